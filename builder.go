@@ -1,20 +1,23 @@
 package qrgode
 
 import (
+	"fmt"
 	"image"
-	"os"
-	"path/filepath"
-	"strings"
+	"io"
 
+	"github.com/ahmedtahas/qr-gode/decode"
 	"github.com/ahmedtahas/qr-gode/internal/colors"
-	"github.com/ahmedtahas/qr-gode/internal/encoder"
+	"github.com/ahmedtahas/qr-gode/payload"
 )
 
 // QRCode represents a QR code generator with fluent configuration.
 type QRCode struct {
-	data   string
-	config *Config
-	errs   []error // Accumulated validation errors
+	data       string
+	config     *Config
+	errs       []error               // Accumulated validation errors
+	payload    payload.Payload       // Source of data, nil for plain text
+	fitVersion int                   // Largest version for the payload, 0 for any
+	fixedLevel *ErrorCorrectionLevel // Level the payload format requires, nil for any
 }
 
 // New creates a new QR code generator for the given data.
@@ -36,6 +39,27 @@ func (q *QRCode) Size(pixels int) *QRCode {
 	return q
 }
 
+// PhysicalSize sets the printed width of PDF and EPS output in millimetres,
+// quiet zone included. By default each pixel is one point.
+func (q *QRCode) PhysicalSize(mm float64) *QRCode {
+	q.config.PhysicalSize = mm
+	return q
+}
+
+// CMYK writes the colors of EPS output as CMYK instead of RGB.
+func (q *QRCode) CMYK() *QRCode {
+	q.config.CMYK = true
+	return q
+}
+
+// SpotColor prints the modules of EPS output in a named spot color ink,
+// such as "PANTONE 286 C". C, M, Y and K (0.0-1.0) give its process
+// equivalent for proofs.
+func (q *QRCode) SpotColor(name string, c, m, y, k float64) *QRCode {
+	q.config.Spot = &SpotColor{Name: name, C: c, M: m, Y: y, K: k}
+	return q
+}
+
 // QuietZone sets the margin around the QR code in modules. Default is 4.
 func (q *QRCode) QuietZone(modules int) *QRCode {
 	q.config.QuietZone = modules
@@ -54,6 +78,74 @@ func (q *QRCode) ErrorCorrection(level ErrorCorrectionLevel) *QRCode {
 	return q
 }
 
+// BoostECL raises the error correction level as far as the data still
+// fits the same version, so the code gets more robust without growing.
+// Encode reports the level used.
+func (q *QRCode) BoostECL() *QRCode {
+	q.config.BoostECL = true
+	return q
+}
+
+// GS1 reads the data as GS1 element strings in human readable form, like
+// (01)09506000134352(17)201225(10)ABC123, validates them and encodes
+// them for GS1 scanners. Generating fails with a *GS1Error for an
+// unknown AI, a wrong length or a bad check digit.
+func (q *QRCode) GS1() *QRCode {
+	q.config.GS1 = true
+	return q
+}
+
+// Symbol sets the symbol type. SymbolMicro generates a Micro QR Code,
+// which is smaller but holds at most 35 digits or 21 characters of text.
+// SymbolRMQR generates a rectangular Micro QR Code for long, narrow
+// spaces. It also sets the symbol's standard quiet zone (4 modules for
+// QR, 2 for Micro QR and rMQR); call QuietZone afterwards to change it.
+func (q *QRCode) Symbol(symbol SymbolType) *QRCode {
+	q.config.Symbol = symbol
+	q.config.QuietZone = symbol.quietZone()
+	return q
+}
+
+// MaxHeight limits rMQR symbols to at most the given number of modules
+// high (7-17). The smallest rectangle that fits is chosen.
+func (q *QRCode) MaxHeight(modules int) *QRCode {
+	q.config.MaxHeight = modules
+	return q
+}
+
+// Version fixes the symbol version, so codes with different data share
+// one layout: 1-40 for QR Code, 1-4 for Micro QR and 1-32 for rMQR.
+// Generating fails if the data does not fit.
+func (q *QRCode) Version(version int) *QRCode {
+	q.config.Version = version
+	return q
+}
+
+// MinVersion sets the smallest version to use. Larger versions are still
+// chosen for data that needs them.
+func (q *QRCode) MinVersion(version int) *QRCode {
+	q.config.MinVersion = version
+	return q
+}
+
+// Mask fixes the mask pattern: 0-7 for QR Code, 0-3 for Micro QR. Pass
+// -1 (the default) to select the best mask.
+func (q *QRCode) Mask(mask int) *QRCode {
+	q.config.Mask = mask
+	return q
+}
+
+// Charset sets the character set for text, announced to scanners with
+// an ECI header so non-ASCII text is not misread. Use CharsetAuto to add
+// the header only when the text needs it.
+//
+// Available charsets: CharsetAuto, CharsetUTF8, CharsetLatin1, CharsetShiftJIS,
+// "us-ascii" and "iso-8859-N"
+func (q *QRCode) Charset(name string) *QRCode {
+	q.config.Charset = name
+	return q
+}
+
 // Shape sets the module shape.
 //
 // Available shapes: ShapeSquare, ShapeCircle, ShapeRounded, ShapeDiamond, ShapeDot, ShapeStar, ShapeHeart
@@ -106,6 +198,112 @@ func (q *QRCode) RadialGradient(centerX, centerY float64, colorStops ...string)
 	return q
 }
 
+// ImageColors samples the module colors from a PNG or JPEG image, so the
+// QR code carries the image's palette. Darken (0.0-1.0) darkens the
+// sampled colors to keep contrast against the background; 0.2-0.4 suits
+// most photos.
+// The image is loaded immediately; errors are collected and returned by SVG()/SaveAs().
+func (q *QRCode) ImageColors(path string, darken float64) *QRCode {
+	c, err := colors.LoadImage(path, darken)
+	if err != nil {
+		q.errs = append(q.errs, &ValidationError{Field: "Modules.Color", Message: err.Error()})
+		return q
+	}
+	q.config.Modules.Color = c
+	return q
+}
+
+// FinderShape draws the finder patterns as concentric shapes of the
+// given kind instead of module by module.
+//
+// Example:
+//
+//	qr.FinderShape(qrgode.ShapeCircle)
+func (q *QRCode) FinderShape(shape Shape) *QRCode {
+	q.config.Finders.Shape = string(shape)
+	return q
+}
+
+// FinderColor sets the color of the finder patterns as a hex string.
+func (q *QRCode) FinderColor(hex string) *QRCode {
+	q.config.Finders.Color = optionalColor(hex)
+	return q
+}
+
+// FinderOuter styles the 7x7 outer ring of the finder patterns.
+// An empty shape or color inherits from FinderShape/FinderColor.
+func (q *QRCode) FinderOuter(shape Shape, hex string) *QRCode {
+	q.config.Finders.Outer = finderLayer(q.config.Finders.Outer, shape, hex)
+	return q
+}
+
+// FinderMiddle styles the 5x5 gap between the outer ring and the eye.
+// The shape sets the outline of the gap; the gap is only filled if a
+// color is given.
+func (q *QRCode) FinderMiddle(shape Shape, hex string) *QRCode {
+	q.config.Finders.Middle = finderLayer(q.config.Finders.Middle, shape, hex)
+	return q
+}
+
+// FinderCenter styles the 3x3 center eye of the finder patterns.
+// An empty shape or color inherits from FinderShape/FinderColor.
+func (q *QRCode) FinderCenter(shape Shape, hex string) *QRCode {
+	q.config.Finders.Center = finderLayer(q.config.Finders.Center, shape, hex)
+	return q
+}
+
+// FinderCornerRadius rounds the corners of all finder layers.
+// The radius is a fraction of each layer's size (0.0-0.5).
+func (q *QRCode) FinderCornerRadius(radius float64) *QRCode {
+	f := &q.config.Finders
+	for _, l := range []**FinderLayerStyle{&f.Outer, &f.Middle, &f.Center} {
+		if *l == nil {
+			*l = &FinderLayerStyle{}
+		}
+		(*l).CornerRadius = radius
+	}
+	return q
+}
+
+// AlignmentShape draws alignment patterns as concentric shapes of the
+// given kind instead of module by module.
+func (q *QRCode) AlignmentShape(shape Shape) *QRCode {
+	q.config.Alignment.Shape = string(shape)
+	return q
+}
+
+// AlignmentColor sets the color of the alignment patterns as a hex string.
+func (q *QRCode) AlignmentColor(hex string) *QRCode {
+	q.config.Alignment.Color = optionalColor(hex)
+	return q
+}
+
+// AlignmentOuter styles the 5x5 outer ring of the alignment patterns.
+// An empty shape or color inherits from AlignmentShape/AlignmentColor.
+func (q *QRCode) AlignmentOuter(shape Shape, hex string) *QRCode {
+	q.config.Alignment.Outer = &AlignmentLayerStyle{Shape: string(shape), Color: optionalColor(hex)}
+	return q
+}
+
+// AlignmentCenter styles the center module of the alignment patterns.
+// An empty shape or color inherits from AlignmentShape/AlignmentColor.
+func (q *QRCode) AlignmentCenter(shape Shape, hex string) *QRCode {
+	q.config.Alignment.Center = &AlignmentLayerStyle{Shape: string(shape), Color: optionalColor(hex)}
+	return q
+}
+
+// TimingShape sets the shape of the timing pattern modules.
+func (q *QRCode) TimingShape(shape Shape) *QRCode {
+	q.config.Timing.Shape = string(shape)
+	return q
+}
+
+// TimingColor sets the color of the timing pattern modules as a hex string.
+func (q *QRCode) TimingColor(hex string) *QRCode {
+	q.config.Timing.Color = optionalColor(hex)
+	return q
+}
+
 // ModuleImage sets a custom PNG/JPG/SVG image for data modules.
 // Each module will be rendered using this image.
 // The image is validated immediately; errors are collected and returned by SVG()/SaveAs().
@@ -202,6 +400,43 @@ func (q *QRCode) LogoBackground(color string) *QRCode {
 	return q
 }
 
+// LogoPolicy sets what happens when the logo hides more codewords than
+// the error correction level recovers: raise the level or version (the
+// default), shrink the logo, fail with a *LogoCoverageError, or ignore it.
+func (q *QRCode) LogoPolicy(policy LogoPolicy) *QRCode {
+	q.ensureLogo()
+	q.config.Logo.Policy = policy
+	return q
+}
+
+// LogoOverlay draws the logo over the modules instead of clearing the
+// modules behind it, for opaque marks of an exact size like the Swiss
+// cross. The modules the logo and its background cover still count
+// against error correction.
+func (q *QRCode) LogoOverlay() *QRCode {
+	q.ensureLogo()
+	q.config.Logo.Overlay = true
+	return q
+}
+
+// finderLayer updates a finder layer, keeping its corner radius.
+func finderLayer(l *FinderLayerStyle, shape Shape, hex string) *FinderLayerStyle {
+	if l == nil {
+		l = &FinderLayerStyle{}
+	}
+	l.Shape = string(shape)
+	l.Color = optionalColor(hex)
+	return l
+}
+
+// optionalColor returns a solid color, or nil to inherit when hex is empty.
+func optionalColor(hex string) colors.Color {
+	if hex == "" {
+		return nil
+	}
+	return colors.NewSolid(hex)
+}
+
 func (q *QRCode) ensureImages() {
 	if q.config.Images == nil {
 		q.config.Images = &CustomImages{}
@@ -229,24 +464,10 @@ func (q *QRCode) Validate() []error {
 // SVG generates and returns the QR code as SVG bytes.
 // Returns an error if validation fails or encoding fails.
 func (q *QRCode) SVG() ([]byte, error) {
-	// Validate data
-	if q.data == "" {
-		return nil, &ValidationError{Field: "Data", Message: "cannot be empty"}
-	}
-
-	// Check for validation errors
-	if errs := q.Validate(); len(errs) > 0 {
-		return nil, errs[0] // Return first error
-	}
-
-	ecl := encoder.ErrorCorrectionLevel(q.config.ErrorCorrection)
-	enc := encoder.New(q.data, ecl)
-	matrix, err := enc.Encode()
+	renderer, err := q.renderer()
 	if err != nil {
 		return nil, err
 	}
-
-	renderer := newRenderer(matrix, q.config)
 	return renderer.renderSVG()
 }
 
@@ -259,19 +480,220 @@ func (q *QRCode) SVGString() (string, error) {
 	return string(svg), nil
 }
 
+// PNG generates and returns the QR code as PNG bytes.
+// The image is Size pixels wide and, except for rMQR, as high, with
+// anti-aliased shapes.
+func (q *QRCode) PNG() ([]byte, error) {
+	renderer, err := q.renderer()
+	if err != nil {
+		return nil, err
+	}
+	return renderer.renderPNG()
+}
+
+// PDF generates and returns the QR code as a single page PDF document.
+// The page is PhysicalSize millimetres wide, or Size points by default.
+func (q *QRCode) PDF() ([]byte, error) {
+	renderer, err := q.renderer()
+	if err != nil {
+		return nil, err
+	}
+	return renderer.renderPDF()
+}
+
+// EPS generates and returns the QR code as an Encapsulated PostScript
+// file, in RGB, CMYK or a spot color.
+func (q *QRCode) EPS() ([]byte, error) {
+	renderer, err := q.renderer()
+	if err != nil {
+		return nil, err
+	}
+	return renderer.renderEPS()
+}
+
+// Terminal returns the QR code as Unicode half blocks in 24-bit ANSI
+// colors, two module rows per line, for printing to a terminal. Colors
+// are sampled at each module; shapes and the logo are left out.
+func (q *QRCode) Terminal() (string, error) {
+	return q.text(FormatTerminal)
+}
+
+// UTF8 returns the QR code as Unicode half blocks without colors, two
+// module rows per line, with dark modules drawn as ink.
+func (q *QRCode) UTF8() (string, error) {
+	return q.text(FormatUTF8)
+}
+
+// ASCII returns the QR code as plain text, "##" for each dark module
+// and two spaces for each light one.
+func (q *QRCode) ASCII() (string, error) {
+	return q.text(FormatASCII)
+}
+
+func (q *QRCode) text(format Format) (string, error) {
+	renderer, err := q.renderer()
+	if err != nil {
+		return "", err
+	}
+	return renderer.renderText(format)
+}
+
+// WriteSVG streams the QR code to w as SVG, without holding the whole
+// document in memory. Errors from w are returned.
+func (q *QRCode) WriteSVG(w io.Writer) error {
+	return q.Write(w, FormatSVG)
+}
+
+// WritePNG streams the QR code to w as PNG. Errors from w are returned.
+func (q *QRCode) WritePNG(w io.Writer) error {
+	return q.Write(w, FormatPNG)
+}
+
+// WritePDF streams the QR code to w as PDF. Errors from w are returned.
+func (q *QRCode) WritePDF(w io.Writer) error {
+	return q.Write(w, FormatPDF)
+}
+
+// WriteEPS streams the QR code to w as EPS. Errors from w are returned.
+func (q *QRCode) WriteEPS(w io.Writer) error {
+	return q.Write(w, FormatEPS)
+}
+
+// Write streams the QR code to w in the given format, such as an
+// http.ResponseWriter or a gzip.Writer. Errors from w are returned.
+func (q *QRCode) Write(w io.Writer, format Format) error {
+	renderer, err := q.renderer()
+	if err != nil {
+		return err
+	}
+	return renderer.write(w, format)
+}
+
+// Image generates the QR code as an in-memory raster image.
+func (q *QRCode) Image() (image.Image, error) {
+	renderer, err := q.renderer()
+	if err != nil {
+		return nil, err
+	}
+	return renderer.renderImage()
+}
+
+// Encode encodes the data without rendering it and reports the chosen
+// version and mask and the error correction level used.
+func (q *QRCode) Encode() (*Symbol, error) {
+	renderer, err := q.renderer()
+	if err != nil {
+		return nil, err
+	}
+	return renderer.symbol, nil
+}
+
+// Verify rasterizes the QR code at its configured size and decodes it
+// back, returning a *VerifyError if it does not read as the data. Use it
+// to check that shapes, colors and logos still leave a scannable code.
+// Only QR Code symbols can be verified.
+func (q *QRCode) Verify() error {
+	if q.config.Symbol != SymbolQR {
+		return &ValidationError{Field: "Symbol", Message: "only QR Code symbols can be verified"}
+	}
+	want, err := q.fittedData()
+	if err != nil {
+		return err
+	}
+	if q.config.GS1 {
+		// Scanners return the element string
+		if want, err = gs1Data(want); err != nil {
+			return err
+		}
+	}
+
+	img, err := q.Image()
+	if err != nil {
+		return err
+	}
+	text, err := decode.Decode(img)
+	if err != nil {
+		return &VerifyError{Err: err}
+	}
+	if text != want {
+		return &VerifyError{Decoded: text}
+	}
+	return nil
+}
+
 // SaveAs generates the QR code and saves it to the specified file.
-// Currently supports .svg files. PNG support is planned.
+// The format is chosen by extension: .png writes PNG, .pdf writes PDF,
+// .eps writes EPS, .txt writes UTF-8 half blocks, anything else SVG.
+// Nothing is written if generating fails.
 func (q *QRCode) SaveAs(path string) error {
-	ext := strings.ToLower(filepath.Ext(path))
-	if ext == ".png" {
-		return &UnsupportedFormatError{Format: "png"}
+	renderer, err := q.renderer()
+	if err != nil {
+		return err
 	}
+	return renderer.writeFile(path, formatForPath(path))
+}
 
-	svg, err := q.SVG()
+// StructuredAppend generates data too long for one QR code as up to 16
+// linked SVG symbols with the same style. See GenerateStructuredAppend.
+func (q *QRCode) StructuredAppend() ([][]byte, error) {
+	renderers, err := q.sequenceRenderers()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return renderSequence(renderers)
+}
+
+// StructuredAppendGrid generates the symbols of StructuredAppend laid
+// out in one SVG, columns per row (0 = as square as possible).
+func (q *QRCode) StructuredAppendGrid(columns int) ([]byte, error) {
+	renderers, err := q.sequenceRenderers()
+	if err != nil {
+		return nil, err
+	}
+	return renderGrid(renderers, columns)
+}
+
+// sequenceRenderers validates the builder state and returns a renderer
+// per Structured Append symbol.
+func (q *QRCode) sequenceRenderers() ([]*renderer, error) {
+	if errs := q.Validate(); len(errs) > 0 {
+		return nil, errs[0]
+	}
+	data, err := q.fittedData()
+	if err != nil {
+		return nil, err
+	}
+	if data == "" {
+		return nil, &ValidationError{Field: "Data", Message: "cannot be empty"}
+	}
+	return sequenceRenderers(data, q.config)
+}
+
+// renderer validates the builder state, encodes the data and returns a
+// renderer ready to produce output.
+func (q *QRCode) renderer() (*renderer, error) {
+	// Check for validation errors, including those of a payload
+	if errs := q.Validate(); len(errs) > 0 {
+		return nil, errs[0] // Return first error
+	}
+
+	// Validate data
+	data, err := q.fittedData()
+	if err != nil {
+		return nil, err
+	}
+	if data == "" {
+		return nil, &ValidationError{Field: "Data", Message: "cannot be empty"}
 	}
-	return os.WriteFile(path, svg, 0644)
+
+	renderer, err := encodeRenderer(data, q.config, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.checkPayloadSymbol(renderer.symbol); err != nil {
+		return nil, err
+	}
+	return renderer, nil
 }
 
 // GetConfig returns the underlying configuration for advanced customization.
@@ -279,11 +701,30 @@ func (q *QRCode) GetConfig() *Config {
 	return q.config
 }
 
-// UnsupportedFormatError is returned when an unsupported output format is requested.
+// VerifyError is returned by Verify when the rendered QR code does not
+// read back as its data.
+type VerifyError struct {
+	Decoded string // Text read back, if decoding succeeded
+	Err     error  // Why decoding failed
+}
+
+func (e *VerifyError) Error() string {
+	if e.Err != nil {
+		return "QR code does not scan: " + e.Err.Error()
+	}
+	return fmt.Sprintf("QR code reads back as %q", e.Decoded)
+}
+
+func (e *VerifyError) Unwrap() error {
+	return e.Err
+}
+
+// UnsupportedFormatError is returned when output is requested in a
+// Format value that is not one of the Format constants.
 type UnsupportedFormatError struct {
 	Format string
 }
 
 func (e *UnsupportedFormatError) Error() string {
-	return "unsupported format: " + e.Format + " (only SVG is currently supported)"
+	return "unsupported format: " + e.Format
 }
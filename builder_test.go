@@ -1,9 +1,18 @@
 package qrgode
 
 import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/ahmedtahas/qr-gode/payload"
 )
 
 func TestNew(t *testing.T) {
@@ -134,14 +143,28 @@ func TestSaveAs(t *testing.T) {
 	}
 }
 
+func TestUnsupportedFormat(t *testing.T) {
+	err := GenerateTo(io.Discard, "test", nil, Format(99))
+	var ferr *UnsupportedFormatError
+	if !errors.As(err, &ferr) || ferr.Format != "Format(99)" {
+		t.Errorf("expected *UnsupportedFormatError, got %v", err)
+	}
+}
+
 func TestSaveAsPNG(t *testing.T) {
-	err := New("test").SaveAs("test.png")
+	tmpFile := filepath.Join(t.TempDir(), "test_qr.png")
+
+	err := New("test").SaveAs(tmpFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
-	if err == nil {
-		t.Error("expected error for PNG format")
+	data, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatal(err)
 	}
-	if _, ok := err.(*UnsupportedFormatError); !ok {
-		t.Errorf("expected UnsupportedFormatError, got %T", err)
+	if !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Error("expected file to contain PNG data")
 	}
 }
 
@@ -185,3 +208,247 @@ func TestAllErrorCorrectionLevels(t *testing.T) {
 		})
 	}
 }
+
+func TestCharset(t *testing.T) {
+	for _, charset := range []string{CharsetAuto, CharsetUTF8, CharsetLatin1, "iso-8859-15"} {
+		t.Run(charset, func(t *testing.T) {
+			svg, err := New("café").Charset(charset).SVG()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(svg) == 0 {
+				t.Error("expected non-empty SVG")
+			}
+		})
+	}
+
+	// Auto mode leaves ASCII text without an ECI header
+	plain, _ := New("hello").SVGString()
+	auto, _ := New("hello").Charset(CharsetAuto).SVGString()
+	if plain != auto {
+		t.Error("expected auto charset to leave ASCII data unchanged")
+	}
+}
+
+func TestSymbolMicro(t *testing.T) {
+	qr := New("01234567").ErrorCorrection(LevelL).Symbol(SymbolMicro)
+	if qr.config.QuietZone != 2 {
+		t.Errorf("expected 2-module quiet zone, got %d", qr.config.QuietZone)
+	}
+
+	svg, err := qr.Size(170).SVGString()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// M2 is 13 modules plus 2 on each side: 10px modules
+	if !strings.Contains(svg, `M20.00 20.00 L30.00 20.00`) {
+		t.Errorf("expected 10px modules from the quiet zone, got %.300s", svg)
+	}
+
+	// Styled finders work on the single Micro QR finder
+	svg, err = New("HELLO").Symbol(SymbolMicro).FinderShape(ShapeCircle).FinderCenter("", "#ff0000").SVGString()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := strings.Count(svg, `fill="#ff0000"`); n != 1 {
+		t.Errorf("expected one finder center, got %d", n)
+	}
+
+	if _, err := New("https://example.com/a/long/path").Symbol(SymbolMicro).SVG(); err == nil {
+		t.Error("expected error for data beyond M4")
+	}
+	if _, err := New("1").Symbol(SymbolMicro).PNG(); err != nil {
+		t.Errorf("unexpected PNG error: %v", err)
+	}
+}
+
+func TestSymbolRMQR(t *testing.T) {
+	qr := New("012345678901").Symbol(SymbolRMQR).MaxHeight(7).Size(470)
+
+	// R7x43 is 43x7 modules plus 2 on each side: 47x11 at 10px
+	svg, err := qr.SVGString()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(svg, `viewBox="0 0 470 110"`) {
+		t.Errorf("expected a 470x110 viewBox, got %.200s", svg)
+	}
+
+	img, err := qr.Image()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 470 || b.Dy() != 110 {
+		t.Errorf("expected a 470x110 image, got %v", b)
+	}
+
+	// Styled finders leave the rMQR corner patterns module by module
+	svg, err = New("012345678901").Symbol(SymbolRMQR).FinderShape(ShapeCircle).FinderCenter("", "#ff0000").SVGString()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := strings.Count(svg, `fill="#ff0000"`); n != 1 {
+		t.Errorf("expected one finder center, got %d", n)
+	}
+
+	if _, err := New("https://example.com/a/rather/long/path/that/needs/more/room").Symbol(SymbolRMQR).MaxHeight(7).SVG(); err == nil {
+		t.Error("expected error for data beyond R7x139")
+	}
+}
+
+func TestVersionAndMask(t *testing.T) {
+	symbol, err := New("HELLO").Version(5).Mask(3).Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if symbol.Version != 5 || symbol.Mask != 3 || symbol.ErrorCorrection != LevelM {
+		t.Errorf("expected version 5, mask 3 at level M, got %+v", symbol)
+	}
+	if err := New("HELLO").Version(5).Mask(3).Verify(); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	symbol, err = New("HELLO").MinVersion(10).Encode()
+	if err != nil || symbol.Version != 10 {
+		t.Errorf("expected minimum version 10, got %+v, %v", symbol, err)
+	}
+	symbol, err = New(strings.Repeat("A", 100)).MinVersion(2).Encode()
+	if err != nil || symbol.Version != 5 {
+		t.Errorf("expected data to need version 5 above the minimum, got %+v, %v", symbol, err)
+	}
+
+	if _, err := New(strings.Repeat("A", 100)).Version(2).SVG(); err == nil {
+		t.Error("expected error for data beyond the fixed version")
+	}
+
+	symbol, err = New("123").Symbol(SymbolMicro).Version(3).Mask(2).Encode()
+	if err != nil || symbol.Version != 3 || symbol.Mask != 2 {
+		t.Errorf("expected M3 with mask 2, got %+v, %v", symbol, err)
+	}
+}
+
+func TestBoostECL(t *testing.T) {
+	symbol, err := New("HELLO WORLD").BoostECL().Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if symbol.Version != 1 || symbol.ErrorCorrection != LevelQ {
+		t.Errorf("expected version 1 boosted to level Q, got %+v", symbol)
+	}
+	if err := New("HELLO WORLD").BoostECL().Verify(); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	symbol, err = New("HELLO WORLD").Encode()
+	if err != nil || symbol.ErrorCorrection != LevelM {
+		t.Errorf("expected level M without boost, got %+v, %v", symbol, err)
+	}
+}
+
+// failingWriter accepts limit bytes and then fails.
+type failingWriter struct {
+	limit int
+}
+
+var errWriteFailed = errors.New("write failed")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n := w.limit
+		w.limit = 0
+		return n, errWriteFailed
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestWrite(t *testing.T) {
+	qr := New("https://example.com").Shape("circle").LinearGradient(45, "#ff0000", "#0000ff")
+	for _, tt := range []struct {
+		format Format
+		bytes  func() ([]byte, error)
+		write  func(io.Writer) error
+	}{
+		{FormatSVG, qr.SVG, qr.WriteSVG},
+		{FormatPNG, qr.PNG, qr.WritePNG},
+	} {
+		want, err := tt.bytes()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := tt.write(&buf); err != nil {
+			t.Fatalf("format %d: unexpected error: %v", tt.format, err)
+		}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("format %d: streamed output differs from the returned bytes", tt.format)
+		}
+
+		for _, limit := range []int{0, 100, len(want) - 1} {
+			if err := qr.Write(&failingWriter{limit: limit}, tt.format); !errors.Is(err, errWriteFailed) {
+				t.Errorf("format %d: expected write error after %d bytes, got %v", tt.format, limit, err)
+			}
+		}
+	}
+
+	if err := qr.Write(io.Discard, Format(99)); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestGenerateTo(t *testing.T) {
+	var buf bytes.Buffer
+	if err := GenerateTo(&buf, "hello", nil, FormatSVG); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want, _ := Generate("hello", nil)
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Error("GenerateTo output differs from Generate")
+	}
+
+	// Invalid data leaves the writer untouched
+	buf.Reset()
+	if err := GenerateTo(&buf, "", nil, FormatPNG); err == nil || buf.Len() != 0 {
+		t.Errorf("expected error and no output, got %v and %d bytes", err, buf.Len())
+	}
+}
+
+func TestVerify(t *testing.T) {
+	logo := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	draw.Draw(logo, logo.Bounds(), image.NewUniform(color.RGBA{200, 30, 30, 255}), image.Point{}, draw.Src)
+
+	tests := []struct {
+		name string
+		qr   *QRCode
+	}{
+		{"default", New("https://example.com")},
+		{"hearts", New("https://example.com").Shape(ShapeHeart).Size(400)},
+		{"stars", New("https://example.com").Shape(ShapeStar).Size(400)},
+		{"dots and round finders", New("https://example.com").Shape(ShapeDot).FinderShape(ShapeCircle).AlignmentShape(ShapeCircle).Size(400)},
+		{"gradient", New("https://example.com").LinearGradient(45, "#ff6b6b", "#4ecdc4").Background("transparent")},
+		{"logo", New("https://example.com").ErrorCorrection(LevelH).LogoImage(logo).Size(400)},
+		{"large", New(strings.Repeat("Long data needs a large version. ", 10)).Size(800)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.qr.Verify(); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+
+	if err := New("1").Symbol(SymbolMicro).Verify(); err == nil {
+		t.Error("expected error verifying Micro QR")
+	}
+
+	// Invalid data is reported as such, not as a failed read back
+	var gs1Err *GS1Error
+	if err := New("(01)09506000134353").GS1().Verify(); !errors.As(err, &gs1Err) {
+		t.Errorf("expected *GS1Error, got %v", err)
+	}
+	var verr *ValidationError
+	err := NewPayload(payload.WiFi{SSID: strings.Repeat("x", 32), Password: strings.Repeat("p", 63)}).FitVersion(1).Verify()
+	if !errors.As(err, &verr) || verr.Field != "FitVersion" {
+		t.Errorf("expected FitVersion error, got %v", err)
+	}
+}
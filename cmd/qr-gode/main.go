@@ -12,8 +12,14 @@ import (
 
 func main() {
 	// Flags
-	output := flag.String("o", "qrcode.svg", "Output file path")
+	configPath := flag.String("config", "", "TOML style file (see examples/configs); other flags override it")
+	output := flag.String("o", "qrcode.svg", "Output file path (.svg, .png, .pdf, .eps or .txt), - for standard output")
+	format := flag.String("format", "", "Output format: svg, png, pdf, eps, term, utf8 or ascii (default: from the -o extension, term for -o -)")
 	size := flag.Int("size", 512, "Output size in pixels")
+	physicalSize := flag.Float64("physical-size", 0, "Printed width of PDF and EPS output in millimetres (0 = one point per pixel)")
+	cmyk := flag.Bool("cmyk", false, "Write EPS colors as CMYK")
+	spot := flag.String("spot", "", "Spot color ink for the modules of EPS output, e.g. 'PANTONE 286 C'")
+	spotCMYK := flag.String("spot-cmyk", "0,0,0,1", "CMYK equivalent of the spot color (comma-separated, 0-1)")
 	shape := flag.String("shape", "square", "Module shape: square, circle, rounded, diamond, dot, star, heart")
 	fgColor := flag.String("fg", "#000000", "Foreground color (hex)")
 	bgColor := flag.String("bg", "#FFFFFF", "Background color (hex)")
@@ -21,6 +27,14 @@ func main() {
 	gradientAngle := flag.Float64("gradient-angle", 45, "Gradient angle in degrees")
 	radial := flag.Bool("radial", false, "Use radial gradient instead of linear")
 	ecl := flag.String("ecl", "M", "Error correction level: L, M, Q, H")
+	gs1 := flag.Bool("gs1", false, "Encode GS1 element strings given as (01)09506000134352(10)ABC123")
+	boostECL := flag.Bool("boost-ecl", false, "Raise the error correction level while the data fits the same version")
+	charset := flag.String("charset", "", "Character set announced with an ECI header: auto, utf-8, iso-8859-1, shift_jis, ...")
+	symbol := flag.String("symbol", "qr", "Symbol type: qr, micro for Micro QR (short data only) or rmqr for rectangular Micro QR")
+	maxHeight := flag.Int("max-height", 0, "Maximum rMQR height in modules, 7-17 (0 = any)")
+	version := flag.Int("version", 0, "Fixed symbol version: 1-40, 1-4 for Micro QR, 1-32 for rMQR (0 = smallest that fits)")
+	minVersion := flag.Int("min-version", 0, "Smallest symbol version to use (0 = any)")
+	mask := flag.Int("mask", -1, "Fixed mask pattern: 0-7, 0-3 for Micro QR (-1 = best)")
 
 	// Custom image flags
 	moduleImg := flag.String("module-img", "", "Custom PNG/JPG for data modules")
@@ -31,45 +45,164 @@ func main() {
 	logoImg := flag.String("logo", "", "Logo image to place in center (PNG/JPG/SVG)")
 	logoWidth := flag.Int("logo-width", 0, "Optional: logo width in pixels (0 = auto)")
 	logoHeight := flag.Int("logo-height", 0, "Optional: logo height in pixels (0 = auto)")
+	logoPolicy := flag.String("logo-policy", "raise", "When the logo hides too much: raise the level or version, shrink the logo, fail or ignore")
+
+	// Subcommands add flags that build the data
+	args := os.Args[1:]
+	var payloadData func() (string, error)
+	if len(args) > 0 && args[0] == "wifi" {
+		payloadData = wifiFlags(flag.CommandLine)
+		args = args[1:]
+	}
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: qr-gode [options] <data>\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: qr-gode [options] <data>\n")
+		fmt.Fprintf(os.Stderr, "       qr-gode -config style.toml [options] [data]\n")
+		fmt.Fprintf(os.Stderr, "       qr-gode wifi -ssid <name> [-password <password>] [options]\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  qr-gode 'https://google.com'\n")
+		fmt.Fprintf(os.Stderr, "  qr-gode -format term 'otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP'\n")
+		fmt.Fprintf(os.Stderr, "  qr-gode wifi -ssid Office -password 'correct horse' -o wifi.png\n")
 		fmt.Fprintf(os.Stderr, "  qr-gode -shape circle -fg '#3498db' 'Hello World'\n")
 		fmt.Fprintf(os.Stderr, "  qr-gode -gradient '#ff6b6b,#4ecdc4' -shape rounded 'Gradient QR'\n")
 		fmt.Fprintf(os.Stderr, "  qr-gode -module-img dot.png -finder-img finder.png 'Custom Images'\n")
+		fmt.Fprintf(os.Stderr, "  qr-gode -charset auto 'Grüße aus Köln'\n")
+		fmt.Fprintf(os.Stderr, "  qr-gode -symbol micro -ecl L 01234567\n")
+		fmt.Fprintf(os.Stderr, "  qr-gode -symbol rmqr -max-height 9 'https://example.com'\n")
+		fmt.Fprintf(os.Stderr, "  qr-gode -gs1 '(01)09506000134352(17)201225(10)ABC123'\n")
+		fmt.Fprintf(os.Stderr, "  qr-gode -o label.pdf -physical-size 30 'https://example.com'\n")
+		fmt.Fprintf(os.Stderr, "  qr-gode -o plate.eps -spot 'PANTONE 286 C' -spot-cmyk 1,0.66,0,0.02 'https://example.com'\n")
 		fmt.Fprintf(os.Stderr, "  qr-gode -logo logo.png 'QR with Logo'\n")
 		fmt.Fprintf(os.Stderr, "  qr-gode -logo logo.png -logo-width 100 'QR with custom logo size'\n")
+		fmt.Fprintf(os.Stderr, "  qr-gode -config examples/configs/gradient.toml -o qr.png\n")
 	}
 
-	flag.Parse()
+	flag.CommandLine.Parse(args)
+
+	// Build config, starting from the style file if given
+	cfg := qrgode.DefaultConfig()
+	var data string
+	if *configPath != "" {
+		var err error
+		cfg, data, err = qrgode.LoadConfig(*configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	if flag.NArg() < 1 {
+	if payloadData != nil {
+		if flag.NArg() > 0 {
+			fmt.Fprintf(os.Stderr, "Error: unexpected argument %q, the data comes from the flags\n", flag.Arg(0))
+			os.Exit(1)
+		}
+		var err error
+		data, err = payloadData()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else if flag.NArg() >= 1 {
+		data = flag.Arg(0)
+	}
+	if data == "" {
 		flag.Usage()
 		os.Exit(1)
 	}
 
-	data := flag.Arg(0)
+	// With a style file, only flags given on the command line override it
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	apply := func(names ...string) bool {
+		if *configPath == "" {
+			return true
+		}
+		for _, name := range names {
+			if set[name] {
+				return true
+			}
+		}
+		return false
+	}
 
-	// Build config
-	cfg := qrgode.DefaultConfig()
-	cfg.Size = *size
-	cfg.Modules.Shape = *shape
-	cfg.Background = colors.NewSolid(*bgColor)
+	if apply("size") {
+		cfg.Size = *size
+	}
+	if apply("physical-size") {
+		cfg.PhysicalSize = *physicalSize
+	}
+	if apply("cmyk") {
+		cfg.CMYK = *cmyk
+	}
+	if *spot != "" {
+		var c, m, y, k float64
+		if _, err := fmt.Sscanf(strings.ReplaceAll(*spotCMYK, " ", ""), "%g,%g,%g,%g", &c, &m, &y, &k); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid spot CMYK %q (expected four numbers like 1,0.66,0,0.02)\n", *spotCMYK)
+			os.Exit(1)
+		}
+		cfg.Spot = &qrgode.SpotColor{Name: *spot, C: c, M: m, Y: y, K: k}
+	}
+	if apply("shape") {
+		cfg.Modules.Shape = *shape
+	}
+	if apply("bg") {
+		cfg.Background = colors.NewSolid(*bgColor)
+	}
 
 	// Set error correction level
-	switch strings.ToUpper(*ecl) {
-	case "L":
-		cfg.ErrorCorrection = qrgode.LevelL
-	case "M":
-		cfg.ErrorCorrection = qrgode.LevelM
-	case "Q":
-		cfg.ErrorCorrection = qrgode.LevelQ
-	case "H":
-		cfg.ErrorCorrection = qrgode.LevelH
+	if apply("ecl") {
+		switch strings.ToUpper(*ecl) {
+		case "L":
+			cfg.ErrorCorrection = qrgode.LevelL
+		case "M":
+			cfg.ErrorCorrection = qrgode.LevelM
+		case "Q":
+			cfg.ErrorCorrection = qrgode.LevelQ
+		case "H":
+			cfg.ErrorCorrection = qrgode.LevelH
+		}
+	}
+
+	if apply("gs1") {
+		cfg.GS1 = *gs1
+	}
+	if apply("boost-ecl") {
+		cfg.BoostECL = *boostECL
+	}
+
+	if apply("charset") {
+		cfg.Charset = *charset
+	}
+
+	if apply("symbol") {
+		switch strings.ToLower(*symbol) {
+		case "qr":
+			cfg.Symbol = qrgode.SymbolQR
+		case "micro":
+			cfg.Symbol = qrgode.SymbolMicro
+			cfg.QuietZone = 2
+		case "rmqr":
+			cfg.Symbol = qrgode.SymbolRMQR
+			cfg.QuietZone = 2
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown symbol %q (expected qr, micro or rmqr)\n", *symbol)
+			os.Exit(1)
+		}
+	}
+
+	if apply("max-height") {
+		cfg.MaxHeight = *maxHeight
+	}
+	if apply("version") {
+		cfg.Version = *version
+	}
+	if apply("min-version") {
+		cfg.MinVersion = *minVersion
+	}
+	if apply("mask") {
+		cfg.Mask = *mask
 	}
 
 	// Set color (gradient or solid)
@@ -83,7 +216,7 @@ func main() {
 		} else {
 			cfg.Modules.Color = colors.NewLinearGradient(*gradientAngle, stops)
 		}
-	} else {
+	} else if apply("fg") {
 		cfg.Modules.Color = colors.NewSolid(*fgColor)
 	}
 
@@ -104,13 +237,59 @@ func main() {
 			Height: *logoHeight,
 		}
 	}
+	if cfg.Logo != nil && apply("logo-policy") {
+		switch strings.ToLower(*logoPolicy) {
+		case "raise":
+			cfg.Logo.Policy = qrgode.LogoRaise
+		case "shrink":
+			cfg.Logo.Policy = qrgode.LogoShrink
+		case "fail":
+			cfg.Logo.Policy = qrgode.LogoFail
+		case "ignore":
+			cfg.Logo.Policy = qrgode.LogoIgnore
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown logo policy %q (expected raise, shrink, fail or ignore)\n", *logoPolicy)
+			os.Exit(1)
+		}
+	}
+
+	// Text formats go to the terminal unless a file is named
+	formats := map[string]qrgode.Format{
+		"svg":   qrgode.FormatSVG,
+		"png":   qrgode.FormatPNG,
+		"pdf":   qrgode.FormatPDF,
+		"eps":   qrgode.FormatEPS,
+		"term":  qrgode.FormatTerminal,
+		"utf8":  qrgode.FormatUTF8,
+		"ascii": qrgode.FormatASCII,
+	}
+	outFormat, ok := formats[strings.ToLower(*format)]
+	if *format != "" && !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q (expected svg, png, pdf, eps, term, utf8 or ascii)\n", *format)
+		os.Exit(1)
+	}
+	isText := outFormat == qrgode.FormatTerminal || outFormat == qrgode.FormatUTF8 || outFormat == qrgode.FormatASCII
+	toStdout := *output == "-" || (isText && !set["o"])
+	if toStdout && *format == "" {
+		outFormat = qrgode.FormatTerminal
+	}
 
 	// Generate
-	err := qrgode.GenerateToFile(data, cfg, *output)
+	var err error
+	switch {
+	case toStdout:
+		err = qrgode.GenerateTo(os.Stdout, data, cfg, outFormat)
+	case *format != "":
+		err = qrgode.GenerateToFileFormat(data, cfg, *output, outFormat)
+	default:
+		err = qrgode.GenerateToFile(data, cfg, *output)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Generated QR code for %q -> %s\n", data, *output)
+	if !toStdout {
+		fmt.Printf("Generated QR code for %q -> %s\n", data, *output)
+	}
 }
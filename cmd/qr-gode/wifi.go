@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/ahmedtahas/qr-gode/payload"
+)
+
+// wifiFlags registers the flags of the wifi subcommand and returns a
+// function that builds the join code once they are parsed.
+func wifiFlags(fs *flag.FlagSet) func() (string, error) {
+	ssid := fs.String("ssid", "", "Network name")
+	password := fs.String("password", "", "Passphrase or WEP key, empty for open networks")
+	auth := fs.String("auth", "", "Authentication: WEP, WPA, WPA2-EAP or nopass (default: WPA with a password, nopass without)")
+	hidden := fs.Bool("hidden", false, "The network does not broadcast its SSID")
+	eap := fs.String("eap", "", "EAP method for WPA2-EAP: PEAP, TLS, TTLS, PWD, SIM, AKA or AKA'")
+	phase2 := fs.String("phase2", "", "Inner method for PEAP and TTLS: PAP, MSCHAP, MSCHAPV2 or GTC")
+	identity := fs.String("identity", "", "WPA2-EAP user name")
+	anonymousIdentity := fs.String("anonymous-identity", "", "WPA2-EAP outer identity")
+
+	return func() (string, error) {
+		a := payload.Auth(strings.ToUpper(*auth))
+		if a == "NOPASS" {
+			a = payload.AuthNone
+		}
+		return payload.WiFi{
+			SSID:              *ssid,
+			Password:          *password,
+			Auth:              a,
+			Hidden:            *hidden,
+			EAPMethod:         strings.ToUpper(*eap),
+			Phase2:            strings.ToUpper(*phase2),
+			Identity:          *identity,
+			AnonymousIdentity: *anonymousIdentity,
+		}.Encode()
+	}
+}
@@ -14,6 +14,7 @@ func main() {
 		{"numeric (20 digits)", "12345678901234567890"},
 		{"alphanumeric", "HELLO WORLD 123"},
 		{"google.com", "https://google.com"},
+		{"product url", "https://example.com/ITEM/000123456789"},
 		{"long text", "The quick brown fox jumps over the lazy dog. This is a longer message to test byte mode encoding with more data!"},
 		{"lorem ipsum", "Lorem ipsum dolor sit amet, consectetur adipiscing elit. Sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat. Duis aute irure dolor in reprehenderit in voluptate velit esse cillum dolore eu fugiat nulla pariatur."},
 		{"huge lorem", "Lorem ipsum dolor sit amet, consectetur adipiscing elit. Sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat. Duis aute irure dolor in reprehenderit in voluptate velit esse cillum dolore eu fugiat nulla pariatur. Excepteur sint occaecat cupidatat non proident, sunt in culpa qui officia deserunt mollit anim id est laborum. Curabitur pretium tincidunt lacus. Nulla gravida orci a odio."},
@@ -24,10 +25,14 @@ func main() {
 
 	for _, tt := range tests {
 		mode := encoder.AnalyzeData(tt.data)
-		version, _ := encoder.DetermineVersion(len(tt.data), mode, encoder.LevelM)
+		version, _ := encoder.DetermineVersion(mode.CharCount(tt.data), mode, encoder.LevelM)
 		modeName := []string{"Numeric", "Alphanumeric", "Byte", "Kanji"}[mode]
 		fmt.Printf("%-20s: %3d chars, %-12s -> Version %2d (%dx%d)\n",
 			tt.name, len(tt.data), modeName, version, version.Size(), version.Size())
+
+		segments, version, _ := encoder.DetermineSegments(tt.data, encoder.LevelM, nil)
+		fmt.Printf("%-20s  %2d segment(s)           -> Version %2d (%dx%d)\n",
+			"", len(segments), version, version.Size(), version.Size())
 	}
 
 	// Test version 7+ (needs version info blocks)
@@ -37,7 +42,7 @@ func main() {
 		bigData[i] = 'A' + byte(i%26)
 	}
 	mode := encoder.AnalyzeData(string(bigData))
-	version, _ := encoder.DetermineVersion(len(bigData), mode, encoder.LevelM)
+	version, _ := encoder.DetermineVersion(mode.CharCount(string(bigData)), mode, encoder.LevelM)
 	fmt.Printf("200 chars alphanumeric: Version %d (%dx%d)\n", version, version.Size(), version.Size())
 
 	// Force a version 7+
@@ -46,6 +51,6 @@ func main() {
 		bigData[i] = 'a' + byte(i%26) // lowercase = byte mode
 	}
 	mode = encoder.AnalyzeData(string(bigData))
-	version, _ = encoder.DetermineVersion(len(bigData), mode, encoder.LevelM)
+	version, _ = encoder.DetermineVersion(mode.CharCount(string(bigData)), mode, encoder.LevelM)
 	fmt.Printf("370 chars byte mode:    Version %d (%dx%d)\n", version, version.Size(), version.Size())
 }
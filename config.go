@@ -1,6 +1,7 @@
 package qrgode
 
 import (
+	"fmt"
 	"image"
 
 	"github.com/ahmedtahas/qr-gode/internal/colors"
@@ -17,6 +18,14 @@ const (
 	LevelH                             // ~30% recovery capacity
 )
 
+// String returns the level letter: L, M, Q or H.
+func (l ErrorCorrectionLevel) String() string {
+	if l < LevelL || l > LevelH {
+		return fmt.Sprintf("ErrorCorrectionLevel(%d)", int(l))
+	}
+	return string("LMQH"[l])
+}
+
 // Shape defines the module shape for QR code rendering.
 type Shape string
 
@@ -30,6 +39,43 @@ const (
 	ShapeHeart   Shape = "heart"   // Heart shaped modules
 )
 
+// SymbolType selects the kind of symbol to generate.
+type SymbolType int
+
+const (
+	SymbolQR    SymbolType = iota // QR Code, versions 1-40 (default)
+	SymbolMicro                   // Micro QR Code, versions M1-M4 for up to 35 digits
+	SymbolRMQR                    // Rectangular Micro QR Code, R7x43 to R17x139
+)
+
+// maxVersion returns the largest version of the symbol.
+func (s SymbolType) maxVersion() int {
+	switch s {
+	case SymbolMicro:
+		return 4
+	case SymbolRMQR:
+		return 32
+	}
+	return 40
+}
+
+// quietZone returns the standard quiet zone of the symbol in modules.
+func (s SymbolType) quietZone() int {
+	if s == SymbolMicro || s == SymbolRMQR {
+		return 2
+	}
+	return 4
+}
+
+// Character sets for Config.Charset. Any ISO/IEC 8859 part is also
+// accepted as "iso-8859-N".
+const (
+	CharsetAuto     = "auto"       // UTF-8, announced only for non-ASCII data
+	CharsetUTF8     = "utf-8"      // UTF-8, always announced
+	CharsetLatin1   = "iso-8859-1" // Western European
+	CharsetShiftJIS = "shift_jis"  // Japanese
+)
+
 // Color is an alias to the internal color interface for advanced usage.
 // Most users should use the builder methods like Foreground(), LinearGradient(), etc.
 type Color = colors.Color
@@ -52,15 +98,73 @@ func NewRadialGradientColor(centerX, centerY float64, stops []string) Color {
 	return colors.NewRadialGradient(centerX, centerY, stops)
 }
 
+// NewImageColor creates a color that samples each module from a PNG or
+// JPEG image. Darken (0.0-1.0) darkens the sampled colors to keep
+// contrast against a light background.
+func NewImageColor(path string, darken float64) (Color, error) {
+	return colors.LoadImage(path, darken)
+}
+
 // Config holds all configuration for QR code generation.
 type Config struct {
 	// QR data settings
 	ErrorCorrection ErrorCorrectionLevel
 
+	// BoostECL raises ErrorCorrection to the highest level that still
+	// fits the version the data needs at the configured level, for more
+	// robustness at the same size.
+	BoostECL bool
+
+	// Symbol selects QR Code, Micro QR Code or rMQR. Micro QR has a
+	// single finder pattern, no level H, no Charset and holds little
+	// data. rMQR symbols are rectangles 7 to 17 modules high and only
+	// support levels M and H.
+	Symbol SymbolType
+
+	// MaxHeight limits rMQR symbols to at most this many modules high
+	// (7-17). The smallest rectangle that fits is chosen. 0 allows any.
+	MaxHeight int
+
+	// Version fixes the symbol version: 1-40 for QR Code, 1-4 for Micro
+	// QR (M1-M4) and 1-32 for rMQR (R7x43 to R17x139). Data that does not
+	// fit is an error. 0 picks the smallest version that fits, but not
+	// below MinVersion.
+	Version    int
+	MinVersion int
+
+	// Mask fixes the mask pattern: 0-7 for QR Code, 0-3 for Micro QR.
+	// -1 selects the best mask. rMQR has a single mask and ignores it.
+	Mask int
+
+	// GS1 reads the data as GS1 element strings in human readable form,
+	// like (01)09506000134352(10)ABC123. The AIs are validated and the
+	// element string is encoded after an FNC1 header, which marks it for
+	// GS1 scanners. Not available for Micro QR.
+	GS1 bool
+
+	// Charset converts text outside numeric, alphanumeric and Kanji
+	// segments to this character set and announces it to scanners with
+	// an ECI header. Empty writes UTF-8 bytes without a header, which some
+	// scanners read as ISO-8859-1.
+	Charset string
+
 	// Overall dimensions
-	Size      int // Output size in pixels
+	Size      int // Output size in pixels (the width of rMQR symbols)
 	QuietZone int // Margin around QR (in modules)
 
+	// PhysicalSize is the printed width in millimetres of PDF and EPS
+	// output, quiet zone included. 0 makes each pixel one point (1/72
+	// inch).
+	PhysicalSize float64
+
+	// CMYK writes the colors of EPS output as CMYK instead of RGB.
+	CMYK bool
+
+	// Spot prints the modules of EPS output in a single spot color ink,
+	// replacing their colors. The background and logo keep process
+	// colors.
+	Spot *SpotColor
+
 	// Styling
 	Background colors.Color
 	Modules    ModuleStyle
@@ -76,11 +180,14 @@ type Config struct {
 // ModuleStyle defines how data modules are rendered.
 type ModuleStyle struct {
 	Shape string       // Shape name or SVG path
-	Color colors.Color // Solid, gradient, or image-sampled
+	Color colors.Color // Solid, gradient, or image-sampled (one fill per module)
 	Size  float64      // Size as fraction of cell (0.0-1.0)
 }
 
 // FinderStyle defines how finder patterns are rendered.
+// The zero value draws finders module by module like the data modules.
+// Setting Shape or any layer draws each finder as three concentric shapes:
+// a 7x7 outer ring, the 5x5 gap inside it, and a 3x3 center eye.
 type FinderStyle struct {
 	// Simple mode: style all three layers uniformly
 	Shape string
@@ -93,13 +200,18 @@ type FinderStyle struct {
 }
 
 // FinderLayerStyle defines one layer of a finder pattern.
+// Empty fields inherit from FinderStyle, then from Modules.
+// The middle layer is only painted when it has a Color; its Shape
+// sets the shape of the gap cut out of the outer ring.
 type FinderLayerStyle struct {
 	Shape        string
 	Color        colors.Color
-	CornerRadius float64 // For rounded shapes
+	CornerRadius float64 // Corner radius as fraction of layer size (0.0-0.5)
 }
 
 // AlignmentStyle defines how alignment patterns are rendered.
+// The zero value draws them module by module. Setting Shape or any layer
+// draws each as a 5x5 outer ring and a single-module center.
 type AlignmentStyle struct {
 	// Simple mode
 	Shape string
@@ -111,12 +223,14 @@ type AlignmentStyle struct {
 }
 
 // AlignmentLayerStyle defines one layer of an alignment pattern.
+// Empty fields inherit from AlignmentStyle, then from Modules.
 type AlignmentLayerStyle struct {
 	Shape string
 	Color colors.Color
 }
 
 // TimingStyle defines how timing patterns are rendered.
+// Empty fields inherit from Modules.
 type TimingStyle struct {
 	Shape string
 	Color colors.Color
@@ -128,7 +242,28 @@ type LogoConfig struct {
 	Image      image.Image // In-memory logo image (takes precedence over Path)
 	Width      int         // Optional: logo width in pixels (0 = auto-calculate)
 	Height     int         // Optional: logo height in pixels (0 = auto-calculate)
+	Size       float64     // Optional: largest logo side as fraction of QR size (0 = auto-calculate)
+	Padding    float64     // Optional: padding as fraction of QR size (0 = 10% of logo)
 	Background string      // Background color behind logo (hex or "transparent", default white)
+	Policy     LogoPolicy  // What to do when the logo hides more than error correction recovers
+	Overlay    bool        // Draw the logo over the modules instead of clearing a zone for it
+}
+
+// LogoPolicy selects what happens when a logo hides more codewords of a
+// Reed-Solomon block than the error correction level can recover.
+type LogoPolicy int
+
+const (
+	LogoRaise  LogoPolicy = iota // Raise the error correction level, then the version (default)
+	LogoShrink                   // Shrink the logo in steps of 5% of its size
+	LogoFail                     // Return a *LogoCoverageError
+	LogoIgnore                   // Draw the logo as configured without checking
+)
+
+// SpotColor is a named ink, such as a Pantone color, for print output.
+type SpotColor struct {
+	Name       string  // Ink name, as known to the print shop
+	C, M, Y, K float64 // CMYK equivalent (0.0-1.0) for proofs and screens
 }
 
 // CustomImages defines custom PNG images for different QR elements.
@@ -142,6 +277,7 @@ type CustomImages struct {
 func DefaultConfig() *Config {
 	return &Config{
 		ErrorCorrection: LevelM,
+		Mask:            -1,
 		Size:            256,
 		QuietZone:       4,
 		Background:      NewSolidColor("#FFFFFF"),
@@ -150,17 +286,5 @@ func DefaultConfig() *Config {
 			Color: NewSolidColor("#000000"),
 			Size:  1.0,
 		},
-		Finders: FinderStyle{
-			Shape: "square",
-			Color: NewSolidColor("#000000"),
-		},
-		Alignment: AlignmentStyle{
-			Shape: "square",
-			Color: NewSolidColor("#000000"),
-		},
-		Timing: TimingStyle{
-			Shape: "square",
-			Color: NewSolidColor("#000000"),
-		},
 	}
 }
@@ -0,0 +1,131 @@
+package decode
+
+import "image"
+
+// bitImage is a binarized image; true marks dark pixels.
+type bitImage struct {
+	width, height int
+	dark          []bool
+}
+
+// at reports whether the pixel at (x, y) is dark. Pixels outside the
+// image are light, like a quiet zone.
+func (b *bitImage) at(x, y int) bool {
+	if x < 0 || y < 0 || x >= b.width || y >= b.height {
+		return false
+	}
+	return b.dark[y*b.width+x]
+}
+
+// close fills light gaps narrower than about 2*radius+1 pixels between
+// dark areas: a dilation followed by an erosion with a square of that
+// size.
+func (b *bitImage) close(radius int) *bitImage {
+	return b.morph(radius, true).morph(radius, false)
+}
+
+// morph dilates (dark spreads) or erodes (light spreads) the image with
+// a square of side 2*radius+1, one direction at a time.
+func (b *bitImage) morph(radius int, dilate bool) *bitImage {
+	out := b
+	for _, horizontal := range []bool{true, false} {
+		src := out
+		out = &bitImage{width: b.width, height: b.height, dark: make([]bool, len(b.dark))}
+		lines, length := b.height, b.width
+		if !horizontal {
+			lines, length = b.width, b.height
+		}
+		index := func(line, i int) int {
+			if horizontal {
+				return line*b.width + i
+			}
+			return i*b.width + line
+		}
+
+		// Count the pixels of the spreading color in a sliding window
+		for line := range lines {
+			count := 0
+			spreads := func(i int) bool {
+				if i < 0 || i >= length {
+					return !dilate // Outside is light
+				}
+				return src.dark[index(line, i)] == dilate
+			}
+			for i := -radius; i < radius; i++ {
+				if spreads(i) {
+					count++
+				}
+			}
+			for i := range length {
+				if spreads(i + radius) {
+					count++
+				}
+				out.dark[index(line, i)] = (count > 0) == dilate
+				if spreads(i - radius) {
+					count--
+				}
+			}
+		}
+	}
+	return out
+}
+
+// binarize converts img to dark and light pixels with a global
+// threshold chosen by Otsu's method, so gradients and colored modules
+// are split from their background. Transparent pixels are treated as
+// white.
+func binarize(img image.Image) *bitImage {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	luma := make([]uint8, w*h)
+	var hist [256]int
+	for y := range h {
+		for x := range w {
+			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			// Colors are premultiplied; add the white showing through
+			l := (299*r+587*g+114*b)/1000 + 0xffff - a
+			v := uint8(min(l, 0xffff) >> 8)
+			luma[y*w+x] = v
+			hist[v]++
+		}
+	}
+
+	threshold := otsuThreshold(&hist, w*h)
+	dark := make([]bool, w*h)
+	for i, v := range luma {
+		dark[i] = v <= threshold
+	}
+	return &bitImage{width: w, height: h, dark: dark}
+}
+
+// otsuThreshold returns the luminance that best separates the histogram
+// into two classes, maximizing the variance between them.
+func otsuThreshold(hist *[256]int, total int) uint8 {
+	var sum float64
+	for i, n := range hist {
+		sum += float64(i * n)
+	}
+
+	var sumDark, countDark, best float64
+	threshold := uint8(127)
+	for i, n := range hist {
+		countDark += float64(n)
+		if countDark == 0 {
+			continue
+		}
+		countLight := float64(total) - countDark
+		if countLight == 0 {
+			break
+		}
+		sumDark += float64(i * n)
+		meanDark := sumDark / countDark
+		meanLight := (sum - sumDark) / countLight
+		between := countDark * countLight * (meanDark - meanLight) * (meanDark - meanLight)
+		if between > best {
+			best = between
+			threshold = uint8(i)
+		}
+	}
+	return threshold
+}
@@ -0,0 +1,122 @@
+// Package decode reads QR codes back from images.
+//
+// It exists to check that styled output still scans: the image is
+// binarized, the finder patterns located, the modules sampled through a
+// perspective transform, and the format information, error correction
+// and data segments decoded as a scanner would.
+//
+// Only QR Code symbols are read; Micro QR and rMQR symbols are not.
+//
+//	text, err := decode.Decode(img)
+package decode
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/ahmedtahas/qr-gode/internal/encoder"
+)
+
+var (
+	// ErrNotFound is returned when the image holds no recognizable QR code.
+	ErrNotFound = errors.New("no QR code found")
+
+	// ErrUnreadable is returned when a QR code was found but its format
+	// information, error correction or data could not be read.
+	ErrUnreadable = errors.New("QR code unreadable")
+)
+
+// Decode finds a QR code in img and returns the text it holds. Byte
+// data without an ECI header is read as UTF-8, or ISO-8859-1 if it is
+// not valid UTF-8.
+func Decode(img image.Image) (string, error) {
+	bits := binarize(img)
+	text, err := decodeBits(bits)
+
+	// Module shapes with gaps between neighbors, like hearts and stars,
+	// break up the finder patterns; closing the gaps makes them solid
+	for radius := 1; err != nil && radius <= min(bits.width, bits.height)/50; radius *= 2 {
+		if closed, cerr := decodeBits(bits.close(radius)); cerr == nil {
+			return closed, nil
+		}
+	}
+	return text, err
+}
+
+// decodeBits locates, samples and decodes a symbol in a binarized image.
+func decodeBits(bits *bitImage) (string, error) {
+	finders, err := findFinderPatterns(bits)
+	if err != nil {
+		return "", err
+	}
+
+	grid, err := sampleSymbol(bits, finders)
+	if err != nil {
+		return "", err
+	}
+
+	text, err := decodeGrid(grid)
+	if err != nil {
+		// A mirrored image is read transposed
+		if mirrored, merr := decodeGrid(grid.transpose()); merr == nil {
+			return mirrored, nil
+		}
+	}
+	return text, err
+}
+
+// decodeGrid reads the format information, codewords and segments of a
+// sampled symbol.
+func decodeGrid(grid *moduleGrid) (string, error) {
+	version := encoder.Version((grid.size - 17) / 4)
+
+	ecl, mask, ok := readFormatInfo(grid)
+	if !ok {
+		return "", fmt.Errorf("%w: format information", ErrUnreadable)
+	}
+
+	codewords := readCodewords(grid, version, ecl, mask)
+	data, err := correctBlocks(codewords, encoder.GetECCInfo(version, ecl))
+	if err != nil {
+		return "", err
+	}
+	return parseSegments(data, version)
+}
+
+// correctBlocks splits interleaved codewords into their error correction
+// blocks, corrects each and returns the data codewords in order.
+func correctBlocks(codewords []byte, info encoder.ECCInfo) ([]byte, error) {
+	var dataLens []int
+	for _, g := range []encoder.BlockInfo{info.Group1, info.Group2} {
+		for range g.Count {
+			dataLens = append(dataLens, g.DataCodewords)
+		}
+	}
+
+	blocks := make([][]byte, len(dataLens))
+	k := 0
+	for i := 0; i < info.Group1.DataCodewords+1; i++ {
+		for b := range blocks {
+			if i < dataLens[b] {
+				blocks[b] = append(blocks[b], codewords[k])
+				k++
+			}
+		}
+	}
+	for range info.ECCPerBlock {
+		for b := range blocks {
+			blocks[b] = append(blocks[b], codewords[k])
+			k++
+		}
+	}
+
+	var data []byte
+	for b, block := range blocks {
+		if _, err := encoder.ReedSolomonDecode(block, info.ECCPerBlock, nil); err != nil {
+			return nil, fmt.Errorf("%w: block %d has too many errors", ErrUnreadable, b+1)
+		}
+		data = append(data, block[:dataLens[b]]...)
+	}
+	return data, nil
+}
@@ -0,0 +1,128 @@
+package decode
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/ahmedtahas/qr-gode/internal/encoder"
+)
+
+// render draws a matrix with a 4-module quiet zone at scale pixels per
+// module, which may be fractional.
+func render(m *encoder.Matrix, scale float64) *image.Gray {
+	size := int(float64(m.Size()+8) * scale)
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for y := range size {
+		for x := range size {
+			mx, my := int(float64(x)/scale)-4, int(float64(y)/scale)-4
+			dark := mx >= 0 && my >= 0 && mx < m.Size() && my < m.Size() && m.Get(mx, my).Dark
+			if dark {
+				img.SetGray(x, y, color.Gray{Y: 0})
+			} else {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	return img
+}
+
+func encode(t *testing.T, data string, ecl encoder.ErrorCorrectionLevel, cs *encoder.Charset) *encoder.Matrix {
+	t.Helper()
+	enc := encoder.New(data, ecl)
+	enc.SetCharset(cs)
+	m, err := enc.Encode()
+	if err != nil {
+		t.Fatalf("Encode(%q) error = %v", data, err)
+	}
+	return m
+}
+
+func TestDecodeRoundTrip(t *testing.T) {
+	latin1, _ := encoder.LookupCharset("iso-8859-1")
+	tests := []struct {
+		name    string
+		data    string
+		ecl     encoder.ErrorCorrectionLevel
+		charset *encoder.Charset
+	}{
+		{"numeric", "0123456789012345", encoder.LevelL, nil},
+		{"alphanumeric", "HELLO WORLD", encoder.LevelQ, nil},
+		{"byte", "https://example.com/?q=1", encoder.LevelM, nil},
+		{"utf-8", "Grüße aus Köln", encoder.LevelH, nil},
+		{"kanji", "漢字モード", encoder.LevelM, nil},
+		{"eci", "café crème", encoder.LevelM, latin1},
+		{"mixed", "ORDER 12345678901234567890 for café", encoder.LevelL, nil},
+		{"version 7+", strings.Repeat("Version information ", 8), encoder.LevelM, nil},
+		{"many alignment patterns", strings.Repeat("0123456789abcdef", 40), encoder.LevelQ, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := render(encode(t, tt.data, tt.ecl, tt.charset), 4)
+			got, err := Decode(img)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if got != tt.data {
+				t.Errorf("Decode() = %q, want %q", got, tt.data)
+			}
+		})
+	}
+}
+
+func TestDecodeFractionalScale(t *testing.T) {
+	data := "https://example.com/fractional"
+	for _, scale := range []float64{2.5, 3.7, 6.3} {
+		got, err := Decode(render(encode(t, data, encoder.LevelM, nil), scale))
+		if err != nil || got != data {
+			t.Errorf("scale %.1f: Decode() = %q, %v", scale, got, err)
+		}
+	}
+}
+
+func TestDecodeCorrectsErrors(t *testing.T) {
+	data := "error correction at work"
+	m := encode(t, data, encoder.LevelH, nil)
+
+	// Flip a 4x4 patch of data modules in the middle
+	c := m.Size() / 2
+	for y := c - 2; y < c+2; y++ {
+		for x := c - 2; x < c+2; x++ {
+			mod := m.Get(x, y)
+			mod.Dark = !mod.Dark
+			m.Set(x, y, mod)
+		}
+	}
+
+	got, err := Decode(render(m, 5))
+	if err != nil || got != data {
+		t.Errorf("Decode() = %q, %v; want %q", got, err, data)
+	}
+}
+
+func TestDecodeMirrored(t *testing.T) {
+	data := "mirror"
+	img := render(encode(t, data, encoder.LevelM, nil), 4)
+	b := img.Bounds()
+	mirrored := image.NewGray(b)
+	for y := range b.Dy() {
+		for x := range b.Dx() {
+			mirrored.SetGray(b.Dx()-1-x, y, img.GrayAt(x, y))
+		}
+	}
+
+	got, err := Decode(mirrored)
+	if err != nil || got != data {
+		t.Errorf("Decode() = %q, %v; want %q", got, err, data)
+	}
+}
+
+func TestDecodeNotFound(t *testing.T) {
+	blank := image.NewGray(image.Rect(0, 0, 100, 100))
+	if _, err := Decode(blank); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Decode(blank) error = %v, want ErrNotFound", err)
+	}
+}
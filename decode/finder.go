@@ -0,0 +1,275 @@
+package decode
+
+import (
+	"math"
+	"sort"
+)
+
+// finderPattern is a candidate finder pattern center in pixels.
+type finderPattern struct {
+	x, y       float64
+	moduleSize float64
+	count      int // Scan lines that confirmed it
+}
+
+// findFinderPatterns scans the image for the 1:1:3:1:1 dark-light
+// ratio through the three finder patterns and returns them as top-left,
+// top-right and bottom-left.
+func findFinderPatterns(img *bitImage) ([3]finderPattern, error) {
+	var candidates []finderPattern
+	for y := 0; y < img.height; y++ {
+		var counts [5]int
+		state := 0
+		for x := 0; x < img.width; x++ {
+			if img.at(x, y) {
+				if state%2 == 1 {
+					state++
+				}
+				counts[state]++
+				continue
+			}
+			if state%2 == 1 {
+				counts[state]++
+				continue
+			}
+			if state == 4 {
+				if finderRatio(counts) {
+					candidates = addCandidate(candidates, img, counts, x, y)
+				}
+				// Keep the last dark-light-dark runs as a new start
+				counts = [5]int{counts[2], counts[3], counts[4], 1, 0}
+				state = 3
+				continue
+			}
+			state++
+			counts[state]++
+		}
+		if state == 4 && finderRatio(counts) {
+			candidates = addCandidate(candidates, img, counts, img.width, y)
+		}
+	}
+	return selectFinders(candidates)
+}
+
+// finderRatio reports whether five run lengths are close to 1:1:3:1:1.
+func finderRatio(counts [5]int) bool {
+	total := 0
+	for _, c := range counts {
+		if c == 0 {
+			return false
+		}
+		total += c
+	}
+	if total < 7 {
+		return false
+	}
+	module := float64(total) / 7
+	maxVariance := module / 2
+	return math.Abs(module-float64(counts[0])) < maxVariance &&
+		math.Abs(module-float64(counts[1])) < maxVariance &&
+		math.Abs(3*module-float64(counts[2])) < 3*maxVariance &&
+		math.Abs(module-float64(counts[3])) < maxVariance &&
+		math.Abs(module-float64(counts[4])) < maxVariance
+}
+
+// addCandidate cross-checks a horizontal match ending at endX
+// vertically and horizontally again through its center, and merges the
+// confirmed center into the candidates.
+func addCandidate(candidates []finderPattern, img *bitImage, counts [5]int, endX, y int) []finderPattern {
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	centerX := float64(endX-counts[4]-counts[3]) - float64(counts[2])/2
+
+	centerY, ok := crossCheck(img, int(centerX), y, 0, 1, counts[2], total)
+	if !ok {
+		return candidates
+	}
+	centerX, ok = crossCheck(img, int(centerX), int(centerY), 1, 0, counts[2], total)
+	if !ok {
+		return candidates
+	}
+	module := float64(total) / 7
+
+	for i, c := range candidates {
+		if math.Abs(c.x-centerX) <= c.moduleSize && math.Abs(c.y-centerY) <= c.moduleSize &&
+			math.Abs(c.moduleSize-module) <= math.Max(1, c.moduleSize/4) {
+			// Running average of the confirmed centers
+			n := float64(c.count)
+			candidates[i] = finderPattern{
+				x:          (c.x*n + centerX) / (n + 1),
+				y:          (c.y*n + centerY) / (n + 1),
+				moduleSize: (c.moduleSize*n + module) / (n + 1),
+				count:      c.count + 1,
+			}
+			return candidates
+		}
+	}
+	return append(candidates, finderPattern{x: centerX, y: centerY, moduleSize: module, count: 1})
+}
+
+// crossCheck counts the runs through (x, y) along direction (dx, dy),
+// outwards from the dark center, and returns the center of the pattern
+// along that direction if its runs are close to 1:1:3:1:1 and their
+// total to originalTotal. Runs other than the center may not be longer
+// than maxCount.
+func crossCheck(img *bitImage, x, y, dx, dy, maxCount, originalTotal int) (float64, bool) {
+	if !img.at(x, y) {
+		return 0, false
+	}
+	var counts [5]int
+
+	// Back from the center: center, light ring, outer ring
+	i := 0
+	for img.at(x-i*dx, y-i*dy) {
+		counts[2]++
+		i++
+		if !img.inBounds(x-i*dx, y-i*dy) {
+			return 0, false
+		}
+	}
+	for !img.at(x-i*dx, y-i*dy) && counts[1] <= maxCount {
+		counts[1]++
+		i++
+		if !img.inBounds(x-i*dx, y-i*dy) {
+			return 0, false
+		}
+	}
+	for img.at(x-i*dx, y-i*dy) && counts[0] <= maxCount {
+		counts[0]++
+		i++
+	}
+
+	// Forward from the center
+	i = 1
+	for img.at(x+i*dx, y+i*dy) {
+		counts[2]++
+		i++
+		if !img.inBounds(x+i*dx, y+i*dy) {
+			return 0, false
+		}
+	}
+	end := i
+	for !img.at(x+end*dx, y+end*dy) && counts[3] <= maxCount {
+		counts[3]++
+		end++
+		if !img.inBounds(x+end*dx, y+end*dy) {
+			return 0, false
+		}
+	}
+	for img.at(x+end*dx, y+end*dy) && counts[4] <= maxCount {
+		counts[4]++
+		end++
+	}
+
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	if counts[0] > maxCount || counts[4] > maxCount || 5*abs(total-originalTotal) >= 2*originalTotal {
+		return 0, false
+	}
+	if !finderRatio(counts) {
+		return 0, false
+	}
+	// end is one past the outer ring, relative to (x, y)
+	pos := x
+	if dy != 0 {
+		pos = y
+	}
+	return float64(pos+end-counts[4]-counts[3]) - float64(counts[2])/2, true
+}
+
+// inBounds reports whether (x, y) lies in the image.
+func (b *bitImage) inBounds(x, y int) bool {
+	return x >= 0 && y >= 0 && x < b.width && y < b.height
+}
+
+// selectFinders picks the three candidates that best form the corners
+// of a square and orders them top-left, top-right, bottom-left.
+func selectFinders(candidates []finderPattern) ([3]finderPattern, error) {
+	// Prefer patterns confirmed by several scan lines
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].count > candidates[j].count
+	})
+	confirmed := 0
+	for confirmed < len(candidates) && candidates[confirmed].count >= 2 {
+		confirmed++
+	}
+	if confirmed >= 3 {
+		candidates = candidates[:confirmed]
+	}
+	if len(candidates) < 3 {
+		return [3]finderPattern{}, ErrNotFound
+	}
+	candidates = candidates[:min(len(candidates), 12)]
+
+	var best [3]finderPattern
+	bestScore := math.Inf(1)
+	for i := 0; i < len(candidates); i++ {
+		for j := i + 1; j < len(candidates); j++ {
+			for k := j + 1; k < len(candidates); k++ {
+				tri := [3]finderPattern{candidates[i], candidates[j], candidates[k]}
+				if score := squareness(tri); score < bestScore {
+					best, bestScore = tri, score
+				}
+			}
+		}
+	}
+	if bestScore > 0.5 {
+		return [3]finderPattern{}, ErrNotFound
+	}
+
+	// The top-left pattern is opposite the longest side
+	ab, bc, ac := distance(best[0], best[1]), distance(best[1], best[2]), distance(best[0], best[2])
+	switch {
+	case bc >= ab && bc >= ac:
+		// best[0] is top-left
+	case ac >= ab && ac >= bc:
+		best[0], best[1] = best[1], best[0]
+	default:
+		best[0], best[2] = best[2], best[0]
+	}
+
+	// Top-right is clockwise from bottom-left (y points down)
+	tl, b, c := best[0], best[1], best[2]
+	if (b.x-tl.x)*(c.y-tl.y)-(b.y-tl.y)*(c.x-tl.x) < 0 {
+		b, c = c, b
+	}
+	return [3]finderPattern{tl, b, c}, nil
+}
+
+// squareness scores how far three patterns are from the corners of a
+// square with equally sized finders; 0 is perfect.
+func squareness(tri [3]finderPattern) float64 {
+	d := []float64{distance(tri[0], tri[1]), distance(tri[1], tri[2]), distance(tri[0], tri[2])}
+	sort.Float64s(d)
+	if d[0] == 0 {
+		return math.Inf(1)
+	}
+	// Right angle and equal legs
+	score := math.Abs(d[2]*d[2]-d[0]*d[0]-d[1]*d[1])/(d[2]*d[2]) + (d[1]-d[0])/d[1]
+
+	// Similar module sizes
+	lo := math.Min(tri[0].moduleSize, math.Min(tri[1].moduleSize, tri[2].moduleSize))
+	hi := math.Max(tri[0].moduleSize, math.Max(tri[1].moduleSize, tri[2].moduleSize))
+	score += (hi - lo) / hi
+
+	// At least 7 modules apart, as in version 1
+	if d[0] < 7*hi {
+		return math.Inf(1)
+	}
+	return score
+}
+
+func distance(a, b finderPattern) float64 {
+	return math.Hypot(a.x-b.x, a.y-b.y)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,237 @@
+package decode
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/ahmedtahas/qr-gode/internal/encoder"
+)
+
+// moduleGrid holds the sampled modules of a symbol; true is dark.
+type moduleGrid struct {
+	size    int
+	modules []bool
+}
+
+func (g *moduleGrid) at(x, y int) bool {
+	return g.modules[y*g.size+x]
+}
+
+// transpose returns the grid mirrored along its main diagonal.
+func (g *moduleGrid) transpose() *moduleGrid {
+	t := &moduleGrid{size: g.size, modules: make([]bool, len(g.modules))}
+	for y := range g.size {
+		for x := range g.size {
+			t.modules[x*g.size+y] = g.at(x, y)
+		}
+	}
+	return t
+}
+
+// sampleSymbol estimates the symbol size from the finder patterns and
+// samples its modules. Version information, from version 7 on,
+// overrides the estimated size.
+func sampleSymbol(img *bitImage, finders [3]finderPattern) (*moduleGrid, error) {
+	tl, tr, bl := finders[0], finders[1], finders[2]
+	module := (tl.moduleSize + tr.moduleSize + bl.moduleSize) / 3
+
+	// Finder centers are 3.5 modules in from the edges
+	across := (distance(tl, tr) + distance(tl, bl)) / 2 / module
+	version := int(math.Round((across + 7 - 17) / 4))
+	if version < 1 || version > 40 {
+		return nil, ErrNotFound
+	}
+
+	grid := sampleGrid(img, finders, version)
+	if version >= 7 {
+		if v, ok := readVersionInfo(grid); ok && v != version {
+			grid = sampleGrid(img, finders, v)
+		}
+	}
+	return grid, nil
+}
+
+// sampleGrid samples the modules of a symbol of the given version at
+// their centers.
+func sampleGrid(img *bitImage, finders [3]finderPattern, version int) *moduleGrid {
+	tl, tr, bl := finders[0], finders[1], finders[2]
+	size := encoder.Version(version).Size()
+	far := float64(size) - 3.5
+
+	// Map module coordinates to pixels through the finder centers and the
+	// bottom-right alignment pattern, or the fourth corner of the
+	// parallelogram when there is none
+	src := [4][2]float64{{3.5, 3.5}, {far, 3.5}, {far, far}, {3.5, far}}
+	dst := [4][2]float64{{tl.x, tl.y}, {tr.x, tr.y}, {tr.x + bl.x - tl.x, tr.y + bl.y - tl.y}, {bl.x, bl.y}}
+	if version >= 2 {
+		affine := quadToQuad(src, dst)
+		center := float64(size) - 6.5
+		ex, ey := affine.apply(center, center)
+		module := (tl.moduleSize + tr.moduleSize + bl.moduleSize) / 3
+		if ax, ay, ok := findAlignment(img, ex, ey, module); ok {
+			src[2] = [2]float64{center, center}
+			dst[2] = [2]float64{ax, ay}
+		}
+	}
+	transform := quadToQuad(src, dst)
+
+	grid := &moduleGrid{size: size, modules: make([]bool, size*size)}
+	for y := range size {
+		for x := range size {
+			px, py := transform.apply(float64(x)+0.5, float64(y)+0.5)
+			grid.modules[y*size+x] = img.at(int(math.Floor(px)), int(math.Floor(py)))
+		}
+	}
+	return grid
+}
+
+// alignmentTemplate lists module offsets from an alignment pattern's
+// center that are dark (true) or light. Only the cross through the
+// outer ring is checked, so circular patterns match too.
+var alignmentTemplate = []struct {
+	dx, dy int
+	dark   bool
+}{
+	{0, 0, true},
+	{-1, -1, false}, {0, -1, false}, {1, -1, false},
+	{-1, 0, false}, {1, 0, false},
+	{-1, 1, false}, {0, 1, false}, {1, 1, false},
+	{-2, 0, true}, {2, 0, true}, {0, -2, true}, {0, 2, true},
+}
+
+// findAlignment searches around the estimated pixel position (ex, ey)
+// for the bottom-right alignment pattern and returns its center.
+func findAlignment(img *bitImage, ex, ey, module float64) (float64, float64, bool) {
+	radius := int(math.Ceil(4 * module))
+	matches := func(cx, cy int) bool {
+		for _, t := range alignmentTemplate {
+			x := int(math.Floor(float64(cx) + float64(t.dx)*module))
+			y := int(math.Floor(float64(cy) + float64(t.dy)*module))
+			if img.at(x, y) != t.dark {
+				return false
+			}
+		}
+		return true
+	}
+
+	// The match closest to the estimate, then the centroid of the
+	// matching pixels around it
+	bestX, bestY, bestDist := 0, 0, math.Inf(1)
+	ix, iy := int(ex), int(ey)
+	for y := iy - radius; y <= iy+radius; y++ {
+		for x := ix - radius; x <= ix+radius; x++ {
+			d := math.Hypot(float64(x)-ex, float64(y)-ey)
+			if d < bestDist && matches(x, y) {
+				bestX, bestY, bestDist = x, y, d
+			}
+		}
+	}
+	if math.IsInf(bestDist, 1) {
+		return 0, 0, false
+	}
+
+	var sumX, sumY, n float64
+	reach := int(math.Ceil(module))
+	for y := bestY - reach; y <= bestY+reach; y++ {
+		for x := bestX - reach; x <= bestX+reach; x++ {
+			if matches(x, y) {
+				sumX += float64(x)
+				sumY += float64(y)
+				n++
+			}
+		}
+	}
+	return sumX/n + 0.5, sumY/n + 0.5, true
+}
+
+// readFormatInfo reads both copies of the format information and
+// returns the level and mask of the valid format string closest to
+// either, if it is within 3 bits.
+func readFormatInfo(grid *moduleGrid) (encoder.ErrorCorrectionLevel, encoder.MaskPattern, bool) {
+	size := grid.size
+	var first, second uint16
+	set := func(info *uint16, bit int, dark bool) {
+		if dark {
+			*info |= 1 << bit
+		}
+	}
+
+	// Around the top-left finder
+	for i := 0; i <= 5; i++ {
+		set(&first, i, grid.at(8, i))
+	}
+	set(&first, 6, grid.at(8, 7))
+	set(&first, 7, grid.at(8, 8))
+	set(&first, 8, grid.at(7, 8))
+	for i := 9; i <= 14; i++ {
+		set(&first, i, grid.at(14-i, 8))
+	}
+
+	// Below the top-right and beside the bottom-left finder
+	for i := 0; i <= 7; i++ {
+		set(&second, i, grid.at(size-1-i, 8))
+	}
+	for i := 8; i <= 14; i++ {
+		set(&second, i, grid.at(8, size-15+i))
+	}
+
+	best, bestDist := 0, 16
+	for ecl := encoder.LevelL; ecl <= encoder.LevelH; ecl++ {
+		for mask := encoder.Mask0; mask <= encoder.Mask7; mask++ {
+			info := encoder.FormatInfo(ecl, mask)
+			d := min(bits.OnesCount16(info^first), bits.OnesCount16(info^second))
+			if d < bestDist {
+				best, bestDist = int(ecl)<<3|int(mask), d
+			}
+		}
+	}
+	if bestDist > 3 {
+		return 0, 0, false
+	}
+	return encoder.ErrorCorrectionLevel(best >> 3), encoder.MaskPattern(best & 7), true
+}
+
+// readVersionInfo reads both copies of the version information and
+// returns the version closest to either, if it is within 3 bits.
+func readVersionInfo(grid *moduleGrid) (int, bool) {
+	size := grid.size
+	var first, second uint32
+	for i := range 6 {
+		for j := range 3 {
+			bit := uint32(1) << (i*3 + j)
+			if grid.at(size-11+j, i) {
+				first |= bit
+			}
+			if grid.at(i, size-11+j) {
+				second |= bit
+			}
+		}
+	}
+
+	best, bestDist := 0, 19
+	for v := 7; v <= 40; v++ {
+		info := encoder.VersionInfo(encoder.Version(v))
+		d := min(bits.OnesCount32(info^first), bits.OnesCount32(info^second))
+		if d < bestDist {
+			best, bestDist = v, d
+		}
+	}
+	return best, bestDist <= 3
+}
+
+// readCodewords unmasks the data modules and reads them in placement
+// order.
+func readCodewords(grid *moduleGrid, version encoder.Version, ecl encoder.ErrorCorrectionLevel, mask encoder.MaskPattern) []byte {
+	m := encoder.NewMatrix(version)
+	m.PlaceFunctionPatterns(version)
+	positions := m.DataPositions()
+
+	codewords := make([]byte, encoder.GetECCInfo(version, ecl).TotalCodewords)
+	for i := range len(codewords) * 8 {
+		x, y := positions[i][0], positions[i][1]
+		if grid.at(x, y) != mask.ShouldFlip(x, y) {
+			codewords[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return codewords
+}
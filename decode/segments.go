@@ -0,0 +1,199 @@
+package decode
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ahmedtahas/qr-gode/internal/encoder"
+)
+
+// alphanumericChars maps alphanumeric mode values to characters.
+const alphanumericChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// bitReader reads big-endian bit fields from data codewords.
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) available() int {
+	return len(r.data)*8 - r.pos
+}
+
+// read returns the next n bits, or false if fewer are left.
+func (r *bitReader) read(n int) (int, bool) {
+	if n > r.available() {
+		return 0, false
+	}
+	v := 0
+	for range n {
+		bit := r.data[r.pos/8] >> (7 - r.pos%8) & 1
+		v = v<<1 | int(bit)
+		r.pos++
+	}
+	return v, true
+}
+
+// parseSegments decodes the segments in the data codewords of a symbol.
+func parseSegments(data []byte, version encoder.Version) (string, error) {
+	r := &bitReader{data: data}
+	var out strings.Builder
+	var charset *encoder.Charset
+	var fnc1 bool
+
+	truncated := fmt.Errorf("%w: truncated segment", ErrUnreadable)
+	for r.available() >= 4 {
+		indicator, _ := r.read(4)
+		switch indicator {
+		case 0b0000: // Terminator
+			return out.String(), nil
+
+		case 0b0001: // Numeric
+			n, ok := r.read(encoder.ModeNumeric.CharCountBits(version))
+			if !ok {
+				return "", truncated
+			}
+			for ; n > 0; n -= 3 {
+				digits := min(n, 3)
+				v, ok := r.read([]int{0, 4, 7, 10}[digits])
+				if !ok {
+					return "", truncated
+				}
+				s := fmt.Sprintf("%0*d", digits, v)
+				if len(s) != digits {
+					return "", fmt.Errorf("%w: bad numeric data", ErrUnreadable)
+				}
+				out.WriteString(s)
+			}
+
+		case 0b0010: // Alphanumeric
+			n, ok := r.read(encoder.ModeAlphanumeric.CharCountBits(version))
+			if !ok {
+				return "", truncated
+			}
+			var s strings.Builder
+			for ; n > 0; n -= 2 {
+				chars := min(n, 2)
+				v, ok := r.read([]int{0, 6, 11}[chars])
+				if !ok {
+					return "", truncated
+				}
+				if v >= []int{0, 45, 45 * 45}[chars] {
+					return "", fmt.Errorf("%w: bad alphanumeric data", ErrUnreadable)
+				}
+				if chars == 2 {
+					s.WriteByte(alphanumericChars[v/45])
+				}
+				s.WriteByte(alphanumericChars[v%45])
+			}
+			text := s.String()
+			if fnc1 {
+				// In GS1 data % stands for the GS separator and %% for %
+				text = strings.ReplaceAll(text, "%%", "\x00")
+				text = strings.ReplaceAll(text, "%", "\x1d")
+				text = strings.ReplaceAll(text, "\x00", "%")
+			}
+			out.WriteString(text)
+
+		case 0b0100: // Byte
+			n, ok := r.read(encoder.ModeByte.CharCountBits(version))
+			if !ok {
+				return "", truncated
+			}
+			b := make([]byte, n)
+			for i := range b {
+				v, ok := r.read(8)
+				if !ok {
+					return "", truncated
+				}
+				b[i] = byte(v)
+			}
+			out.WriteString(decodeBytes(b, charset))
+
+		case 0b1000: // Kanji
+			n, ok := r.read(encoder.ModeKanji.CharCountBits(version))
+			if !ok {
+				return "", truncated
+			}
+			for range n {
+				v, ok := r.read(13)
+				if !ok {
+					return "", truncated
+				}
+				code := (v/0xC0)<<8 | v%0xC0
+				if code < 0x1F00 {
+					code += 0x8140
+				} else {
+					code += 0xC140
+				}
+				ch, ok := encoder.ShiftJISRune(uint16(code))
+				if !ok {
+					return "", fmt.Errorf("%w: bad Kanji data", ErrUnreadable)
+				}
+				out.WriteRune(ch)
+			}
+
+		case 0b0111: // ECI
+			assignment, ok := readECI(r)
+			if !ok {
+				return "", truncated
+			}
+			cs, ok := encoder.LookupECI(assignment)
+			if !ok {
+				return "", fmt.Errorf("%w: unsupported ECI %d", ErrUnreadable, assignment)
+			}
+			charset = cs
+
+		case 0b0011: // Structured Append: index, total and parity
+			if _, ok := r.read(16); !ok {
+				return "", truncated
+			}
+
+		case 0b0101: // FNC1 in first position (GS1)
+			fnc1 = true
+
+		case 0b1001: // FNC1 in second position: application indicator
+			if _, ok := r.read(8); !ok {
+				return "", truncated
+			}
+			fnc1 = true
+
+		default:
+			return "", fmt.Errorf("%w: unknown mode %04b", ErrUnreadable, indicator)
+		}
+	}
+	return out.String(), nil
+}
+
+// readECI reads an ECI designator of 1-3 bytes.
+func readECI(r *bitReader) (int, bool) {
+	first, ok := r.read(8)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case first&0x80 == 0:
+		return first, true
+	case first&0xC0 == 0x80:
+		rest, ok := r.read(8)
+		return (first&0x3F)<<8 | rest, ok
+	case first&0xE0 == 0xC0:
+		rest, ok := r.read(16)
+		return (first&0x1F)<<16 | rest, ok
+	}
+	return 0, false
+}
+
+// decodeBytes converts byte mode data to text in the announced charset.
+// Without one, UTF-8 is assumed unless the bytes are not valid UTF-8.
+func decodeBytes(b []byte, charset *encoder.Charset) string {
+	if charset != nil {
+		return charset.Decode(b)
+	}
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	latin1, _ := encoder.LookupCharset("iso-8859-1")
+	return latin1.Decode(b)
+}
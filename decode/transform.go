@@ -0,0 +1,70 @@
+package decode
+
+// perspective maps points of one plane to another:
+// x' = (h00 x + h01 y + h02) / (h20 x + h21 y + h22), likewise y'.
+type perspective [3][3]float64
+
+// quadToQuad returns the transform taking the corners of src to the
+// corners of dst, both in the order (0,0), (1,0), (1,1), (0,1) of the
+// unit square.
+func quadToQuad(src, dst [4][2]float64) perspective {
+	return squareToQuad(dst).mul(squareToQuad(src).adjugate())
+}
+
+// squareToQuad returns the transform taking the unit square to the
+// quadrilateral q.
+func squareToQuad(q [4][2]float64) perspective {
+	x0, y0 := q[0][0], q[0][1]
+	x1, y1 := q[1][0], q[1][1]
+	x2, y2 := q[2][0], q[2][1]
+	x3, y3 := q[3][0], q[3][1]
+
+	dx3, dy3 := x0-x1+x2-x3, y0-y1+y2-y3
+	if dx3 == 0 && dy3 == 0 {
+		// A parallelogram is an affine transform
+		return perspective{
+			{x1 - x0, x2 - x1, x0},
+			{y1 - y0, y2 - y1, y0},
+			{0, 0, 1},
+		}
+	}
+	dx1, dx2 := x1-x2, x3-x2
+	dy1, dy2 := y1-y2, y3-y2
+	den := dx1*dy2 - dx2*dy1
+	g := (dx3*dy2 - dx2*dy3) / den
+	h := (dx1*dy3 - dx3*dy1) / den
+	return perspective{
+		{x1 - x0 + g*x1, x3 - x0 + h*x3, x0},
+		{y1 - y0 + g*y1, y3 - y0 + h*y3, y0},
+		{g, h, 1},
+	}
+}
+
+// adjugate returns the inverse of p up to scale, which is all a
+// projective transform needs.
+func (p perspective) adjugate() perspective {
+	return perspective{
+		{p[1][1]*p[2][2] - p[1][2]*p[2][1], p[0][2]*p[2][1] - p[0][1]*p[2][2], p[0][1]*p[1][2] - p[0][2]*p[1][1]},
+		{p[1][2]*p[2][0] - p[1][0]*p[2][2], p[0][0]*p[2][2] - p[0][2]*p[2][0], p[0][2]*p[1][0] - p[0][0]*p[1][2]},
+		{p[1][0]*p[2][1] - p[1][1]*p[2][0], p[0][1]*p[2][0] - p[0][0]*p[2][1], p[0][0]*p[1][1] - p[0][1]*p[1][0]},
+	}
+}
+
+// mul returns the transform applying q, then p.
+func (p perspective) mul(q perspective) perspective {
+	var r perspective
+	for i := range 3 {
+		for j := range 3 {
+			for k := range 3 {
+				r[i][j] += p[i][k] * q[k][j]
+			}
+		}
+	}
+	return r
+}
+
+// apply maps the point (x, y).
+func (p perspective) apply(x, y float64) (float64, float64) {
+	w := p[2][0]*x + p[2][1]*y + p[2][2]
+	return (p[0][0]*x + p[0][1]*y + p[0][2]) / w, (p[1][0]*x + p[1][1]*y + p[1][2]) / w
+}
@@ -10,10 +10,14 @@
 //	}
 //	os.WriteFile("qr.svg", svg, 0644)
 //
-// Or save directly to a file:
+// Or save directly to a file (.svg, .png, .pdf, .eps or .txt):
 //
 //	err := qrgode.GenerateToFile("https://example.com", nil, "qr.svg")
 //
+// Or stream it to any io.Writer, such as an http.ResponseWriter:
+//
+//	err := qrgode.GenerateTo(w, "https://example.com", nil, qrgode.FormatSVG)
+//
 // # Builder Pattern
 //
 // For customization, use the fluent builder API:
@@ -27,7 +31,15 @@
 //
 //	svg, err := qr.SVG()
 //	// or
-//	err := qr.SaveAs("qr.svg")
+//	png, err := qr.PNG()
+//	// or
+//	pdf, err := qr.PhysicalSize(30).PDF() // 30 mm wide, for print
+//	// or
+//	eps, err := qr.CMYK().EPS() // or SpotColor("PANTONE 286 C", ...)
+//	// or
+//	text, err := qr.Terminal() // ANSI colored, or UTF8() and ASCII()
+//	// or
+//	err := qr.SaveAs("qr.png")
 //
 // # Gradients
 //
@@ -41,6 +53,62 @@
 //	qr := qrgode.New("https://example.com").
 //		RadialGradient(0.5, 0.5, "#ff0000", "#0000ff")
 //
+//	// Each module colored from a photo, darkened 30% for contrast
+//	qr := qrgode.New("https://example.com").
+//		ImageColors("photo.jpg", 0.3)
+//
+// # Character Sets
+//
+// Announce the character set of non-ASCII text with an ECI header, so
+// scanners do not fall back to ISO-8859-1:
+//
+//	qr := qrgode.New("Grüße aus Köln").
+//		Charset(qrgode.CharsetAuto) // UTF-8, header only when needed
+//
+// # GS1
+//
+// GS1 element strings are validated and encoded with an FNC1 header:
+//
+//	svg, err := qrgode.New("(01)09506000134352(17)201225(10)ABC123").GS1().SVG()
+//
+// ParseGS1, GS1ElementString and GS1DigitalLink work with the elements
+// directly.
+//
+// # Payloads
+//
+// The payload package builds the text formats scanners act on, with
+// escaping and validation. NewPayload encodes any of them, NewWiFi a
+// WiFi join code, NewVCard and NewMeCard a contact, NewEPC a SEPA credit
+// transfer and NewSwissQRBill a Swiss QR-bill, each at the error
+// correction level its standard requires:
+//
+//	qr := qrgode.NewWiFi(payload.WiFi{SSID: "Office", Password: "correct horse"})
+//
+// FitVersion drops optional contact fields until the data fits a
+// version:
+//
+//	qr := qrgode.NewVCard(card).FitVersion(10)
+//
+// # Structured Append
+//
+// Split data too long for one QR code across up to 16 linked symbols:
+//
+//	symbols, err := qrgode.New(export).StructuredAppend() // One SVG each
+//	grid, err := qrgode.New(export).StructuredAppendGrid(0) // One SVG
+//
+// # Micro QR Code
+//
+// Short data fits in a smaller Micro QR Code with one finder pattern:
+//
+//	qr := qrgode.New("01234567").Symbol(qrgode.SymbolMicro)
+//
+// # rMQR
+//
+// Rectangular Micro QR symbols suit long, narrow spaces. The smallest
+// rectangle no higher than MaxHeight modules is chosen:
+//
+//	qr := qrgode.New("https://example.com").Symbol(qrgode.SymbolRMQR).MaxHeight(9)
+//
 // # Custom Images
 //
 // Use custom PNG/JPG images for QR elements:
@@ -62,6 +130,39 @@
 //	qr := qrgode.New("https://example.com").
 //		LogoImage(myImage)
 //
+// A logo hides the modules behind it. When it hides more codewords than
+// the error correction level recovers, the level and then the version are
+// raised. LogoPolicy can shrink the logo or fail with a
+// *LogoCoverageError instead.
+//
+// # Encoding Metadata
+//
+// Encode returns a Symbol with the version, mask, segments and capacity
+// chosen for the data, and its modules through IsDark and TypeAt:
+//
+//	symbol, err := qrgode.Encode("https://example.com", qrgode.LevelM)
+//	fmt.Println(symbol.Version, symbol.Mode, symbol.IsDark(0, 0))
+//
+// QRCode.Encode does the same for a configured code.
+//
+// # Verifying Output
+//
+// Check that a heavily styled code still scans by decoding its image:
+//
+//	err := qrgode.New("https://example.com").Shape(qrgode.ShapeHeart).Verify()
+//
+// The decoder is also available as decode.Decode for any image.Image.
+//
+// # Configuration Files
+//
+// Styles can be loaded from TOML files like those in examples/configs:
+//
+//	cfg, data, err := qrgode.LoadConfig("style.toml")
+//	if err != nil {
+//		log.Fatal(err) // Reports file, line and column
+//	}
+//	svg, err := qrgode.Generate(data, cfg)
+//
 // # Shapes
 //
 // Available module shapes:
@@ -80,4 +181,7 @@
 //   - LevelM: ~15% recovery (default)
 //   - LevelQ: ~25% recovery
 //   - LevelH: ~30% recovery (densest QR)
+//
+// BoostECL raises the level as far as the data still fits the version it
+// needs at the configured level.
 package qrgode
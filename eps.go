@@ -0,0 +1,278 @@
+package qrgode
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/ascii85"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/ahmedtahas/qr-gode/internal/colors"
+	"github.com/ahmedtahas/qr-gode/internal/raster"
+)
+
+// epsProlog abbreviates the path operators to the names used by PDF, so
+// both formats share writePathOps.
+const epsProlog = `/m {moveto} bind def
+/l {lineto} bind def
+/c {curveto} bind def
+/h {closepath} bind def
+`
+
+// renderEPS generates the QR code as an Encapsulated PostScript file.
+func (r *renderer) renderEPS() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.writeEPS(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeEPS streams an EPS file of the QR code to w. The shapes are drawn
+// as PostScript paths with curves kept exact, in RGB, CMYK or a spot
+// color. PostScript has no transparency: translucent colors are drawn
+// opaque and the logo is flattened onto its background.
+func (r *renderer) writeEPS(out io.Writer) error {
+	if r.hasCustomImages() {
+		return errors.New("custom element images are not supported in EPS output")
+	}
+
+	// The body is rendered first so that errors leave nothing written
+	page := &epsPage{color: rgbColor, spot: r.config.Spot}
+	if r.config.CMYK {
+		page.color = cmykColor
+	}
+
+	width, height := r.pixelSize()
+	scale := r.pageScale()
+	// Flip the y axis so the body uses SVG pixel coordinates
+	fmt.Fprintf(&page.body, "0 %s translate\n%s %s scale\n",
+		vectorNum(float64(height)*scale), vectorNum(scale), vectorNum(-scale))
+
+	bg := r.config.Background
+	if bg == nil {
+		bg = colors.NewSolid("#FFFFFF")
+	}
+	if err := page.fill(fmt.Sprintf("M0 0H%dV%dH0Z", width, height), bg, false, false); err != nil {
+		return fmt.Errorf("invalid background color: %w", err)
+	}
+
+	layers, err := r.shapeLayers()
+	if err != nil {
+		return err
+	}
+	for _, layer := range layers {
+		if err := page.fill(layer.path, layer.color, layer.evenOdd, true); err != nil {
+			return fmt.Errorf("invalid module color: %w", err)
+		}
+	}
+
+	if r.hasLogo() {
+		if err := r.epsLogo(page, bg); err != nil {
+			return err
+		}
+	}
+
+	w := bufio.NewWriter(out)
+	pageW, pageH := float64(width)*scale, float64(height)*scale
+	fmt.Fprintf(w, "%%!PS-Adobe-3.0 EPSF-3.0\n%%%%Creator: qr-gode\n")
+	fmt.Fprintf(w, "%%%%BoundingBox: 0 0 %d %d\n", int(math.Ceil(pageW)), int(math.Ceil(pageH)))
+	fmt.Fprintf(w, "%%%%HiResBoundingBox: 0 0 %s %s\n", vectorNum(pageW), vectorNum(pageH))
+	w.WriteString("%%LanguageLevel: 3\n")
+	if page.spot != nil {
+		name := psString(page.spot.Name)
+		fmt.Fprintf(w, "%%%%DocumentCustomColors: %s\n", name)
+		fmt.Fprintf(w, "%%%%CMYKCustomColor: %s %s %s %s %s\n",
+			vectorNum(page.spot.C), vectorNum(page.spot.M), vectorNum(page.spot.Y), vectorNum(page.spot.K), name)
+	}
+	w.WriteString("%%EndComments\n%%BeginProlog\n")
+	w.WriteString(epsProlog)
+	if page.spot != nil {
+		// The tint transform scales the CMYK equivalent by the tint
+		fmt.Fprintf(w, "/qrspot [/Separation %s /DeviceCMYK {dup %s mul exch dup %s mul exch dup %s mul exch %s mul}] def\n",
+			psString(page.spot.Name), vectorNum(page.spot.C), vectorNum(page.spot.M), vectorNum(page.spot.Y), vectorNum(page.spot.K))
+	}
+	w.WriteString("%%EndProlog\ngsave\n")
+	w.Write(page.body.Bytes())
+	w.WriteString("grestore\nshowpage\n%%EOF\n")
+	return w.Flush()
+}
+
+// epsLogo draws the logo and its background in the center of the page.
+func (r *renderer) epsLogo(page *epsPage, bg colors.Color) error {
+	logo := r.config.Logo
+
+	logoImg := logo.Image
+	if logoImg == nil {
+		var err error
+		logoImg, err = loadRasterImage(logo.Path)
+		if err != nil {
+			return fmt.Errorf("failed to load logo: %w", err)
+		}
+	}
+
+	logoWidth, logoHeight, padding, err := r.calculateLogoDimensions()
+	if err != nil {
+		return err
+	}
+
+	width, height := r.pixelSize()
+	logoX := (float64(width) - logoWidth) / 2
+	logoY := (float64(height) - logoHeight) / 2
+
+	// Transparent logo pixels are flattened onto what lies beneath
+	matte := bg.ColorAt(0.5, 0.5)
+	if s, ok := vectorColor(bg).(*colors.Solid); ok {
+		matte = s.Hex
+	}
+	bgColor := logo.Background
+	if bgColor == "" {
+		bgColor = "#FFFFFF"
+	}
+	if bgColor != "transparent" {
+		d := roundedRectPath(logoX-padding, logoY-padding, logoWidth+2*padding, logoHeight+2*padding, padding/2)
+		if err := page.fill(d, colors.NewSolid(bgColor), false, false); err != nil {
+			return fmt.Errorf("invalid logo background: %w", err)
+		}
+		matte = bgColor
+	}
+	under, err := colors.ToRGBA(matte)
+	if err != nil {
+		return fmt.Errorf("invalid logo background: %w", err)
+	}
+
+	page.image(logoImg, under, logoX, logoY, logoWidth, logoHeight)
+	return nil
+}
+
+// epsPage collects the PostScript body of an EPS file.
+type epsPage struct {
+	body  bytes.Buffer
+	color deviceColor // Process color space
+	spot  *SpotColor  // Ink of the modules, nil for process colors
+}
+
+// fill fills SVG path data in pixel coordinates with c, or with the spot
+// color if module is set and one is configured. Gradients span the
+// bounding box of the path, as in PDF.
+func (p *epsPage) fill(d string, c colors.Color, evenOdd, module bool) error {
+	outline, err := raster.ParseOutline(d)
+	if err != nil {
+		return fmt.Errorf("invalid shape path: %w", err)
+	}
+	if len(outline) == 0 {
+		return nil
+	}
+
+	fillOp := "fill"
+	if evenOdd {
+		fillOp = "eofill"
+	}
+
+	if module && p.spot != nil {
+		p.body.WriteString("qrspot setcolorspace 1 setcolor\n")
+		writePathOps(&p.body, outline)
+		fmt.Fprintf(&p.body, "%s\n", fillOp)
+		return nil
+	}
+
+	switch g := vectorColor(c).(type) {
+	case *colors.Solid:
+		rgba, err := colors.ToRGBA(g.Hex)
+		if err != nil {
+			return err
+		}
+		if rgba.A == 0 {
+			return nil
+		}
+		fmt.Fprintf(&p.body, "%s setcolorspace %s setcolor\n", p.color.space, p.color.components(rgba))
+		writePathOps(&p.body, outline)
+		fmt.Fprintf(&p.body, "%s\n", fillOp)
+	case *colors.LinearGradient, *colors.RadialGradient:
+		shading, err := shadingDict(g, p.color)
+		if err != nil {
+			return err
+		}
+		box, err := gradientBox(d)
+		if err != nil {
+			return err
+		}
+		clipOp := "clip"
+		if evenOdd {
+			clipOp = "eoclip"
+		}
+		// One function per line keeps lines short, as DSC asks
+		shading = strings.ReplaceAll(shading, " << /FunctionType", "\n<< /FunctionType")
+		p.body.WriteString("gsave\n")
+		writePathOps(&p.body, outline)
+		fmt.Fprintf(&p.body, "%s newpath\n[%s] concat\n%s shfill\ngrestore\n", clipOp, box, shading)
+	}
+	return nil
+}
+
+// image draws img into the box (x, y, w, h) as inline image data,
+// flattened onto the color under.
+func (p *epsPage) image(img image.Image, under color.RGBA, x, y, w, h float64) {
+	b := img.Bounds()
+	components := 3
+	if p.color.space == cmykColor.space {
+		components = 4
+	}
+
+	data := make([]byte, 0, components*b.Dx()*b.Dy())
+	for py := b.Min.Y; py < b.Max.Y; py++ {
+		for px := b.Min.X; px < b.Max.X; px++ {
+			c := color.NRGBAModel.Convert(img.At(px, py)).(color.NRGBA)
+			blend := func(v, u uint8) uint8 {
+				return uint8((uint32(v)*uint32(c.A) + uint32(u)*(255-uint32(c.A)) + 127) / 255)
+			}
+			r, g, bl := blend(c.R, under.R), blend(c.G, under.G), blend(c.B, under.B)
+			if components == 4 {
+				cc, m, yy, k := color.RGBToCMYK(r, g, bl)
+				data = append(data, cc, m, yy, k)
+			} else {
+				data = append(data, r, g, bl)
+			}
+		}
+	}
+
+	// The unit square maps onto the box with its top row first, as the
+	// image matrix expects
+	decode := strings.TrimSpace(strings.Repeat("0 1 ", components))
+	fmt.Fprintf(&p.body, "gsave\n%s %s translate\n%s %s scale\n%s setcolorspace\n",
+		vectorNum(x), vectorNum(y), vectorNum(w), vectorNum(h), p.color.space)
+	fmt.Fprintf(&p.body, "<< /ImageType 1 /Width %d /Height %d /BitsPerComponent 8 /Decode [%s] /ImageMatrix [%d 0 0 %d 0 0]\n",
+		b.Dx(), b.Dy(), decode, b.Dx(), b.Dy())
+	p.body.WriteString("/DataSource currentfile /ASCII85Decode filter /FlateDecode filter >> image\n")
+	writeASCII85(&p.body, deflate(data))
+	p.body.WriteString("grestore\n")
+}
+
+// writeASCII85 writes data in ASCII85 lines of at most 76 characters,
+// ending with the ~> marker. Lines never start with %, so they cannot be
+// mistaken for comments.
+func writeASCII85(w *bytes.Buffer, data []byte) {
+	encoded := make([]byte, ascii85.MaxEncodedLen(len(data)))
+	encoded = encoded[:ascii85.Encode(encoded, data)]
+	for len(encoded) > 0 {
+		n := min(len(encoded), 76)
+		if encoded[0] == '%' {
+			w.WriteByte(' ') // Whitespace is skipped by the decoder
+		}
+		w.Write(encoded[:n])
+		w.WriteByte('\n')
+		encoded = encoded[n:]
+	}
+	w.WriteString("~>\n")
+}
+
+// psString returns s as a PostScript string literal.
+func psString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+	return "(" + r.Replace(s) + ")"
+}
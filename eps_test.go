@@ -0,0 +1,135 @@
+package qrgode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEPSGeneration(t *testing.T) {
+	data, err := New("https://example.com").Size(290).EPS()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	eps := string(data)
+	if !strings.HasPrefix(eps, "%!PS-Adobe-3.0 EPSF-3.0\n") {
+		t.Errorf("expected EPS header, got %q", eps[:min(len(eps), 24)])
+	}
+	if !strings.Contains(eps, "%%BoundingBox: 0 0 290 290\n") {
+		t.Error("expected a 290 point bounding box")
+	}
+	if !strings.Contains(eps, "/DeviceRGB setcolorspace 0 0 0 setcolor\n") || !strings.Contains(eps, "\nfill\n") {
+		t.Error("expected black filled paths")
+	}
+	if !strings.HasSuffix(eps, "showpage\n%%EOF\n") {
+		t.Error("expected the file to end with the EOF comment")
+	}
+	for i, line := range strings.Split(eps, "\n") {
+		if len(line) > 255 {
+			t.Errorf("line %d is %d characters long, DSC allows 255", i+1, len(line))
+		}
+	}
+}
+
+func TestEPSBoundingBox(t *testing.T) {
+	// 46 mm is 130.39 points, rounded up for the integer bounding box
+	data, err := New("https://example.com").PhysicalSize(46).EPS()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(data, []byte("%%BoundingBox: 0 0 131 131\n")) {
+		t.Error("expected a 131 point bounding box")
+	}
+	if !bytes.Contains(data, []byte("%%HiResBoundingBox: 0 0 130.394 130.394\n")) {
+		t.Error("expected the exact size in the high resolution bounding box")
+	}
+}
+
+func TestEPSColors(t *testing.T) {
+	tests := []struct {
+		name string
+		qr   *QRCode
+		want []string
+	}{
+		{"cmyk", New("test").Foreground("#ff0000").CMYK(),
+			[]string{"/DeviceCMYK setcolorspace 0 1 1 0 setcolor\n"}},
+		{"spot", New("test").SpotColor("PANTONE 286 C", 1, 0.66, 0, 0.02), []string{
+			"%%DocumentCustomColors: (PANTONE 286 C)\n",
+			"%%CMYKCustomColor: 1 0.66 0 0.02 (PANTONE 286 C)\n",
+			"/qrspot [/Separation (PANTONE 286 C) /DeviceCMYK",
+			"qrspot setcolorspace 1 setcolor\n",
+		}},
+		{"gradient", New("test").RadialGradient(0.5, 0.5, "#ff0000", "#0000ff").CMYK(), []string{
+			"/ShadingType 3 /ColorSpace /DeviceCMYK",
+			"shfill\n",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.qr.EPS()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !bytes.Contains(data, []byte(want)) {
+					t.Errorf("expected %q in output", want)
+				}
+			}
+		})
+	}
+
+	if _, err := New("test").SpotColor("", 0, 0, 0, 1).EPS(); err == nil {
+		t.Error("expected error for a spot color without name")
+	}
+	if _, err := New("test").SpotColor("Gold", 0, 0.2, 1.5, 0).EPS(); err == nil {
+		t.Error("expected error for a CMYK component above 1")
+	}
+}
+
+func TestEPSWithLogo(t *testing.T) {
+	logo := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	logo.SetNRGBA(10, 10, color.NRGBA{255, 0, 0, 255})
+
+	data, err := New("https://example.com").ErrorCorrection(LevelH).LogoImage(logo).EPS()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	eps := string(data)
+	if !strings.Contains(eps, "/ImageType 1 /Width 20 /Height 20") {
+		t.Error("expected the logo as a PostScript image")
+	}
+	if !strings.Contains(eps, "/ASCII85Decode filter /FlateDecode filter >> image\n") || !strings.Contains(eps, "\n~>\n") {
+		t.Error("expected ASCII85 encoded image data")
+	}
+}
+
+func TestEPSRejectsCustomImages(t *testing.T) {
+	dir := t.TempDir()
+	modulePath := filepath.Join(dir, "module.png")
+	writeTestPNG(t, modulePath, 8, 8, color.RGBA{0, 128, 0, 255})
+
+	if _, err := New("test").ModuleImage(modulePath).EPS(); err == nil {
+		t.Error("expected error for custom images in EPS output")
+	}
+}
+
+func TestGenerateToFileEPS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "qr.eps")
+	if err := GenerateToFile("https://example.com", nil, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("%!PS-Adobe-3.0 EPSF-3.0")) {
+		t.Error("expected an EPS file")
+	}
+}
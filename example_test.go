@@ -1,6 +1,7 @@
 package qrgode_test
 
 import (
+	"bytes"
 	"fmt"
 	"log"
 
@@ -85,6 +86,18 @@ func Example_saveToFile() {
 	}
 }
 
+func Example_stream() {
+	// Stream straight into an http.ResponseWriter, gzip.Writer or file
+	var w bytes.Buffer
+	err := qrgode.New("https://example.com").
+		Shape("rounded").
+		WritePNG(&w)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Wrote %d bytes of PNG\n", w.Len())
+}
+
 func Example_advancedConfig() {
 	// For advanced usage, access the underlying config
 	qr := qrgode.New("https://example.com")
@@ -0,0 +1,355 @@
+package qrgode
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GS1Element is one GS1 application identifier (AI) and its value, such
+// as AI "01" with a GTIN or AI "10" with a batch number.
+type GS1Element struct {
+	AI    string
+	Value string
+}
+
+// GS1Error reports GS1 data that does not follow the GS1 General
+// Specifications.
+type GS1Error struct {
+	AI      string // Application identifier, empty for errors in the syntax
+	Message string
+}
+
+func (e *GS1Error) Error() string {
+	if e.AI == "" {
+		return "GS1: " + e.Message
+	}
+	return fmt.Sprintf("GS1 (%s): %s", e.AI, e.Message)
+}
+
+// gs1Part is one part of an AI value.
+type gs1Part struct {
+	numeric  bool // Digits only, otherwise the GS1 character set 82
+	min, max int  // Length; equal for fixed-length parts
+	check    bool // Ends in a GS1 check digit
+	date     bool // YYMMDD, day 00 meaning the whole month
+}
+
+// gs1AI describes the value of an application identifier.
+type gs1AI struct {
+	digits int // Length of the AI, including a decimal point digit
+	parts  []gs1Part
+}
+
+func gs1Fixed(n int) gs1Part  { return gs1Part{numeric: true, min: n, max: n} }
+func gs1Check(n int) gs1Part  { return gs1Part{numeric: true, min: n, max: n, check: true} }
+func gs1Digits(n int) gs1Part { return gs1Part{numeric: true, min: 1, max: n} }
+func gs1Text(n int) gs1Part   { return gs1Part{min: 1, max: n} }
+
+// gs1Optional makes a trailing part optional.
+func gs1Optional(p gs1Part) gs1Part {
+	p.min = 0
+	return p
+}
+
+var gs1Date = gs1Part{numeric: true, min: 6, max: 6, date: true}
+
+// gs1AIs holds the application identifiers in common use, keyed by the
+// AI without its decimal point digit.
+var gs1AIs = map[string]gs1AI{
+	"00":   {2, []gs1Part{gs1Check(18)}}, // SSCC
+	"01":   {2, []gs1Part{gs1Check(14)}}, // GTIN
+	"02":   {2, []gs1Part{gs1Check(14)}}, // GTIN of contained trade items
+	"10":   {2, []gs1Part{gs1Text(20)}},  // Batch or lot number
+	"11":   {2, []gs1Part{gs1Date}},      // Production date
+	"12":   {2, []gs1Part{gs1Date}},      // Due date
+	"13":   {2, []gs1Part{gs1Date}},      // Packaging date
+	"15":   {2, []gs1Part{gs1Date}},      // Best before date
+	"16":   {2, []gs1Part{gs1Date}},      // Sell by date
+	"17":   {2, []gs1Part{gs1Date}},      // Expiration date
+	"20":   {2, []gs1Part{gs1Fixed(2)}},  // Internal product variant
+	"21":   {2, []gs1Part{gs1Text(20)}},  // Serial number
+	"22":   {2, []gs1Part{gs1Text(20)}},  // Consumer product variant
+	"235":  {3, []gs1Part{gs1Text(28)}},
+	"240":  {3, []gs1Part{gs1Text(30)}},
+	"241":  {3, []gs1Part{gs1Text(30)}},
+	"242":  {3, []gs1Part{gs1Digits(6)}},
+	"243":  {3, []gs1Part{gs1Text(20)}},
+	"250":  {3, []gs1Part{gs1Text(30)}},
+	"251":  {3, []gs1Part{gs1Text(30)}},
+	"253":  {3, []gs1Part{gs1Check(13), gs1Optional(gs1Text(17))}}, // GDTI
+	"254":  {3, []gs1Part{gs1Text(20)}},
+	"255":  {3, []gs1Part{gs1Check(13), gs1Optional(gs1Digits(12))}}, // GCN
+	"30":   {2, []gs1Part{gs1Digits(8)}},                             // Variable count
+	"37":   {2, []gs1Part{gs1Digits(8)}},                             // Count of trade items
+	"390":  {4, []gs1Part{gs1Digits(15)}},
+	"391":  {4, []gs1Part{gs1Fixed(3), gs1Digits(15)}},
+	"392":  {4, []gs1Part{gs1Digits(15)}},
+	"393":  {4, []gs1Part{gs1Fixed(3), gs1Digits(15)}},
+	"400":  {3, []gs1Part{gs1Text(30)}},
+	"401":  {3, []gs1Part{gs1Text(30)}},  // GINC
+	"402":  {3, []gs1Part{gs1Check(17)}}, // GSIN
+	"403":  {3, []gs1Part{gs1Text(30)}},
+	"420":  {3, []gs1Part{gs1Text(20)}},
+	"421":  {3, []gs1Part{gs1Fixed(3), gs1Text(9)}},
+	"422":  {3, []gs1Part{gs1Fixed(3)}},
+	"426":  {3, []gs1Part{gs1Fixed(3)}},
+	"7003": {4, []gs1Part{gs1Fixed(10)}},
+	"8003": {4, []gs1Part{gs1Check(14), gs1Optional(gs1Text(16))}}, // GRAI
+	"8004": {4, []gs1Part{gs1Text(30)}},                            // GIAI
+	"8005": {4, []gs1Part{gs1Fixed(6)}},
+	"8006": {4, []gs1Part{gs1Check(14), gs1Fixed(4)}}, // ITIP
+	"8008": {4, []gs1Part{gs1Fixed(8), gs1Optional(gs1Digits(4))}},
+	"8010": {4, []gs1Part{gs1Text(30)}},   // CPID
+	"8011": {4, []gs1Part{gs1Digits(12)}}, // CPID serial number
+	"8017": {4, []gs1Part{gs1Check(18)}},  // GSRN provider
+	"8018": {4, []gs1Part{gs1Check(18)}},  // GSRN recipient
+	"8019": {4, []gs1Part{gs1Digits(10)}},
+	"8020": {4, []gs1Part{gs1Text(25)}},
+	"8200": {4, []gs1Part{gs1Text(70)}},
+	"90":   {2, []gs1Part{gs1Text(30)}},
+}
+
+func init() {
+	// Trade measures with a decimal point digit, and the GLNs of 410-417
+	for _, r := range [][2]int{{310, 316}, {320, 337}, {340, 357}, {360, 369}} {
+		for ai := r[0]; ai <= r[1]; ai++ {
+			gs1AIs[fmt.Sprint(ai)] = gs1AI{4, []gs1Part{gs1Fixed(6)}}
+		}
+	}
+	for ai := 410; ai <= 417; ai++ {
+		gs1AIs[fmt.Sprint(ai)] = gs1AI{3, []gs1Part{gs1Check(13)}}
+	}
+	for ai := 91; ai <= 99; ai++ {
+		gs1AIs[fmt.Sprint(ai)] = gs1AI{2, []gs1Part{gs1Text(90)}} // Company internal
+	}
+}
+
+// gs1Predefined holds the first two digits of the AIs whose values have
+// a predefined length and need no separator.
+var gs1Predefined = map[string]bool{
+	"00": true, "01": true, "02": true, "03": true, "04": true,
+	"11": true, "12": true, "13": true, "14": true, "15": true,
+	"16": true, "17": true, "18": true, "19": true, "20": true,
+	"31": true, "32": true, "33": true, "34": true, "35": true,
+	"36": true, "41": true,
+}
+
+// gs1Charset82 holds the characters allowed in alphanumeric AI values.
+const gs1Charset82 = "!\"%&'()*+,-./0123456789:;<=>?ABCDEFGHIJKLMNOPQRSTUVWXYZ_abcdefghijklmnopqrstuvwxyz"
+
+// lookupGS1 returns the description of ai.
+func lookupGS1(ai string) (gs1AI, bool) {
+	for n := 2; n <= min(len(ai), 4); n++ {
+		if def, ok := gs1AIs[ai[:n]]; ok && def.digits == len(ai) {
+			return def, true
+		}
+	}
+	return gs1AI{}, false
+}
+
+// Validate checks that the AI is known and the value has its length,
+// character set, check digit and date format.
+func (e GS1Element) Validate() error {
+	def, ok := lookupGS1(e.AI)
+	if !ok || strings.Trim(e.AI, "0123456789") != "" {
+		return &GS1Error{AI: e.AI, Message: "unknown application identifier"}
+	}
+
+	// Fixed-length parts come first, so each takes up to its maximum
+	value := e.Value
+	for _, part := range def.parts {
+		n := min(len(value), part.max)
+		v := value[:n]
+		value = value[n:]
+
+		switch {
+		case len(v) < part.min:
+			return &GS1Error{AI: e.AI, Message: fmt.Sprintf("value %q too short", e.Value)}
+		case part.numeric && strings.Trim(v, "0123456789") != "":
+			return &GS1Error{AI: e.AI, Message: fmt.Sprintf("value %q must be digits", e.Value)}
+		case !part.numeric && strings.Trim(v, gs1Charset82) != "":
+			return &GS1Error{AI: e.AI, Message: fmt.Sprintf("value %q has characters outside the GS1 character set", e.Value)}
+		case part.check && gs1CheckDigit(v[:len(v)-1]) != v[len(v)-1]:
+			return &GS1Error{AI: e.AI, Message: fmt.Sprintf("check digit of %q is %c, expected %c", v, v[len(v)-1], gs1CheckDigit(v[:len(v)-1]))}
+		case part.date && !gs1ValidDate(v):
+			return &GS1Error{AI: e.AI, Message: fmt.Sprintf("%q is not a YYMMDD date", v)}
+		}
+	}
+	if value != "" {
+		return &GS1Error{AI: e.AI, Message: fmt.Sprintf("value %q too long", e.Value)}
+	}
+	return nil
+}
+
+// gs1CheckDigit returns the GS1 mod 10 check digit of digits: weights 3
+// and 1 alternate from the rightmost digit.
+func gs1CheckDigit(digits string) byte {
+	sum := 0
+	for i := range len(digits) {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// gs1ValidDate reports whether a YYMMDD value names a day of the year
+// 20YY. Day 00 stands for the last day of the month.
+func gs1ValidDate(v string) bool {
+	year := 2000 + int(v[0]-'0')*10 + int(v[1]-'0')
+	month := int(v[2]-'0')*10 + int(v[3]-'0')
+	day := int(v[4]-'0')*10 + int(v[5]-'0')
+	if month < 1 || month > 12 {
+		return false
+	}
+	// Day 0 of the next month is the last day of this one
+	days := time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	return day <= days
+}
+
+// ParseGS1 parses and validates GS1 data in human readable form, with
+// each AI in parentheses: (01)09506000134352(17)201225(10)ABC123. A value
+// may contain parentheses unless they look like an AI.
+func ParseGS1(s string) ([]GS1Element, error) {
+	if !strings.HasPrefix(s, "(") {
+		return nil, &GS1Error{Message: "data must start with an AI in parentheses"}
+	}
+
+	var elements []GS1Element
+	for s != "" {
+		if !gs1AIPrefix(s) {
+			return nil, &GS1Error{Message: fmt.Sprintf("bad application identifier at %q", s)}
+		}
+		end := strings.IndexByte(s, ')')
+		ai := s[1:end]
+		s = s[end+1:]
+
+		next := len(s)
+		for i := 1; i < len(s); i++ {
+			if s[i] == '(' && gs1AIPrefix(s[i:]) {
+				next = i
+				break
+			}
+		}
+		e := GS1Element{AI: ai, Value: s[:next]}
+		if err := e.Validate(); err != nil {
+			return nil, err
+		}
+		elements = append(elements, e)
+		s = s[next:]
+	}
+	return elements, nil
+}
+
+// gs1AIPrefix reports whether s starts with 2-4 digits in parentheses.
+func gs1AIPrefix(s string) bool {
+	end := strings.IndexByte(s, ')')
+	return len(s) > 0 && s[0] == '(' && end >= 3 && end <= 5 && strings.Trim(s[1:end], "0123456789") == ""
+}
+
+// GS1ElementString validates elements and joins them into the element
+// string QR Codes carry in FNC1 mode: AIs and values with a GS (0x1D)
+// after each value whose length is not predefined, except the last.
+func GS1ElementString(elements []GS1Element) (string, error) {
+	if len(elements) == 0 {
+		return "", &GS1Error{Message: "no elements"}
+	}
+	var b strings.Builder
+	for i, e := range elements {
+		if err := e.Validate(); err != nil {
+			return "", err
+		}
+		b.WriteString(e.AI)
+		b.WriteString(e.Value)
+		if i < len(elements)-1 && !gs1Predefined[e.AI[:2]] {
+			b.WriteByte('\x1d')
+		}
+	}
+	return b.String(), nil
+}
+
+// gs1Data converts GS1 data in human readable form to the element string
+// to encode.
+func gs1Data(data string) (string, error) {
+	elements, err := ParseGS1(data)
+	if err != nil {
+		return "", err
+	}
+	return GS1ElementString(elements)
+}
+
+// gs1PrimaryKeys maps the AIs that identify an item in a GS1 Digital
+// Link URI to their key qualifiers, in path order.
+var gs1PrimaryKeys = map[string][]string{
+	"00":   nil,
+	"01":   {"22", "10", "21"},
+	"253":  nil,
+	"255":  nil,
+	"401":  nil,
+	"402":  nil,
+	"414":  {"254"},
+	"8003": nil,
+	"8004": nil,
+	"8006": {"22", "10", "21"},
+	"8010": {"8011"},
+	"8017": {"8019"},
+	"8018": {"8019"},
+}
+
+// GS1DigitalLink validates elements and builds a GS1 Digital Link URI on
+// base, https://id.gs1.org if empty. The one primary key, such as the
+// GTIN (01), and its qualifiers form the path; other elements become
+// query parameters:
+//
+//	https://id.gs1.org/01/09506000134352/10/ABC123?17=201225
+//
+// The URI is plain data: encode it without Config.GS1.
+func GS1DigitalLink(base string, elements []GS1Element) (string, error) {
+	if base == "" {
+		base = "https://id.gs1.org"
+	}
+	byAI := make(map[string]string, len(elements))
+	key := ""
+	for _, e := range elements {
+		if err := e.Validate(); err != nil {
+			return "", err
+		}
+		if _, dup := byAI[e.AI]; dup {
+			return "", &GS1Error{AI: e.AI, Message: "repeated application identifier"}
+		}
+		byAI[e.AI] = e.Value
+		if _, ok := gs1PrimaryKeys[e.AI]; ok {
+			if key != "" {
+				return "", &GS1Error{AI: e.AI, Message: fmt.Sprintf("second primary key after (%s)", key)}
+			}
+			key = e.AI
+		}
+	}
+	if key == "" {
+		return "", &GS1Error{Message: "no primary key such as a GTIN (01)"}
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimSuffix(base, "/"))
+	inPath := map[string]bool{}
+	for _, ai := range append([]string{key}, gs1PrimaryKeys[key]...) {
+		if value, ok := byAI[ai]; ok {
+			b.WriteString("/" + ai + "/" + url.PathEscape(value))
+			inPath[ai] = true
+		}
+	}
+
+	sep := "?"
+	for _, e := range elements {
+		if inPath[e.AI] {
+			continue
+		}
+		b.WriteString(sep + e.AI + "=" + url.QueryEscape(e.Value))
+		sep = "&"
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,140 @@
+package qrgode
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGS1(t *testing.T) {
+	got, err := ParseGS1("(01)09506000134352(17)201225(10)ABC123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []GS1Element{{"01", "09506000134352"}, {"17", "201225"}, {"10", "ABC123"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// Parentheses that do not enclose an AI belong to the value
+	got, err = ParseGS1("(10)A(B)(21)1")
+	if err != nil || got[0].Value != "A(B)" {
+		t.Errorf("expected value A(B), got %v, %v", got, err)
+	}
+}
+
+func TestParseGS1Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"no AI", "0109506000134352", "must start with an AI"},
+		{"bad AI", "(A1)123", "bad application identifier"},
+		{"unknown AI", "(05)123", "GS1 (05): unknown application identifier"},
+		{"check digit", "(01)09506000134353", "check digit of \"09506000134353\" is 3, expected 2"},
+		{"too short", "(01)0950600013435", "too short"},
+		{"too long", "(10)" + strings.Repeat("A", 21), "too long"},
+		{"not digits", "(30)12A", "must be digits"},
+		{"character set", "(10)AB#", "outside the GS1 character set"},
+		{"date", "(17)201325", "not a YYMMDD date"},
+		{"31 February", "(17)250231", "not a YYMMDD date"},
+		{"31 April", "(17)250431", "not a YYMMDD date"},
+		{"29 February, not a leap year", "(17)250229", "not a YYMMDD date"},
+		{"decimal AI", "(310)123456", "GS1 (310): unknown application identifier"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseGS1(tt.data)
+			var gs1Err *GS1Error
+			if !errors.As(err, &gs1Err) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected GS1Error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestParseGS1Dates(t *testing.T) {
+	for _, date := range []string{"240229", "250200", "250430", "251231", "000229"} {
+		if _, err := ParseGS1("(17)" + date); err != nil {
+			t.Errorf("%s: unexpected error: %v", date, err)
+		}
+	}
+}
+
+func TestGS1ElementString(t *testing.T) {
+	tests := []struct {
+		name     string
+		elements []GS1Element
+		want     string
+	}{
+		{"predefined lengths", []GS1Element{{"01", "09506000134352"}, {"17", "201225"}, {"10", "ABC123"}}, "010950600013435217201225" + "10ABC123"},
+		{"variable length first", []GS1Element{{"10", "ABC123"}, {"21", "XYZ"}}, "10ABC123\x1d21XYZ"},
+		{"fixed but not predefined", []GS1Element{{"422", "250"}, {"3103", "000125"}}, "422250\x1d3103000125"},
+		{"optional part", []GS1Element{{"8008", "20122512"}}, "800820122512"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GS1ElementString(tt.elements)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := GS1ElementString(nil); err == nil {
+		t.Error("expected error for no elements")
+	}
+}
+
+func TestGS1DigitalLink(t *testing.T) {
+	elements := []GS1Element{{"17", "201225"}, {"21", "S/N1"}, {"01", "09506000134352"}, {"10", "ABC123"}}
+	got, err := GS1DigitalLink("", elements)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "https://id.gs1.org/01/09506000134352/10/ABC123/21/S%2FN1?17=201225"
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+
+	got, err = GS1DigitalLink("https://example.com/", elements[2:3])
+	if err != nil || got != "https://example.com/01/09506000134352" {
+		t.Errorf("unexpected link %s, %v", got, err)
+	}
+
+	if _, err := GS1DigitalLink("", elements[:2]); err == nil {
+		t.Error("expected error without a primary key")
+	}
+	if _, err := GS1DigitalLink("", append(elements, GS1Element{"00", "106141412345678908"})); err == nil {
+		t.Error("expected error for two primary keys")
+	}
+}
+
+func TestGS1Encoding(t *testing.T) {
+	data := "(01)09506000134352(17)201225(10)ABC123(21)x%1"
+	symbol, err := New(data).GS1().Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if symbol.Segments[0].Mode != ModeFNC1First {
+		t.Errorf("expected an FNC1 header first, got %+v", symbol.Segments)
+	}
+	if err := New(data).GS1().Verify(); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	if _, err := New("(01)09506000134353").GS1().SVG(); err == nil {
+		t.Error("expected error for a bad check digit")
+	}
+	if _, err := New(data).GS1().Symbol(SymbolMicro).SVG(); err == nil {
+		t.Error("expected error for GS1 in Micro QR")
+	}
+	if _, err := GenerateStructuredAppend(data, &Config{Size: 256, GS1: true, Mask: -1}); err == nil {
+		t.Error("expected error for GS1 with Structured Append")
+	}
+}
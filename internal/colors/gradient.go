@@ -2,6 +2,7 @@ package colors
 
 import (
 	"fmt"
+	"image/color"
 	"math"
 	"strings"
 )
@@ -24,15 +25,11 @@ func (g *LinearGradient) ColorAt(x, y float64) string {
 	if len(g.Stops) == 0 {
 		return "#000000"
 	}
-	if len(g.Stops) == 1 {
-		return g.Stops[0]
-	}
-	// Simple linear interpolation along gradient direction
+	// Project onto the same gradient vector that SVGDefs emits, which runs
+	// through the center of the bounding box along the angle.
 	rad := g.Angle * math.Pi / 180
-	pos := x*math.Cos(rad) + y*math.Sin(rad)
-	pos = math.Max(0, math.Min(1, pos))
-	idx := int(pos * float64(len(g.Stops)-1))
-	return g.Stops[idx]
+	pos := 0.5 + (x-0.5)*math.Cos(rad) + (y-0.5)*math.Sin(rad)
+	return interpolateStops(g.Stops, pos)
 }
 
 func (g *LinearGradient) Type() string {
@@ -86,10 +83,8 @@ func (g *RadialGradient) ColorAt(x, y float64) string {
 	}
 	dx := x - g.CenterX
 	dy := y - g.CenterY
-	dist := math.Sqrt(dx*dx+dy*dy) / 0.707 // Normalize to roughly 0-1
-	dist = math.Max(0, math.Min(1, dist))
-	idx := int(dist * float64(len(g.Stops)-1))
-	return g.Stops[idx]
+	dist := math.Sqrt(dx*dx+dy*dy) / 0.7 // Matches r="70%" in SVGDefs
+	return interpolateStops(g.Stops, dist)
 }
 
 func (g *RadialGradient) Type() string {
@@ -113,3 +108,34 @@ func (g *RadialGradient) SVGDefs(id string) string {
 func (g *RadialGradient) SVGFill(id string) string {
 	return fmt.Sprintf("url(#%s)", id)
 }
+
+// interpolateStops returns the color at position t (0.0-1.0) along evenly
+// spaced color stops, blending linearly between neighbours like SVG does.
+func interpolateStops(stops []string, t float64) string {
+	if len(stops) == 1 {
+		return stops[0]
+	}
+	t = math.Max(0, math.Min(1, t))
+	pos := t * float64(len(stops)-1)
+	idx := int(pos)
+	if idx >= len(stops)-1 {
+		return stops[len(stops)-1]
+	}
+
+	from, err1 := ToRGBA(stops[idx])
+	to, err2 := ToRGBA(stops[idx+1])
+	if err1 != nil || err2 != nil {
+		return stops[idx]
+	}
+
+	f := pos - float64(idx)
+	lerp := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*f))
+	}
+	return ToHex(color.RGBA{
+		R: lerp(from.R, to.R),
+		G: lerp(from.G, to.G),
+		B: lerp(from.B, to.B),
+		A: lerp(from.A, to.A),
+	})
+}
@@ -0,0 +1,117 @@
+package colors
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg" // Register JPEG decoder
+	_ "image/png"  // Register PNG decoder
+	"math"
+	"os"
+)
+
+// Image samples module colors from a picture, so the QR code carries the
+// picture's palette. The picture is scaled to cover the QR code and
+// center-cropped, keeping its aspect ratio.
+type Image struct {
+	Source image.Image
+	Darken float64 // Fraction to darken sampled colors (0.0-1.0)
+}
+
+// NewImage creates an image-sampled color from a decoded image.
+func NewImage(img image.Image, darken float64) *Image {
+	return &Image{Source: img, Darken: math.Max(0, math.Min(1, darken))}
+}
+
+// LoadImage creates an image-sampled color from a PNG or JPEG file.
+func LoadImage(path string, darken float64) (*Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image file %s: %w", path, err)
+	}
+	return NewImage(img, darken), nil
+}
+
+// ColorAt returns the bilinearly sampled color at (x, y), darkened by
+// Darken. Transparent areas of the picture yield black.
+func (c *Image) ColorAt(x, y float64) string {
+	b := c.Source.Bounds()
+	if b.Empty() {
+		return "#000000"
+	}
+
+	// Cover: the shorter side spans the QR code, the longer one is cropped
+	w, h := float64(b.Dx()), float64(b.Dy())
+	side := math.Min(w, h)
+	u := (w-side)/2 + clampUnit(x)*side - 0.5
+	v := (h-side)/2 + clampUnit(y)*side - 0.5
+
+	x0, y0 := math.Floor(u), math.Floor(v)
+	fx, fy := u-x0, v-y0
+
+	var sum [4]float64
+	for _, s := range [4]struct {
+		dx, dy int
+		weight float64
+	}{
+		{0, 0, (1 - fx) * (1 - fy)},
+		{1, 0, fx * (1 - fy)},
+		{0, 1, (1 - fx) * fy},
+		{1, 1, fx * fy},
+	} {
+		px := b.Min.X + min(max(int(x0)+s.dx, 0), b.Dx()-1)
+		py := b.Min.Y + min(max(int(y0)+s.dy, 0), b.Dy()-1)
+		r, g, bl, a := c.Source.At(px, py).RGBA()
+		sum[0] += float64(r) * s.weight
+		sum[1] += float64(g) * s.weight
+		sum[2] += float64(bl) * s.weight
+		sum[3] += float64(a) * s.weight
+	}
+
+	// Premultiplied over black, then darkened
+	k := (1 - c.Darken) / 257
+	return ToHex(color.RGBA{
+		R: uint8(math.Round(sum[0] * k)),
+		G: uint8(math.Round(sum[1] * k)),
+		B: uint8(math.Round(sum[2] * k)),
+		A: 255,
+	})
+}
+
+func (c *Image) Type() string {
+	return "image"
+}
+
+// SVGDefs returns nothing: SVG has no paint server that samples a picture
+// per module, so renderers fill each module with ColorAt instead.
+func (c *Image) SVGDefs(id string) string {
+	return ""
+}
+
+// SVGFill returns the color at the center, for use where a single fill
+// is unavoidable.
+func (c *Image) SVGFill(id string) string {
+	return c.ColorAt(0.5, 0.5)
+}
+
+// PerModule marks image colors as sampled per module.
+func (c *Image) PerModule() bool {
+	return true
+}
+
+// IsPerModule reports whether c must be evaluated per module with
+// ColorAt rather than drawn as one fill.
+func IsPerModule(c Color) bool {
+	p, ok := c.(interface{ PerModule() bool })
+	return ok && p.PerModule()
+}
+
+func clampUnit(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -1,6 +1,12 @@
 package colors
 
-import "fmt"
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
 
 // Solid represents a single hex color.
 type Solid struct {
@@ -41,3 +47,69 @@ func ParseHex(hex string) (string, error) {
 		return "", fmt.Errorf("invalid hex color length: %s", hex)
 	}
 }
+
+// ToRGBA converts a color string to a non-premultiplied RGBA value.
+// Accepts: #RGB, #RRGGBB, #RRGGBBAA, rgb(r, g, b), rgba(r, g, b, a), transparent
+func ToRGBA(s string) (color.RGBA, error) {
+	s = strings.TrimSpace(s)
+	lower := strings.ToLower(s)
+
+	switch {
+	case lower == "transparent" || lower == "none":
+		return color.RGBA{}, nil
+	case strings.HasPrefix(lower, "rgb"):
+		return parseFunctional(lower)
+	}
+
+	hex, err := ParseHex(s)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	hex = hex[1:]
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid hex color: %s", s)
+	}
+	return color.RGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// parseFunctional parses rgb(r, g, b) and rgba(r, g, b, a) notation.
+func parseFunctional(s string) (color.RGBA, error) {
+	open := strings.IndexByte(s, '(')
+	if open < 0 || !strings.HasSuffix(s, ")") {
+		return color.RGBA{}, fmt.Errorf("invalid color: %s", s)
+	}
+	parts := strings.Split(s[open+1:len(s)-1], ",")
+	if len(parts) != 3 && len(parts) != 4 {
+		return color.RGBA{}, fmt.Errorf("invalid color: %s", s)
+	}
+
+	var ch [4]uint8
+	ch[3] = 255
+	for i, p := range parts {
+		n, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return color.RGBA{}, fmt.Errorf("invalid color: %s", s)
+		}
+		if i == 3 {
+			n *= 255 // alpha is given as 0.0-1.0
+		}
+		ch[i] = uint8(math.Max(0, math.Min(255, math.Round(n))))
+	}
+	return color.RGBA{R: ch[0], G: ch[1], B: ch[2], A: ch[3]}, nil
+}
+
+// ToHex formats an RGBA value as #RRGGBB, or #RRGGBBAA if not opaque.
+func ToHex(c color.RGBA) string {
+	if c.A == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
@@ -49,50 +49,108 @@ func (bs *BitStream) Bytes() []byte {
 	return bytes
 }
 
-func EncodeData(data string, mode Mode, version Version) *BitStream {
+// EncodeData encodes each segment with its mode indicator and character
+// count, followed by the terminator and padding to a byte boundary.
+func EncodeData(segs []Segment, version Version) *BitStream {
 	bs := NewBitStream()
 
-	// 1. Mode indicator (4 bits)
-	bs.AppendBits(uint(mode.ModeIndicator()), 4)
+	// 1-3. Mode indicator, character count indicator and data
+	for _, seg := range segs {
+		encodeSegment(bs, seg, qrHeader(version))
+	}
 
-	// 2. Character count indicator
-	charCountBits := mode.CharCountBits(version)
-	bs.AppendBits(uint(len(data)), charCountBits)
+	// 4. Terminator (4 zero bits)
+	bs.AppendBits(0, 4)
 
-	// 3. Encode the actual data
-	switch mode {
+	// 5. Pad to byte boundary
+	if bs.Len()%8 != 0 {
+		bs.AppendBits(0, 8-bs.Len()%8)
+	}
+
+	return bs
+}
+
+// encodeSegment appends the mode indicator, character count indicator
+// and encoded data of one segment.
+func encodeSegment(bs *BitStream, seg Segment, h segmentHeader) {
+	bs.AppendBits(h.indicator(seg.Mode), h.modeBits)
+	switch seg.Mode {
+	case ModeECI:
+		encodeECI(bs, seg.ECI)
+		return
+	case ModeStructuredAppend:
+		bs.AppendBits(uint(seg.Append.Index), 4)
+		bs.AppendBits(uint(seg.Append.Total-1), 4)
+		bs.AppendBits(uint(seg.Append.Parity), 8)
+		return
+	case ModeFNC1First:
+		return
+	case ModeFNC1Second:
+		bs.AppendBits(uint(seg.Application), 8)
+		return
+	}
+	bs.AppendBits(uint(seg.Mode.CharCount(seg.Data)), h.countBits(seg.Mode))
+
+	switch seg.Mode {
 	case ModeNumeric:
-		encodeNumeric(bs, data)
+		encodeNumeric(bs, seg.Data)
 	case ModeAlphanumeric:
-		encodeAlphanumeric(bs, data)
+		encodeAlphanumeric(bs, seg.Data)
 	case ModeByte:
-		encodeByte(bs, data)
-	default:
-		// Kanji encoding not implemented
+		encodeByte(bs, seg.Data)
+	case ModeKanji:
+		encodeKanji(bs, seg.Data)
 	}
+}
 
-	// 4. Terminator (up to 4 zero bits)
-	bs.AppendBits(0, 4)
+// EncodeDataWithPadding encodes segments and pads to the required
+// capacity. The terminator is shortened when the data leaves less than
+// 4 bits.
+func EncodeDataWithPadding(segs []Segment, version Version, capacityBytes int) *BitStream {
+	bs := NewBitStream()
+	for _, seg := range segs {
+		encodeSegment(bs, seg, qrHeader(version))
+	}
 
-	// 5. Pad to byte boundary
+	// Terminator (up to 4 zero bits), then pad to byte boundary
+	bs.AppendBits(0, min(4, capacityBytes*8-bs.Len()))
 	if bs.Len()%8 != 0 {
 		bs.AppendBits(0, 8-bs.Len()%8)
 	}
 
+	// Add padding bytes to fill capacity (alternating 0xEC and 0x11)
+	padBytes := []byte{0xEC, 0x11}
+	padIndex := 0
+	for bs.Len() < capacityBytes*8 {
+		bs.AppendByte(padBytes[padIndex])
+		padIndex = (padIndex + 1) % 2
+	}
+
 	return bs
 }
 
-// EncodeDataWithPadding encodes data and pads to the required capacity.
-func EncodeDataWithPadding(data string, mode Mode, version Version, capacityBytes int) *BitStream {
-	bs := EncodeData(data, mode, version)
+// encodePadded encodes segments with the given headers, then the
+// terminator and padding up to capacityBits. A capacity that is not a
+// whole number of bytes ends in a short codeword of zeros.
+func encodePadded(segs []Segment, h segmentHeader, terminatorBits, capacityBits int) *BitStream {
+	bs := NewBitStream()
+	for _, seg := range segs {
+		encodeSegment(bs, seg, h)
+	}
+
+	// Terminator, then pad to a codeword boundary
+	bs.AppendBits(0, min(terminatorBits, capacityBits-bs.Len()))
+	if bs.Len()%8 != 0 {
+		bs.AppendBits(0, min(8-bs.Len()%8, capacityBits-bs.Len()))
+	}
 
-	// Add padding bytes to fill capacity (alternating 0xEC and 0x11)
 	padBytes := []byte{0xEC, 0x11}
 	padIndex := 0
-	for bs.Len() < capacityBytes*8 {
+	for bs.Len()+8 <= capacityBits {
 		bs.AppendByte(padBytes[padIndex])
 		padIndex = (padIndex + 1) % 2
 	}
+	bs.AppendBits(0, capacityBits-bs.Len())
 
 	return bs
 }
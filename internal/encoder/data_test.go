@@ -118,3 +118,41 @@ func TestBitStream_Len(t *testing.T) {
 		t.Errorf("after 4+8 bits: got %d, want 12", bs.Len())
 	}
 }
+
+func TestEncodeKanji(t *testing.T) {
+	// Worked examples from ISO/IEC 18004: 0x935F -> 0xD9F, 0xE4AA -> 0x1AAA
+	bs := NewBitStream()
+	encodeKanji(bs, "点茗")
+
+	want := NewBitStream()
+	want.AppendBits(0xD9F, 13)
+	want.AppendBits(0x1AAA, 13)
+	if bs.Len() != want.Len() {
+		t.Fatalf("got %d bits, want %d", bs.Len(), want.Len())
+	}
+	for i := range want.bits {
+		if bs.bits[i] != want.bits[i] {
+			t.Fatalf("bit %d differs", i)
+		}
+	}
+}
+
+func TestEncodeDataWithPaddingFull(t *testing.T) {
+	// 17 bytes fill version 1-L except for the 4 bit terminator, and
+	// 18 would overflow it: the stream must still match the capacity
+	capacity := GetECCInfo(1, LevelL).DataCapacity()
+	bs := EncodeDataWithPadding([]Segment{{Mode: ModeByte, Data: "abcdefghijklmnopq"}}, 1, capacity)
+	if got := len(bs.Bytes()); got != capacity {
+		t.Errorf("got %d bytes, want %d", got, capacity)
+	}
+}
+
+func TestEncodeDataWithPaddingShortTerminator(t *testing.T) {
+	// 25 alphanumeric characters take 4+9+138 bits, one less than
+	// version 1-L holds: the terminator shrinks to that one bit
+	capacity := GetECCInfo(1, LevelL).DataCapacity()
+	bs := EncodeDataWithPadding([]Segment{{Mode: ModeAlphanumeric, Data: "ABCDEFGHIJKLMNOPQRSTUVWXY"}}, 1, capacity)
+	if got := len(bs.Bytes()); got != capacity {
+		t.Errorf("got %d bytes, want %d", got, capacity)
+	}
+}
@@ -0,0 +1,263 @@
+package encoder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Charset is a character set for byte mode data, announced to readers
+// with an ECI (Extended Channel Interpretation) header.
+type Charset struct {
+	Name string
+	ECI  int // ECI assignment number
+
+	// auto emits the ECI header only when byte data is not plain ASCII
+	auto bool
+
+	// appendRune appends the encoding of r, whose UTF-8 form is src
+	appendRune func(dst []byte, r rune, src string) ([]byte, bool)
+
+	// decode converts bytes in the charset to UTF-8; nil for UTF-8
+	decode func(b []byte) string
+}
+
+// Charsets known by name. ISO/IEC 8859 parts are named "iso-8859-N".
+var (
+	CharsetUTF8     = &Charset{Name: "utf-8", ECI: 26, appendRune: appendUTF8}
+	CharsetASCII    = &Charset{Name: "us-ascii", ECI: 27, appendRune: appendASCII}
+	CharsetShiftJIS = &Charset{Name: "shift_jis", ECI: 20, appendRune: appendShiftJIS, decode: decodeShiftJIS}
+
+	// CharsetAuto encodes UTF-8 and emits its ECI header only when
+	// byte mode data contains non-ASCII bytes.
+	CharsetAuto = &Charset{Name: "auto", ECI: 26, auto: true, appendRune: appendUTF8}
+)
+
+var charsetAliases = map[string]string{
+	"utf8":      "utf-8",
+	"ascii":     "us-ascii",
+	"latin1":    "iso-8859-1",
+	"latin-1":   "iso-8859-1",
+	"sjis":      "shift_jis",
+	"shift-jis": "shift_jis",
+}
+
+// LookupCharset returns the charset with the given name, ignoring case.
+func LookupCharset(name string) (*Charset, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := charsetAliases[name]; ok {
+		name = alias
+	}
+
+	switch name {
+	case "auto":
+		return CharsetAuto, true
+	case "utf-8":
+		return CharsetUTF8, true
+	case "us-ascii":
+		return CharsetASCII, true
+	case "shift_jis":
+		return CharsetShiftJIS, true
+	}
+
+	part, ok := strings.CutPrefix(name, "iso-8859-")
+	if !ok {
+		return nil, false
+	}
+	n, err := strconv.Atoi(part)
+	if err != nil {
+		return nil, false
+	}
+	// Part N is ECI N+2 (ECI 14 is reserved for the unpublished part 12)
+	if n == 1 {
+		return &Charset{Name: name, ECI: 3, appendRune: appendLatin1, decode: decodeLatin1}, true
+	}
+	table, ok := iso8859Runes[n]
+	if !ok {
+		return nil, false
+	}
+	return &Charset{Name: name, ECI: n + 2, appendRune: iso8859Encoder(table), decode: iso8859Decoder(table)}, true
+}
+
+// LookupECI returns the charset with the given ECI assignment number.
+// Assignment 1 is the old number of ISO-8859-1.
+func LookupECI(assignment int) (*Charset, bool) {
+	switch assignment {
+	case 1, 3:
+		return LookupCharset("iso-8859-1")
+	case 20:
+		return CharsetShiftJIS, true
+	case 26:
+		return CharsetUTF8, true
+	case 27:
+		return CharsetASCII, true
+	case 14:
+		return nil, false
+	}
+	if assignment >= 4 && assignment <= 18 {
+		return LookupCharset("iso-8859-" + strconv.Itoa(assignment-2))
+	}
+	return nil, false
+}
+
+// CharsetNames lists the names accepted by LookupCharset.
+func CharsetNames() []string {
+	names := []string{"auto", "utf-8", "us-ascii", "shift_jis", "iso-8859-1"}
+	for _, n := range []int{2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 13, 14, 15, 16} {
+		names = append(names, "iso-8859-"+strconv.Itoa(n))
+	}
+	return names
+}
+
+// Encode converts UTF-8 text to the charset, or reports the first
+// character it cannot represent.
+func (cs *Charset) Encode(s string) ([]byte, error) {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		var ok bool
+		if out, ok = cs.appendRune(out, r, s[i:i+size]); !ok {
+			return nil, fmt.Errorf("character %q cannot be encoded in %s", r, cs.Name)
+		}
+		i += size
+	}
+	return out, nil
+}
+
+// Decode converts bytes in the charset to UTF-8 text.
+func (cs *Charset) Decode(b []byte) string {
+	if cs.decode == nil {
+		return string(b)
+	}
+	return cs.decode(b)
+}
+
+// eciBits returns the length of an ECI designator: 1-3 bytes.
+func eciBits(assignment int) int {
+	switch {
+	case assignment < 1<<7:
+		return 8
+	case assignment < 1<<14:
+		return 16
+	}
+	return 24
+}
+
+// encodeECI appends the designator bytes for an ECI assignment number.
+func encodeECI(bs *BitStream, assignment int) {
+	switch {
+	case assignment < 1<<7:
+		bs.AppendBits(uint(assignment), 8)
+	case assignment < 1<<14:
+		bs.AppendBits(0b10, 2)
+		bs.AppendBits(uint(assignment), 14)
+	default:
+		bs.AppendBits(0b110, 3)
+		bs.AppendBits(uint(assignment), 21)
+	}
+}
+
+func appendUTF8(dst []byte, r rune, src string) ([]byte, bool) {
+	return append(dst, src...), true
+}
+
+func appendASCII(dst []byte, r rune, src string) ([]byte, bool) {
+	if r >= utf8.RuneSelf {
+		return dst, false
+	}
+	return append(dst, byte(r)), true
+}
+
+func appendLatin1(dst []byte, r rune, src string) ([]byte, bool) {
+	if r > 0xFF || (r == utf8.RuneError && len(src) == 1) {
+		return dst, false
+	}
+	return append(dst, byte(r)), true
+}
+
+func decodeLatin1(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// iso8859Decoder returns a decoder for an ISO/IEC 8859 part given its
+// upper half. Unassigned bytes become U+FFFD.
+func iso8859Decoder(table *[96]uint16) func([]byte) string {
+	return func(b []byte) string {
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			switch {
+			case c < 0xA0:
+				runes[i] = rune(c)
+			case table[c-0xA0] != 0:
+				runes[i] = rune(table[c-0xA0])
+			default:
+				runes[i] = utf8.RuneError
+			}
+		}
+		return string(runes)
+	}
+}
+
+// iso8859Encoder returns an encoder for an ISO/IEC 8859 part given its
+// upper half. Bytes below 0xA0 match Unicode.
+func iso8859Encoder(table *[96]uint16) func([]byte, rune, string) ([]byte, bool) {
+	reverse := make(map[rune]byte, len(table))
+	for i, u := range table {
+		if u != 0 {
+			reverse[rune(u)] = byte(0xA0 + i)
+		}
+	}
+	return func(dst []byte, r rune, src string) ([]byte, bool) {
+		if r < 0xA0 {
+			return append(dst, byte(r)), true
+		}
+		b, ok := reverse[r]
+		if !ok {
+			return dst, false
+		}
+		return append(dst, b), true
+	}
+}
+
+func appendShiftJIS(dst []byte, r rune, src string) ([]byte, bool) {
+	switch {
+	case r < utf8.RuneSelf:
+		return append(dst, byte(r)), true
+	case r >= 0xFF61 && r <= 0xFF9F:
+		// Half-width katakana are single bytes
+		return append(dst, byte(r-0xFF61+0xA1)), true
+	}
+	code, ok := ShiftJIS(r)
+	if !ok {
+		return dst, false
+	}
+	return append(dst, byte(code>>8), byte(code)), true
+}
+
+func decodeShiftJIS(b []byte) string {
+	runes := make([]rune, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case c < utf8.RuneSelf:
+			runes = append(runes, rune(c))
+		case c >= 0xA1 && c <= 0xDF:
+			runes = append(runes, rune(c)-0xA1+0xFF61)
+		case i+1 < len(b):
+			r, ok := ShiftJISRune(uint16(c)<<8 | uint16(b[i+1]))
+			if !ok {
+				r = utf8.RuneError
+			}
+			runes = append(runes, r)
+			i++
+		default:
+			runes = append(runes, utf8.RuneError)
+		}
+	}
+	return string(runes)
+}
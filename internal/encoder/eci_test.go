@@ -0,0 +1,131 @@
+package encoder
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLookupCharset(t *testing.T) {
+	tests := []struct {
+		name string
+		eci  int
+	}{
+		{"utf-8", 26},
+		{"UTF8", 26},
+		{"latin1", 3},
+		{"iso-8859-2", 4},
+		{"ISO-8859-15", 17},
+		{"shift_jis", 20},
+		{"us-ascii", 27},
+		{"auto", 26},
+	}
+	for _, tt := range tests {
+		cs, ok := LookupCharset(tt.name)
+		if !ok || cs.ECI != tt.eci {
+			t.Errorf("LookupCharset(%q) = %v, %v; want ECI %d", tt.name, cs, ok, tt.eci)
+		}
+	}
+
+	for _, name := range []string{"", "ebcdic", "iso-8859-12", "iso-8859-x"} {
+		if _, ok := LookupCharset(name); ok {
+			t.Errorf("expected %q to be unknown", name)
+		}
+	}
+	for _, name := range CharsetNames() {
+		cs, ok := LookupCharset(name)
+		if !ok {
+			t.Errorf("listed charset %q is unknown", name)
+			continue
+		}
+		if byECI, ok := LookupECI(cs.ECI); !ok || byECI.ECI != cs.ECI {
+			t.Errorf("LookupECI(%d) = %v, %v; want %s", cs.ECI, byECI, ok, name)
+		}
+	}
+}
+
+func TestCharsetEncode(t *testing.T) {
+	tests := []struct {
+		charset string
+		text    string
+		want    []byte
+	}{
+		{"iso-8859-1", "café", []byte{'c', 'a', 'f', 0xE9}},
+		{"iso-8859-15", "5€", []byte{'5', 0xA4}},
+		{"iso-8859-2", "Łódź", []byte{0xA3, 0xF3, 'd', 0xBC}},
+		{"shift_jis", "ｱ漢", []byte{0xB1, 0x8A, 0xBF}},
+		{"utf-8", "é", []byte{0xC3, 0xA9}},
+	}
+	for _, tt := range tests {
+		cs, _ := LookupCharset(tt.charset)
+		got, err := cs.Encode(tt.text)
+		if err != nil || !bytes.Equal(got, tt.want) {
+			t.Errorf("%s.Encode(%q) = %x, %v; want %x", tt.charset, tt.text, got, err, tt.want)
+		}
+		if back := cs.Decode(got); back != tt.text {
+			t.Errorf("%s.Decode(%x) = %q, want %q", tt.charset, got, back, tt.text)
+		}
+	}
+
+	if _, err := CharsetASCII.Encode("é"); err == nil {
+		t.Error("expected error encoding é in us-ascii")
+	}
+}
+
+func TestEncodeECI(t *testing.T) {
+	tests := []struct {
+		assignment int
+		want       []byte
+	}{
+		{26, []byte{0x1A}},
+		{1000, []byte{0x83, 0xE8}},
+		{100000, []byte{0xC1, 0x86, 0xA0}},
+	}
+	for _, tt := range tests {
+		bs := NewBitStream()
+		encodeECI(bs, tt.assignment)
+		if got := bs.Bytes(); !bytes.Equal(got, tt.want) {
+			t.Errorf("encodeECI(%d) = %x, want %x", tt.assignment, got, tt.want)
+		}
+		if eciBits(tt.assignment) != bs.Len() {
+			t.Errorf("eciBits(%d) = %d, want %d", tt.assignment, eciBits(tt.assignment), bs.Len())
+		}
+	}
+}
+
+func TestSplitSegmentsCharset(t *testing.T) {
+	// Auto mode only announces UTF-8 when byte data is not ASCII
+	segs, _ := SplitSegments("hello", 1, CharsetAuto)
+	if segs[0].Mode == ModeECI {
+		t.Error("expected no ECI header for ASCII data in auto mode")
+	}
+	segs, _ = SplitSegments("héllo", 1, CharsetAuto)
+	if segs[0].Mode != ModeECI || segs[0].ECI != 26 {
+		t.Errorf("expected UTF-8 ECI header, got %v", segs)
+	}
+	segs, _ = SplitSegments("hello", 1, CharsetUTF8)
+	if segs[0].Mode != ModeECI {
+		t.Error("expected an explicit charset to always write its header")
+	}
+
+	// Byte data is converted to the charset
+	latin1, _ := LookupCharset("iso-8859-1")
+	segs, _ = SplitSegments("café", 1, latin1)
+	if len(segs) != 2 || segs[1].Data != "caf\xe9" {
+		t.Errorf("expected ISO-8859-1 bytes after the header, got %q", segs)
+	}
+
+	if _, err := SplitSegments("한국어", 1, latin1); err == nil {
+		t.Error("expected error for characters missing from the charset")
+	}
+}
+
+func TestDetermineSegmentsECI(t *testing.T) {
+	// 17 UTF-8 bytes fill version 1-L; the ECI header pushes them to 2
+	data := "éabcdefghijklmno"
+	if _, v, _ := DetermineSegments(data, LevelL, nil); v != 1 {
+		t.Errorf("expected version 1 without ECI, got %d", v)
+	}
+	if _, v, _ := DetermineSegments(data, LevelL, CharsetAuto); v != 2 {
+		t.Errorf("expected version 2 with ECI, got %d", v)
+	}
+}
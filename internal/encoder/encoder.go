@@ -1,13 +1,40 @@
 package encoder
 
+import (
+	"errors"
+	"fmt"
+)
+
 // Encoder orchestrates the QR code encoding process.
 type Encoder struct {
 	data            string
 	errorCorrection ErrorCorrectionLevel
+	level           ErrorCorrectionLevel
+	boost           bool
+	gs1             bool
 	version         int
-	mode            Mode
+	mask            int
+	segments        []Segment
+	charset         *Charset
+	sequence        *StructuredAppend
+	symbol          Symbol
+	maxHeight       int
+	minVersion      int
+	fixedVersion    int
+	fixedMask       int
+	layout          Layout
+	usedBits        int
 }
 
+// Symbol selects the kind of symbol to encode.
+type Symbol int
+
+const (
+	SymbolQR    Symbol = iota // QR Code, versions 1-40
+	SymbolMicro               // Micro QR Code, versions M1-M4
+	SymbolRMQR                // Rectangular Micro QR Code, R7x43 to R17x139
+)
+
 // ErrorCorrectionLevel defines redundancy level.
 type ErrorCorrectionLevel int
 
@@ -23,26 +50,188 @@ func New(data string, ecl ErrorCorrectionLevel) *Encoder {
 	return &Encoder{
 		data:            data,
 		errorCorrection: ecl,
+		fixedMask:       -1,
+	}
+}
+
+// SetCharset sets the character set for byte mode data, announced with
+// an ECI header. The default, nil, writes UTF-8 without a header.
+func (e *Encoder) SetCharset(cs *Charset) {
+	e.charset = cs
+}
+
+// SetStructuredAppend marks the symbol as part of a Structured Append
+// sequence. The default, nil, encodes a standalone symbol.
+func (e *Encoder) SetStructuredAppend(sa *StructuredAppend) {
+	e.sequence = sa
+}
+
+// SetGS1 marks the data as GS1 element strings with an FNC1 first
+// position header. GS characters (0x1D) in the data separate elements.
+// The default, false, encodes plain data.
+func (e *Encoder) SetGS1(gs1 bool) {
+	e.gs1 = gs1
+}
+
+// SetSymbol sets the kind of symbol to encode. The default is SymbolQR.
+func (e *Encoder) SetSymbol(s Symbol) {
+	e.symbol = s
+}
+
+// SetMaxHeight limits rMQR symbols to at most the given number of
+// modules high. The default, 0, allows any height.
+func (e *Encoder) SetMaxHeight(modules int) {
+	e.maxHeight = modules
+}
+
+// SetMinVersion sets the smallest version to encode with, numbered as
+// returned by Version. The default, 0, allows any version.
+func (e *Encoder) SetMinVersion(version int) {
+	e.minVersion = version
+}
+
+// SetVersion fixes the version to encode with, numbered as returned by
+// Version. Encode returns ErrVersionTooSmall if the data does not fit.
+// The default, 0, picks the smallest version that fits.
+func (e *Encoder) SetVersion(version int) {
+	e.fixedVersion = version
+}
+
+// SetMask fixes the mask pattern: 0-7 for QR Code, 0-3 for Micro QR.
+// rMQR symbols have a single mask and ignore it. The default, -1,
+// selects the best mask.
+func (e *Encoder) SetMask(mask int) {
+	e.fixedMask = mask
+}
+
+// SetBoost raises the error correction level to the highest one that
+// still fits the version chosen for the configured level. The default,
+// false, keeps the configured level.
+func (e *Encoder) SetBoost(boost bool) {
+	e.boost = boost
+}
+
+// Version returns the version of the last encoded symbol: 1-40 for QR
+// Code, 1-4 for Micro QR (M1-M4) and 1-32 for rMQR.
+func (e *Encoder) Version() int {
+	return e.version
+}
+
+// Mask returns the mask pattern of the last encoded symbol: 0-7 for QR
+// Code and rMQR, which always uses pattern 4, and 0-3 for Micro QR.
+func (e *Encoder) Mask() int {
+	return e.mask
+}
+
+// ErrorCorrection returns the error correction level of the last
+// encoded symbol.
+func (e *Encoder) ErrorCorrection() ErrorCorrectionLevel {
+	return e.level
+}
+
+// boostLevel returns the highest level from the configured one up to
+// top whose capacity in bits still holds bits, or the configured level
+// without SetBoost. capacityBits returns -1 for levels the version does
+// not have.
+func (e *Encoder) boostLevel(bits int, top ErrorCorrectionLevel, capacityBits func(ErrorCorrectionLevel) int) ErrorCorrectionLevel {
+	ecl := e.errorCorrection
+	for l := ecl + 1; e.boost && l <= top; l++ {
+		c := capacityBits(l)
+		if c < 0 {
+			continue
+		}
+		if bits > c {
+			break
+		}
+		ecl = l
+	}
+	return ecl
+}
+
+// headers returns the header segments that precede the data segments.
+func (e *Encoder) headers() []Segment {
+	var headers []Segment
+	if e.sequence != nil {
+		headers = append(headers, Segment{Mode: ModeStructuredAppend, Append: *e.sequence})
+	}
+	if e.gs1 {
+		headers = append(headers, Segment{Mode: ModeFNC1First})
 	}
+	return headers
+}
+
+// versions returns the range of versions Encode may choose from, where
+// last is the largest version of the symbol.
+func (e *Encoder) versions(last int) (lo, hi int, err error) {
+	if e.fixedVersion > last || e.minVersion > last || e.fixedVersion < 0 || e.minVersion < 0 {
+		return 0, 0, fmt.Errorf("version out of range 1-%d", last)
+	}
+	if e.fixedVersion > 0 {
+		return e.fixedVersion, e.fixedVersion, nil
+	}
+	return max(e.minVersion, 1), last, nil
+}
+
+// tooLong reports whether err means the data does not fit the fixed
+// version.
+func (e *Encoder) tooLong(err error) bool {
+	return e.fixedVersion > 0 && errors.Is(err, ErrDataTooLong)
+}
+
+// Segments returns the segments of the last encoded symbol, headers
+// included.
+func (e *Encoder) Segments() []Segment {
+	return e.segments
+}
+
+// UsedBits returns the length of the encoded segments of the last
+// symbol, without terminator and padding.
+func (e *Encoder) UsedBits() int {
+	return e.usedBits
+}
+
+// Layout returns the block layout of the last encoded symbol.
+func (e *Encoder) Layout() Layout {
+	return e.layout
 }
 
 // Encode performs the full encoding process and returns the module matrix.
 func (e *Encoder) Encode() (*Matrix, error) {
-	// 1. Analyze data to determine best mode
-	mode := AnalyzeData(e.data)
-	e.mode = mode
+	switch e.symbol {
+	case SymbolMicro:
+		return e.encodeMicro()
+	case SymbolRMQR:
+		return e.encodeRMQR()
+	}
 
-	// 2. Determine minimum version that fits data + error correction
-	version, err := DetermineVersion(len(e.data), mode, e.errorCorrection)
+	// 1-2. Split data into mode segments and determine the minimum
+	// version that fits them + error correction
+	lo, hi, err := e.versions(40)
 	if err != nil {
 		return nil, err
 	}
+	if e.fixedMask > 7 {
+		return nil, fmt.Errorf("mask %d out of range 0-7", e.fixedMask)
+	}
+	segments, version, err := fitSegments(e.data, e.errorCorrection, e.charset, e.headers(), Version(lo), Version(hi))
+	if e.tooLong(err) {
+		return nil, fmt.Errorf("version %d: %w", e.fixedVersion, ErrVersionTooSmall)
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.segments = segments
 	e.version = int(version)
+	e.usedBits = segmentsBits(segments, qrHeader(version))
+	e.level = e.boostLevel(e.usedBits, LevelH, func(l ErrorCorrectionLevel) int {
+		return GetECCInfo(version, l).DataCapacity() * 8
+	})
+	e.layout = qrLayout(version, e.level)
 
 	// 3. Encode data to bit stream
-	eccInfo := GetECCInfo(version, e.errorCorrection)
+	eccInfo := GetECCInfo(version, e.level)
 	dataCapacity := eccInfo.DataCapacity()
-	bs := EncodeDataWithPadding(e.data, mode, version, dataCapacity)
+	bs := EncodeDataWithPadding(segments, version, dataCapacity)
 	dataBytes := bs.Bytes()
 
 	// 4. Generate error correction codewords
@@ -58,12 +247,16 @@ func (e *Encoder) Encode() (*Matrix, error) {
 	// 7. Place data modules
 	matrix.PlaceData(finalData)
 
-	// 8. Apply masking and select best mask
-	bestMask := SelectBestMask(matrix)
-	ApplyMask(matrix, bestMask)
+	// 8. Apply masking and select best mask, unless fixed
+	mask := MaskPattern(e.fixedMask)
+	if e.fixedMask < 0 {
+		mask = SelectBestMask(matrix)
+	}
+	ApplyMask(matrix, mask)
+	e.mask = int(mask)
 
 	// 9. Add format and version information
-	formatInfo := FormatInfo(e.errorCorrection, bestMask)
+	formatInfo := FormatInfo(e.level, mask)
 	PlaceFormatInfo(matrix, formatInfo)
 
 	if version >= 7 {
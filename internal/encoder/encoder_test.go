@@ -1,6 +1,11 @@
 package encoder
 
-import "testing"
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
 
 func TestEncode(t *testing.T) {
 	tests := []struct {
@@ -84,6 +89,49 @@ func TestEncodeMatrixStructure(t *testing.T) {
 	}
 }
 
+func TestAlignmentPatternPositions(t *testing.T) {
+	tests := []struct {
+		version Version
+		want    []int
+	}{
+		{1, nil},
+		{2, []int{6, 18}},
+		{7, []int{6, 22, 38}},
+		{15, []int{6, 26, 48, 70}},
+		{16, []int{6, 26, 50, 74}},
+		{21, []int{6, 28, 50, 72, 94}},
+		{32, []int{6, 34, 60, 86, 112, 138}},
+		{36, []int{6, 24, 50, 76, 102, 128, 154}},
+		{40, []int{6, 30, 58, 86, 114, 142, 170}},
+	}
+	for _, tt := range tests {
+		if got := alignmentPatternPositions(tt.version); !slices.Equal(got, tt.want) {
+			t.Errorf("alignmentPatternPositions(%d) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestPlaceFormatInfo(t *testing.T) {
+	m := NewMatrix(1)
+	m.PlaceFunctionPatterns(1)
+	info := FormatInfo(LevelM, Mask5)
+	PlaceFormatInfo(m, info)
+
+	// Both copies carry bit 0 next to a corner and bit 14 at the far end
+	bit := func(i int) bool { return info>>i&1 == 1 }
+	checks := []struct {
+		x, y, bit int
+	}{
+		{8, 0, 0}, {8, 7, 6}, {8, 8, 7}, {7, 8, 8}, {0, 8, 14},
+		{20, 8, 0}, {13, 8, 7}, {8, 14, 8}, {8, 20, 14},
+	}
+	for _, c := range checks {
+		if m.Get(c.x, c.y).Dark != bit(c.bit) {
+			t.Errorf("module (%d,%d) should hold format bit %d", c.x, c.y, c.bit)
+		}
+	}
+}
+
 func TestEncodeDifferentModes(t *testing.T) {
 	// Numeric mode
 	enc := New("1234567890", LevelL)
@@ -130,3 +178,119 @@ func TestEncodeTooLong(t *testing.T) {
 		t.Error("Expected error for data too long, got nil")
 	}
 }
+
+func TestEncodeKanjiVersion(t *testing.T) {
+	// 10 Kanji characters are 4+8+130 bits, the exact capacity limit of
+	// version 1-L; the same text in UTF-8 bytes would need version 2
+	matrix, err := New("日本語の漢字符号化試験", LevelL).Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if matrix.Size() != 25 {
+		t.Errorf("expected 11 characters to need version 2, got size %d", matrix.Size())
+	}
+
+	matrix, err = New("日本語の漢字符号化試", LevelL).Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if matrix.Size() != 21 {
+		t.Errorf("expected 10 characters to fit version 1, got size %d", matrix.Size())
+	}
+}
+
+func TestEncodeMinVersion(t *testing.T) {
+	enc := New("HELLO", LevelM)
+	enc.SetMinVersion(12)
+	matrix, err := enc.Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enc.Version() != 12 || matrix.Size() != Version(12).Size() {
+		t.Errorf("got version %d, size %d; want version 12", enc.Version(), matrix.Size())
+	}
+	if got := enc.Layout().ECC; got != GetECCInfo(12, LevelM) {
+		t.Errorf("layout ECC = %+v, want version 12-M", got)
+	}
+}
+
+func TestEncodeFixedVersionAndMask(t *testing.T) {
+	enc := New("HELLO", LevelM)
+	enc.SetVersion(3)
+	enc.SetMask(5)
+	if _, err := enc.Encode(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enc.Version() != 3 || enc.Mask() != 5 {
+		t.Errorf("got version %d, mask %d; want 3 and 5", enc.Version(), enc.Mask())
+	}
+
+	enc = New(strings.Repeat("A", 100), LevelM)
+	enc.SetVersion(2)
+	if _, err := enc.Encode(); !errors.Is(err, ErrVersionTooSmall) {
+		t.Errorf("expected ErrVersionTooSmall, got %v", err)
+	}
+
+	enc = New("HELLO", LevelM)
+	enc.SetMask(8)
+	if _, err := enc.Encode(); err == nil {
+		t.Error("expected error for mask 8")
+	}
+}
+
+func TestEncodeUsedBits(t *testing.T) {
+	tests := []struct {
+		symbol Symbol
+		want   int
+	}{
+		{SymbolQR, 4 + 9 + 28},    // Mode, count, 2 pairs and a single character
+		{SymbolMicro, 1 + 3 + 28}, // M2: shorter mode indicator and count
+	}
+	for _, tt := range tests {
+		enc := New("HELLO", LevelL)
+		enc.SetSymbol(tt.symbol)
+		if _, err := enc.Encode(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := enc.UsedBits(); got != tt.want {
+			t.Errorf("symbol %d: expected %d used bits, got %d", tt.symbol, tt.want, got)
+		}
+		if segs := enc.Segments(); len(segs) != 1 || segs[0].Mode != ModeAlphanumeric {
+			t.Errorf("symbol %d: expected one alphanumeric segment, got %+v", tt.symbol, segs)
+		}
+	}
+}
+
+func TestEncodeBoost(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		symbol Symbol
+		ecl    ErrorCorrectionLevel
+		boost  bool
+		want   ErrorCorrectionLevel
+	}{
+		{"off", "HELLO", SymbolQR, LevelL, false, LevelL},
+		{"QR to H", "HELLO", SymbolQR, LevelL, true, LevelH},
+		{"QR full", strings.Repeat("A", 25), SymbolQR, LevelL, true, LevelL}, // 1-L holds 25, 1-M 20
+		{"QR to Q", strings.Repeat("A", 16), SymbolQR, LevelL, true, LevelQ}, // 1-Q holds 16, 1-H 10
+		{"micro stays below H", "HELLO", SymbolMicro, LevelL, true, LevelM},
+		{"rMQR skips Q", "123", SymbolRMQR, LevelM, true, LevelH},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc := New(tt.data, tt.ecl)
+			enc.SetSymbol(tt.symbol)
+			enc.SetBoost(tt.boost)
+			if _, err := enc.Encode(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if enc.ErrorCorrection() != tt.want {
+				t.Errorf("expected level %d, got %d", tt.want, enc.ErrorCorrection())
+			}
+			if enc.Layout().ECC.ECCPerBlock == 0 {
+				t.Error("expected a layout for the boosted level")
+			}
+		})
+	}
+}
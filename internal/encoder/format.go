@@ -12,7 +12,12 @@ func FormatInfo(ecl ErrorCorrectionLevel, mask MaskPattern) uint16 {
 	// 1. Combine ECL (2 bits) + mask (3 bits) = 5 data bits
 	data := (eclBits[ecl] << 3) | uint16(mask)
 
-	// 2. Generate BCH(15,5) error correction
+	// 2-3. Add BCH error correction and XOR with mask pattern
+	return formatBCH(data) ^ formatInfoMask
+}
+
+// formatBCH appends the BCH(15,5) error correction bits to 5 data bits.
+func formatBCH(data uint16) uint16 {
 	// Generator polynomial: x^10 + x^8 + x^5 + x^4 + x^2 + x + 1 = 0x537
 	info := data << 10 // Make room for 10 ECC bits
 
@@ -24,10 +29,7 @@ func FormatInfo(ecl ErrorCorrectionLevel, mask MaskPattern) uint16 {
 	}
 
 	// Combine data and ECC
-	info = (data << 10) | info
-
-	// 3. XOR with mask pattern
-	return info ^ formatInfoMask
+	return (data << 10) | info
 }
 
 // PlaceFormatInfo places the format info bits on the matrix.
@@ -35,28 +37,28 @@ func FormatInfo(ecl ErrorCorrectionLevel, mask MaskPattern) uint16 {
 func PlaceFormatInfo(matrix *Matrix, info uint16) {
 	size := matrix.Size()
 
-	// Format info bit positions around top-left finder (bits 14-0, MSB first)
+	// Format info bit positions around top-left finder (bit 0 is the LSB)
 	// Vertical strip (column 8): rows 0-5, skip 6, rows 7-8
-	// Horizontal strip (row 8): cols 0-5, skip 6, cols 7-8
+	// Horizontal strip (row 8): cols 7, skip 6, cols 5-0
 
 	// Location 1: Around top-left finder
 	// Bits 0-5 go in column 8, rows 0-5
 	for i := 0; i <= 5; i++ {
-		bit := (info >> (14 - i)) & 1
+		bit := (info >> i) & 1
 		matrix.Set(8, i, Module{Dark: bit == 1, Type: ModuleFormatInfo, Reserved: true})
 	}
 	// Bit 6 goes in column 8, row 7 (skip row 6 - timing)
-	bit6 := (info >> 8) & 1
+	bit6 := (info >> 6) & 1
 	matrix.Set(8, 7, Module{Dark: bit6 == 1, Type: ModuleFormatInfo, Reserved: true})
 	// Bit 7 goes in column 8, row 8
 	bit7 := (info >> 7) & 1
 	matrix.Set(8, 8, Module{Dark: bit7 == 1, Type: ModuleFormatInfo, Reserved: true})
 	// Bit 8 goes in row 8, column 7
-	bit8 := (info >> 6) & 1
+	bit8 := (info >> 8) & 1
 	matrix.Set(7, 8, Module{Dark: bit8 == 1, Type: ModuleFormatInfo, Reserved: true})
 	// Bits 9-14 go in row 8, columns 5-0
 	for i := 9; i <= 14; i++ {
-		bit := (info >> (14 - i)) & 1
+		bit := (info >> i) & 1
 		matrix.Set(14-i, 8, Module{Dark: bit == 1, Type: ModuleFormatInfo, Reserved: true})
 	}
 
@@ -0,0 +1,70 @@
+package encoder
+
+import "sync"
+
+var (
+	sjisOnce  sync.Once
+	sjisCodes map[rune]uint16 // Reverse of sjisRunes, built on first use
+)
+
+// ShiftJIS returns the double-byte Shift_JIS code for r if r can be
+// encoded in Kanji mode (JIS X 0208 characters in 0x8140-0x9FFC and
+// 0xE040-0xEBBF).
+func ShiftJIS(r rune) (uint16, bool) {
+	sjisOnce.Do(func() {
+		sjisCodes = make(map[rune]uint16, len(sjisRunes))
+		for i, u := range sjisRunes {
+			if u == 0 {
+				continue
+			}
+			row, trail := i/189, i%189+0x40
+			lead := 0x81 + row
+			if row > 30 {
+				lead = 0xE0 + row - 31
+			}
+			sjisCodes[rune(u)] = uint16(lead<<8 | trail)
+		}
+	})
+	code, ok := sjisCodes[r]
+	return code, ok
+}
+
+// ShiftJISRune returns the character of a double-byte Shift_JIS code
+// in the Kanji mode ranges.
+func ShiftJISRune(code uint16) (rune, bool) {
+	lead, trail := int(code>>8), int(code&0xFF)
+	var row int
+	switch {
+	case lead >= 0x81 && lead <= 0x9F:
+		row = lead - 0x81
+	case lead >= 0xE0 && lead <= 0xEB:
+		row = lead - 0xE0 + 31
+	default:
+		return 0, false
+	}
+	if trail < 0x40 || trail > 0xFC {
+		return 0, false
+	}
+	u := sjisRunes[row*189+trail-0x40]
+	return rune(u), u != 0
+}
+
+// isKanji reports whether r can be encoded in Kanji mode.
+func isKanji(r rune) bool {
+	_, ok := ShiftJIS(r)
+	return ok
+}
+
+// encodeKanji packs each character's Shift_JIS code into 13 bits.
+func encodeKanji(bs *BitStream, data string) {
+	for _, r := range data {
+		code, _ := ShiftJIS(r)
+		c := uint(code)
+		if c <= 0x9FFC {
+			c -= 0x8140
+		} else {
+			c -= 0xC140
+		}
+		bs.AppendBits((c>>8)*0xC0+(c&0xFF), 13)
+	}
+}
@@ -0,0 +1,138 @@
+package encoder
+
+// Layout describes how the codewords of an encoded symbol are split into
+// Reed-Solomon blocks and interleaved onto its matrix.
+type Layout struct {
+	ECC        ECCInfo // Block structure
+	Protection int     // ECC codewords per block reserved against misdecodes
+	shortBits  int     // Bit offset of the 4-bit data codeword of M1 and M3, -1 for none
+}
+
+// BlockDamage counts the codewords of one block that are covered and
+// how many codeword errors the block can correct.
+type BlockDamage struct {
+	Damaged     int // Codewords with at least one covered module
+	Correctable int // Codeword errors the ECC codewords correct
+}
+
+// Recoverable reports whether the block still decodes with every damaged
+// codeword wrong.
+func (d BlockDamage) Recoverable() bool {
+	return d.Damaged <= d.Correctable
+}
+
+// misdecodeProtection holds the ECC codewords of small QR Code versions
+// that guard against misdecodes instead of correcting errors, indexed
+// by [version-1][ecl].
+var misdecodeProtection = [3][4]int{
+	{3, 2, 1, 1}, // Version 1
+	{2, 0, 0, 0}, // Version 2
+	{1, 0, 0, 0}, // Version 3
+}
+
+// qrLayout returns the layout of a QR Code version and level.
+func qrLayout(version Version, ecl ErrorCorrectionLevel) Layout {
+	l := Layout{ECC: GetECCInfo(version, ecl), shortBits: -1}
+	if version <= 3 {
+		l.Protection = misdecodeProtection[version-1][ecl]
+	}
+	return l
+}
+
+// microProtection holds the misdecode protection codewords of Micro QR
+// symbols, indexed by [version-1][ecl]. M1 only detects errors.
+var microProtection = [4][3]int{
+	{2},       // M1
+	{3, 2},    // M2
+	{2, 0},    // M3
+	{2, 0, 0}, // M4
+}
+
+// microLayout returns the single block layout of a Micro QR symbol.
+func microLayout(version MicroVersion, ecl ErrorCorrectionLevel, c microCapacity) Layout {
+	dataCodewords := (c.dataBits + 7) / 8
+	total := dataCodewords + c.eccCodewords
+	l := Layout{
+		ECC: ECCInfo{
+			TotalCodewords: total,
+			ECCPerBlock:    c.eccCodewords,
+			Group1:         BlockInfo{1, total, dataCodewords},
+		},
+		Protection: microProtection[version-1][ecl],
+		shortBits:  -1,
+	}
+	if c.dataBits%8 != 0 {
+		l.shortBits = c.dataBits - 4
+	}
+	return l
+}
+
+// Blocks returns the number of Reed-Solomon blocks.
+func (l Layout) Blocks() int {
+	return l.ECC.Group1.Count + l.ECC.Group2.Count
+}
+
+// Correctable returns the codeword errors each block can correct.
+func (l Layout) Correctable() int {
+	return (l.ECC.ECCPerBlock - l.Protection) / 2
+}
+
+// codewordBlocks returns the block of each codeword in placement order,
+// following InterleaveBlocks.
+func (l Layout) codewordBlocks() []int {
+	sizes := make([]int, 0, l.Blocks())
+	for range l.ECC.Group1.Count {
+		sizes = append(sizes, l.ECC.Group1.DataCodewords)
+	}
+	for range l.ECC.Group2.Count {
+		sizes = append(sizes, l.ECC.Group2.DataCodewords)
+	}
+
+	blocks := make([]int, 0, l.ECC.TotalCodewords)
+	for i := 0; i < l.ECC.Group2.DataCodewords || i < l.ECC.Group1.DataCodewords; i++ {
+		for b, size := range sizes {
+			if i < size {
+				blocks = append(blocks, b)
+			}
+		}
+	}
+	for range l.ECC.ECCPerBlock {
+		for b := range sizes {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
+
+// codeword returns the index of the codeword holding data bit i.
+func (l Layout) codeword(i int) int {
+	if l.shortBits >= 0 && i >= l.shortBits {
+		if i < l.shortBits+4 {
+			return l.shortBits / 8
+		}
+		i += 4
+	}
+	return i / 8
+}
+
+// Damage counts the codewords of each block that have a module covered
+// on m, which must hold data placed with this layout. Remainder bits
+// and function patterns are not counted.
+func (l Layout) Damage(m *Matrix, covered func(x, y int) bool) []BlockDamage {
+	blocks := l.codewordBlocks()
+	damage := make([]BlockDamage, l.Blocks())
+	for b := range damage {
+		damage[b].Correctable = l.Correctable()
+	}
+
+	hit := make([]bool, len(blocks))
+	for i, pos := range m.DataPositions() {
+		cw := l.codeword(i)
+		if cw >= len(blocks) || hit[cw] || !covered(pos[0], pos[1]) {
+			continue
+		}
+		hit[cw] = true
+		damage[blocks[cw]].Damaged++
+	}
+	return damage
+}
@@ -0,0 +1,80 @@
+package encoder
+
+import "testing"
+
+func TestLayoutCodewordBlocks(t *testing.T) {
+	// Version 5-Q has two groups of different block lengths
+	info := GetECCInfo(5, LevelQ)
+	data := make([]byte, info.DataCapacity())
+	dataBlocks, eccBlocks := GenerateECC(data, info)
+	for b := range dataBlocks {
+		for i := range dataBlocks[b] {
+			dataBlocks[b][i] = byte(b)
+		}
+		for i := range eccBlocks[b] {
+			eccBlocks[b][i] = byte(b)
+		}
+	}
+	interleaved := InterleaveBlocks(dataBlocks, eccBlocks)
+
+	blocks := qrLayout(5, LevelQ).codewordBlocks()
+	if len(blocks) != len(interleaved) {
+		t.Fatalf("got %d codewords, want %d", len(blocks), len(interleaved))
+	}
+	for i, b := range blocks {
+		if byte(b) != interleaved[i] {
+			t.Fatalf("codeword %d in block %d, want %d", i, b, interleaved[i])
+		}
+	}
+}
+
+func TestLayoutCorrectable(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout Layout
+		want   int
+	}{
+		{"1-L", qrLayout(1, LevelL), 2},
+		{"1-H", qrLayout(1, LevelH), 8},
+		{"3-L", qrLayout(3, LevelL), 7},
+		{"10-M", qrLayout(10, LevelM), 13},
+		{"M1", microLayout(1, LevelL, microTable[0][0]), 0},
+		{"M2-L", microLayout(2, LevelL, microTable[1][0]), 1},
+		{"M2-M", microLayout(2, LevelM, microTable[1][1]), 2},
+		{"M4-Q", microLayout(4, LevelQ, microTable[3][2]), 7},
+	}
+	for _, tt := range tests {
+		if got := tt.layout.Correctable(); got != tt.want {
+			t.Errorf("%s: Correctable() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutDamage(t *testing.T) {
+	matrix := NewMatrix(1)
+	matrix.PlaceFunctionPatterns(1)
+	positions := matrix.DataPositions()
+
+	// Cover codewords 0 and 2 fully and one module of codeword 3
+	covered := make(map[[2]int]bool)
+	for _, i := range []int{0, 1, 2, 3, 4, 5, 6, 7, 16, 17, 18, 19, 20, 21, 22, 23, 30} {
+		covered[positions[i]] = true
+	}
+	damage := qrLayout(1, LevelM).Damage(matrix, func(x, y int) bool { return covered[[2]int{x, y}] })
+	if len(damage) != 1 || damage[0].Damaged != 3 || damage[0].Correctable != 4 {
+		t.Fatalf("damage = %+v, want 3 damaged of 4 correctable", damage)
+	}
+	if !damage[0].Recoverable() {
+		t.Error("expected block to be recoverable")
+	}
+}
+
+func TestLayoutMicroShortCodeword(t *testing.T) {
+	// M1 holds 20 data bits: two codewords and a 4-bit one, then ECC
+	l := microLayout(1, LevelL, microTable[0][0])
+	for _, tt := range []struct{ bit, want int }{{15, 1}, {16, 2}, {19, 2}, {20, 3}, {27, 3}, {28, 4}} {
+		if got := l.codeword(tt.bit); got != tt.want {
+			t.Errorf("codeword(%d) = %d, want %d", tt.bit, got, tt.want)
+		}
+	}
+}
@@ -40,9 +40,8 @@ func (mp MaskPattern) ShouldFlip(x, y int) bool {
 // ApplyMask applies the mask pattern to a matrix.
 // Only data modules are affected, not function patterns.
 func ApplyMask(matrix *Matrix, pattern MaskPattern) {
-	size := matrix.Size()
-	for y := 0; y < size; y++ {
-		for x := 0; x < size; x++ {
+	for y := 0; y < matrix.Height(); y++ {
+		for x := 0; x < matrix.Width(); x++ {
 			mod := matrix.Get(x, y)
 			if !mod.Reserved && pattern.ShouldFlip(x, y) {
 				mod.Dark = !mod.Dark
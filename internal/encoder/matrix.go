@@ -21,28 +21,62 @@ const (
 	ModuleDarkModule // The single always-dark module
 )
 
-// Matrix represents the QR code module grid.
+// Matrix represents the QR code module grid. QR and Micro QR matrices
+// are square; rMQR matrices are wider than they are high.
 type Matrix struct {
-	size    int
-	modules [][]Module
+	width   int
+	height  int
+	modules [][]Module // Indexed [y][x]
+
+	finders    [][2]int // Top-left corners of finder patterns
+	alignments [][2]int // Centers of alignment patterns
+	timingCol  int      // Column skipped by data placement, -1 for none
 }
 
 // NewMatrix creates a matrix for the given version.
 func NewMatrix(version Version) *Matrix {
-	size := version.Size()
-	modules := make([][]Module, size)
+	m := newMatrix(version.Size(), version.Size())
+	m.timingCol = 6
+	return m
+}
+
+func newMatrix(width, height int) *Matrix {
+	modules := make([][]Module, height)
 	for i := range modules {
-		modules[i] = make([]Module, size)
+		modules[i] = make([]Module, width)
 	}
 	return &Matrix{
-		size:    size,
-		modules: modules,
+		width:     width,
+		height:    height,
+		modules:   modules,
+		timingCol: -1,
 	}
 }
 
-// Size returns the dimension of the matrix.
+// Size returns the dimension of a square matrix. For rMQR matrices it is
+// the width; use Width and Height for those.
 func (m *Matrix) Size() int {
-	return m.size
+	return m.width
+}
+
+// Width returns the number of modules per row.
+func (m *Matrix) Width() int {
+	return m.width
+}
+
+// Height returns the number of modules per column.
+func (m *Matrix) Height() int {
+	return m.height
+}
+
+// Finders returns the top-left corners of the 7x7 finder patterns.
+func (m *Matrix) Finders() [][2]int {
+	return m.finders
+}
+
+// Alignments returns the centers of the 5x5 alignment patterns.
+func (m *Matrix) Alignments() [][2]int {
+	return m.alignments
 }
 
 // Get returns the module at (x, y).
@@ -55,12 +89,35 @@ func (m *Matrix) Set(x, y int, mod Module) {
 	m.modules[y][x] = mod
 }
 
+// InFinder reports whether (x, y) lies in one of the 7x7 finder patterns.
+// rMQR corner patterns are finder modules outside them.
+func (m *Matrix) InFinder(x, y int) bool {
+	for _, f := range m.finders {
+		if x >= f[0] && x < f[0]+7 && y >= f[1] && y < f[1]+7 {
+			return true
+		}
+	}
+	return false
+}
+
+// InAlignment reports whether (x, y) lies in one of the 5x5 alignment
+// patterns. rMQR 3x3 alignment patterns are alignment modules outside
+// them.
+func (m *Matrix) InAlignment(x, y int) bool {
+	for _, a := range m.alignments {
+		if x >= a[0]-2 && x <= a[0]+2 && y >= a[1]-2 && y <= a[1]+2 {
+			return true
+		}
+	}
+	return false
+}
+
 // PlaceFunctionPatterns places all non-data patterns on the matrix.
 func (m *Matrix) PlaceFunctionPatterns(version Version) {
 	// 1. Place finder patterns (3 corners)
-	m.placeFinder(0, 0)        // top-left
-	m.placeFinder(m.size-7, 0) // top-right
-	m.placeFinder(0, m.size-7) // bottom-left
+	m.placeFinder(0, 0)          // top-left
+	m.placeFinder(m.Size()-7, 0) // top-right
+	m.placeFinder(0, m.Size()-7) // bottom-left
 
 	// 2. Place finder separators
 	m.placeSeparators()
@@ -92,23 +149,41 @@ func (m *Matrix) PlaceData(data []byte) {
 			bits[i*8+j] = (b>>(7-j))&1 == 1
 		}
 	}
+	m.PlaceBits(bits)
+}
+
+// PlaceBits places data bits onto the matrix in the zigzag order,
+// leaving remaining modules light.
+func (m *Matrix) PlaceBits(bits []bool) {
+	for i, pos := range m.DataPositions() {
+		x, y := pos[0], pos[1]
+		m.modules[y][x].Dark = i < len(bits) && bits[i]
+		m.modules[y][x].Type = ModuleData
+	}
+}
 
-	bitIndex := 0
-	// Start from right side, move left in 2-column strips
-	// Skip column 6 (timing pattern)
-	for col := m.size - 1; col >= 0; col -= 2 {
-		if col == 6 {
-			col = 5 // Skip timing column
-		}
+// DataPositions returns the unreserved modules in the zigzag order data
+// bits are placed in: codeword i covers positions 8i to 8i+7. Placement
+// starts in the rightmost column with free modules.
+func (m *Matrix) DataPositions() [][2]int {
+	start := m.width - 1
+	for start > 0 && m.columnReserved(start) {
+		start--
+	}
 
-		// Zigzag: go up on even strips, down on odd
-		// We determine direction based on which strip we're on
-		upward := ((m.size-1-col)/2)%2 == 0
+	var positions [][2]int
+	upward := true
+	// Move left in 2-column strips, alternating up and down
+	// Skip the vertical timing column (column 6 in QR Codes)
+	for col := start; col >= 0; col -= 2 {
+		if col == m.timingCol {
+			col-- // Skip timing column
+		}
 
-		for row := 0; row < m.size; row++ {
+		for row := 0; row < m.height; row++ {
 			actualRow := row
 			if upward {
-				actualRow = m.size - 1 - row
+				actualRow = m.height - 1 - row
 			}
 
 			// Try right column, then left column
@@ -119,27 +194,37 @@ func (m *Matrix) PlaceData(data []byte) {
 				}
 
 				if !m.modules[actualRow][x].Reserved {
-					dark := false
-					if bitIndex < len(bits) {
-						dark = bits[bitIndex]
-						bitIndex++
-					}
-					m.modules[actualRow][x].Dark = dark
-					m.modules[actualRow][x].Type = ModuleData
+					positions = append(positions, [2]int{x, actualRow})
 				}
 			}
 		}
+		upward = !upward
+	}
+	return positions
+}
+
+// columnReserved reports whether every module of column x is reserved.
+func (m *Matrix) columnReserved(x int) bool {
+	for y := 0; y < m.height; y++ {
+		if !m.modules[y][x].Reserved {
+			return false
+		}
 	}
+	return true
 }
 
 // Clone creates a deep copy of the matrix.
 func (m *Matrix) Clone() *Matrix {
 	clone := &Matrix{
-		size:    m.size,
-		modules: make([][]Module, m.size),
+		width:      m.width,
+		height:     m.height,
+		modules:    make([][]Module, m.height),
+		finders:    m.finders,
+		alignments: m.alignments,
+		timingCol:  m.timingCol,
 	}
 	for i := range m.modules {
-		clone.modules[i] = make([]Module, m.size)
+		clone.modules[i] = make([]Module, m.width)
 		copy(clone.modules[i], m.modules[i])
 	}
 	return clone
@@ -149,13 +234,13 @@ func (m *Matrix) Clone() *Matrix {
 // These are alternating dark/light modules between finder patterns.
 func (m *Matrix) placeTiming() {
 	// Horizontal timing: row 6, from col 8 to size-9
-	for i := 8; i < m.size-8; i++ {
+	for i := 8; i < m.Size()-8; i++ {
 		dark := i%2 == 0
 		m.Set(i, 6, Module{Dark: dark, Type: ModuleTiming, Reserved: true})
 	}
 
 	// Vertical timing: col 6, from row 8 to size-9
-	for i := 8; i < m.size-8; i++ {
+	for i := 8; i < m.Size()-8; i++ {
 		dark := i%2 == 0
 		m.Set(6, i, Module{Dark: dark, Type: ModuleTiming, Reserved: true})
 	}
@@ -181,6 +266,7 @@ func (m *Matrix) placeAlignment(version Version) {
 
 // placeAlignmentPattern places a 5x5 alignment pattern centered at (cx, cy).
 func (m *Matrix) placeAlignmentPattern(cx, cy int) {
+	m.alignments = append(m.alignments, [2]int{cx, cy})
 	for row := -2; row <= 2; row++ {
 		for col := -2; col <= 2; col++ {
 			// Dark if on border OR center
@@ -207,12 +293,12 @@ func (m *Matrix) reserveFormatInfo() {
 
 	// Below top-right finder
 	for i := 0; i < 8; i++ {
-		m.Set(m.size-1-i, 8, Module{Type: ModuleFormatInfo, Reserved: true})
+		m.Set(m.Size()-1-i, 8, Module{Type: ModuleFormatInfo, Reserved: true})
 	}
 
 	// Right of bottom-left finder
 	for i := 0; i < 7; i++ {
-		m.Set(8, m.size-1-i, Module{Type: ModuleFormatInfo, Reserved: true})
+		m.Set(8, m.Size()-1-i, Module{Type: ModuleFormatInfo, Reserved: true})
 	}
 }
 
@@ -221,14 +307,14 @@ func (m *Matrix) reserveVersionInfo() {
 	// Bottom-left of top-right finder (6x3 block)
 	for i := 0; i < 6; i++ {
 		for j := 0; j < 3; j++ {
-			m.Set(m.size-11+j, i, Module{Type: ModuleVersionInfo, Reserved: true})
+			m.Set(m.Size()-11+j, i, Module{Type: ModuleVersionInfo, Reserved: true})
 		}
 	}
 
 	// Top-right of bottom-left finder (3x6 block)
 	for i := 0; i < 6; i++ {
 		for j := 0; j < 3; j++ {
-			m.Set(i, m.size-11+j, Module{Type: ModuleVersionInfo, Reserved: true})
+			m.Set(i, m.Size()-11+j, Module{Type: ModuleVersionInfo, Reserved: true})
 		}
 	}
 }
@@ -250,14 +336,12 @@ func alignmentPatternPositions(version Version) []int {
 		return []int{first, last}
 	}
 
-	// Calculate step: must be even, spread evenly between first and last
-	totalDistance := last - first
-	steps := count - 1
-	step := totalDistance / steps
-
-	// Step must be even (QR spec requirement)
-	if step%2 != 0 {
-		step++
+	// Calculate step: even, spread evenly from last back towards first,
+	// leaving any remainder in the first gap. Version 32 is the one
+	// exception to the formula.
+	step := 26
+	if version != 32 {
+		step = (int(version)*4 + count*2 + 1) / (count*2 - 2) * 2
 	}
 
 	// Build positions from last going backwards
@@ -281,19 +365,20 @@ func (m *Matrix) placeSeparators() {
 
 	// Top-right: left edge (col size-8) and bottom edge (row 7)
 	for i := 0; i < 8; i++ {
-		m.Set(m.size-8, i, Module{Type: ModuleFinderSeparator, Reserved: true})   // left edge
-		m.Set(m.size-8+i, 7, Module{Type: ModuleFinderSeparator, Reserved: true}) // bottom edge
+		m.Set(m.Size()-8, i, Module{Type: ModuleFinderSeparator, Reserved: true})   // left edge
+		m.Set(m.Size()-8+i, 7, Module{Type: ModuleFinderSeparator, Reserved: true}) // bottom edge
 	}
 
 	// Bottom-left: right edge (col 7) and top edge (row size-8)
 	for i := 0; i < 8; i++ {
-		m.Set(7, m.size-8+i, Module{Type: ModuleFinderSeparator, Reserved: true}) // right edge
-		m.Set(i, m.size-8, Module{Type: ModuleFinderSeparator, Reserved: true})   // top edge
+		m.Set(7, m.Size()-8+i, Module{Type: ModuleFinderSeparator, Reserved: true}) // right edge
+		m.Set(i, m.Size()-8, Module{Type: ModuleFinderSeparator, Reserved: true})   // top edge
 	}
 }
 
 // placeFinder places a 7x7 finder pattern at (x, y).
 func (m *Matrix) placeFinder(x, y int) {
+	m.finders = append(m.finders, [2]int{x, y})
 	for row := range 7 {
 		for col := range 7 {
 			// Dark if on border OR in 3x3 center
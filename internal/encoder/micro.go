@@ -0,0 +1,260 @@
+package encoder
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MicroVersion represents a Micro QR Code version (M1-M4).
+// Version determines the size: (version * 2) + 9 modules per side.
+type MicroVersion int
+
+// Size returns the number of modules per side for this version.
+func (v MicroVersion) Size() int {
+	return int(v)*2 + 9
+}
+
+// microCapacity describes one Micro QR version/level combination.
+type microCapacity struct {
+	symbol       int // Symbol number in the format information
+	dataBits     int // Data capacity; M1 and M3 end in a 4-bit codeword
+	eccCodewords int
+}
+
+// microTable holds capacities indexed by [version-1][ecl]. M1 only
+// detects errors and is listed under L; zero entries are unavailable.
+var microTable = [4][3]microCapacity{
+	{{0, 20, 2}},                             // M1
+	{{1, 40, 5}, {2, 32, 6}},                 // M2
+	{{3, 84, 6}, {4, 68, 8}},                 // M3
+	{{5, 128, 8}, {6, 112, 10}, {7, 80, 14}}, // M4
+}
+
+// microCapacityFor returns the capacity of a version and level, or
+// false if the version lacks the level.
+func microCapacityFor(version MicroVersion, ecl ErrorCorrectionLevel) (microCapacity, bool) {
+	if ecl > LevelQ {
+		return microCapacity{}, false
+	}
+	c := microTable[version-1][ecl]
+	return c, c.dataBits > 0
+}
+
+// microHeader returns the segment headers of a Micro QR version: mode
+// indicators of version-1 bits and shorter character counts. M1 only
+// has numeric mode, M2 adds alphanumeric.
+func microHeader(version MicroVersion) segmentHeader {
+	v := int(version)
+	return segmentHeader{
+		modeBits: v - 1,
+		indicator: func(m Mode) uint {
+			switch m {
+			case ModeAlphanumeric:
+				return 1
+			case ModeByte:
+				return 2
+			case ModeKanji:
+				return 3
+			}
+			return 0
+		},
+		countBits: func(m Mode) int {
+			switch {
+			case m == ModeNumeric:
+				return v + 2
+			case m == ModeAlphanumeric && v >= 2:
+				return v + 1
+			case m == ModeByte && v >= 3:
+				return v + 1
+			case m == ModeKanji && v >= 3:
+				return v
+			}
+			return 0
+		},
+	}
+}
+
+// fitMicroSegments splits data into segments for the smallest Micro QR
+// version from lo to hi that holds it at the given level.
+func fitMicroSegments(data string, ecl ErrorCorrectionLevel, lo, hi MicroVersion) ([]Segment, MicroVersion, error) {
+	if ecl > LevelQ {
+		return nil, 0, errors.New("micro QR does not support error correction level H")
+	}
+	for v := lo; v <= hi; v++ {
+		c, ok := microCapacityFor(v, ecl)
+		if !ok {
+			continue
+		}
+		h := microHeader(v)
+		segs, err := splitSegments(data, h, nil)
+		if errors.Is(err, errUnavailableMode) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if bits := segmentsBits(segs, h); bits >= 0 && bits <= c.dataBits {
+			return segs, v, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("micro QR: %w", ErrDataTooLong)
+}
+
+// encodeMicroData encodes segments followed by the terminator and
+// padding. The final codeword of M1 and M3 is 4 bits long and padded
+// with zeros.
+func encodeMicroData(segs []Segment, version MicroVersion, capacityBits int) *BitStream {
+	// Terminator of 3, 5, 7 or 9 zero bits
+	return encodePadded(segs, microHeader(version), 2*int(version)+1, capacityBits)
+}
+
+// NewMicroMatrix creates a Micro QR matrix with its function patterns:
+// one finder, its separator, timing along the top and left edges and
+// the reserved format information area.
+func NewMicroMatrix(version MicroVersion) *Matrix {
+	m := newMatrix(version.Size(), version.Size())
+	m.placeFinder(0, 0)
+
+	for i := 0; i < 8; i++ {
+		m.Set(7, i, Module{Type: ModuleFinderSeparator, Reserved: true})
+		m.Set(i, 7, Module{Type: ModuleFinderSeparator, Reserved: true})
+	}
+
+	for i := 8; i < m.width; i++ {
+		dark := i%2 == 0
+		m.Set(i, 0, Module{Dark: dark, Type: ModuleTiming, Reserved: true})
+		m.Set(0, i, Module{Dark: dark, Type: ModuleTiming, Reserved: true})
+	}
+
+	for i := 1; i <= 8; i++ {
+		m.Set(i, 8, Module{Type: ModuleFormatInfo, Reserved: true})
+		m.Set(8, i, Module{Type: ModuleFormatInfo, Reserved: true})
+	}
+	return m
+}
+
+// microMasks maps the 2-bit Micro QR mask references to their patterns.
+var microMasks = [4]MaskPattern{Mask1, Mask4, Mask6, Mask7}
+
+// EvaluateMicroMask scores a masked Micro QR matrix by the dark modules
+// on its right and bottom edges. Higher score is better.
+func EvaluateMicroMask(matrix *Matrix) int {
+	size := matrix.Size()
+	sum1, sum2 := 0, 0
+	for i := 1; i < size; i++ {
+		if matrix.Get(size-1, i).Dark {
+			sum1++
+		}
+		if matrix.Get(i, size-1).Dark {
+			sum2++
+		}
+	}
+	if sum1 <= sum2 {
+		return sum1*16 + sum2
+	}
+	return sum2*16 + sum1
+}
+
+// SelectBestMicroMask tries the 4 Micro QR masks and returns the
+// reference (0-3) of the best one.
+func SelectBestMicroMask(matrix *Matrix) int {
+	best, bestScore := 0, -1
+	for ref, mask := range microMasks {
+		testMatrix := matrix.Clone()
+		ApplyMask(testMatrix, mask)
+		if score := EvaluateMicroMask(testMatrix); score > bestScore {
+			best, bestScore = ref, score
+		}
+	}
+	return best
+}
+
+// Micro QR format info mask pattern for XOR
+const microFormatInfoMask = 0x4445 // 100010001000101
+
+// MicroFormatInfo encodes the symbol number (version and level) and the
+// mask reference.
+func MicroFormatInfo(symbol, maskRef int) uint16 {
+	return formatBCH(uint16(symbol<<2|maskRef)) ^ microFormatInfoMask
+}
+
+// PlaceMicroFormatInfo places the format info bits around the finder:
+// bits 0-7 down column 8 and bits 14-8 along row 8.
+func PlaceMicroFormatInfo(matrix *Matrix, info uint16) {
+	for i := 0; i < 8; i++ {
+		bit := (info >> i) & 1
+		matrix.Set(8, i+1, Module{Dark: bit == 1, Type: ModuleFormatInfo, Reserved: true})
+	}
+	for i := 0; i < 7; i++ {
+		bit := (info >> (14 - i)) & 1
+		matrix.Set(i+1, 8, Module{Dark: bit == 1, Type: ModuleFormatInfo, Reserved: true})
+	}
+}
+
+// encodeMicro performs the Micro QR encoding process.
+func (e *Encoder) encodeMicro() (*Matrix, error) {
+	if e.charset != nil {
+		return nil, errors.New("micro QR does not support ECI character sets")
+	}
+	if e.sequence != nil {
+		return nil, errors.New("micro QR does not support Structured Append")
+	}
+	if e.gs1 {
+		return nil, errors.New("micro QR does not support GS1 data")
+	}
+
+	// 1-2. Split data into segments and pick the smallest version
+	lo, hi, err := e.versions(4)
+	if err != nil {
+		return nil, fmt.Errorf("micro QR: %w", err)
+	}
+	if e.fixedMask > 3 {
+		return nil, fmt.Errorf("micro QR: mask %d out of range 0-3", e.fixedMask)
+	}
+	segments, version, err := fitMicroSegments(e.data, e.errorCorrection, MicroVersion(lo), MicroVersion(hi))
+	if e.tooLong(err) {
+		return nil, fmt.Errorf("micro QR M%d: %w", e.fixedVersion, ErrVersionTooSmall)
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.segments = segments
+	e.version = int(version)
+	e.usedBits = segmentsBits(segments, microHeader(version))
+	e.level = e.boostLevel(e.usedBits, LevelQ, func(l ErrorCorrectionLevel) int {
+		if c, ok := microCapacityFor(version, l); ok {
+			return c.dataBits
+		}
+		return -1
+	})
+
+	// 3-4. Encode data and generate error correction (a single block)
+	capacity, _ := microCapacityFor(version, e.level)
+	e.layout = microLayout(version, e.level, capacity)
+	bs := encodeMicroData(segments, version, capacity.dataBits)
+	dataBytes := bs.Bytes()
+	eccBytes := ReedSolomonEncode(dataBytes, capacity.eccCodewords)
+
+	// 5. Place data bits; a 4-bit final codeword is placed as 4 bits
+	bits := make([]bool, 0, capacity.dataBits+len(eccBytes)*8)
+	bits = append(bits, bs.bits...)
+	for _, b := range eccBytes {
+		for j := 7; j >= 0; j-- {
+			bits = append(bits, (b>>j)&1 == 1)
+		}
+	}
+	matrix := NewMicroMatrix(version)
+	matrix.PlaceBits(bits)
+
+	// 6. Apply the best of the 4 masks, unless fixed, then format
+	// information
+	maskRef := e.fixedMask
+	if maskRef < 0 {
+		maskRef = SelectBestMicroMask(matrix)
+	}
+	ApplyMask(matrix, microMasks[maskRef])
+	e.mask = maskRef
+	PlaceMicroFormatInfo(matrix, MicroFormatInfo(capacity.symbol, maskRef))
+
+	return matrix, nil
+}
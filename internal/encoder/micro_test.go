@@ -0,0 +1,120 @@
+package encoder
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncodeMicroDataAnnex(t *testing.T) {
+	// "01234567" as M2-L, from the worked example in ISO/IEC 18004
+	segs, version, err := fitMicroSegments("01234567", LevelL, 1, 4)
+	if err != nil || version != 2 {
+		t.Fatalf("got version M%d, %v; want M2", version, err)
+	}
+	data := encodeMicroData(segs, version, 40).Bytes()
+	if want := []byte{0x40, 0x18, 0xAC, 0xC3, 0x00}; !bytes.Equal(data, want) {
+		t.Errorf("data = %x, want %x", data, want)
+	}
+	if ecc, want := ReedSolomonEncode(data, 5), []byte{0x86, 0x0D, 0x22, 0xAE, 0x30}; !bytes.Equal(ecc, want) {
+		t.Errorf("ecc = %x, want %x", ecc, want)
+	}
+}
+
+func TestEncodeMicroDataHalfCodeword(t *testing.T) {
+	// M3-L holds 84 bits: ten full codewords and a final 4-bit one
+	segs, _, _ := fitMicroSegments("1", LevelM, 1, 4)
+	bs := encodeMicroData(segs, 3, 84)
+	if bs.Len() != 84 {
+		t.Fatalf("got %d bits, want 84", bs.Len())
+	}
+	// 00 mode, 00001 count, 0001 digit, 7-bit terminator, then padding
+	// codewords and a zero 4-bit codeword
+	want := []byte{0x02, 0x20, 0x00, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x00}
+	if got := bs.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+}
+
+func TestFitMicroSegments(t *testing.T) {
+	tests := []struct {
+		data string
+		ecl  ErrorCorrectionLevel
+		want MicroVersion
+	}{
+		{"12345", LevelL, 1},
+		{"123456", LevelL, 2},
+		{"12345", LevelM, 2}, // M1 has no level M
+		{"HELLO", LevelL, 2}, // M1 has no alphanumeric mode
+		{"hello", LevelL, 3}, // Byte mode starts at M3
+		{"hello", LevelQ, 4}, // Only M4 has level Q
+		{strings.Repeat("1", 35), LevelL, 4},
+	}
+	for _, tt := range tests {
+		_, v, err := fitMicroSegments(tt.data, tt.ecl, 1, 4)
+		if err != nil || v != tt.want {
+			t.Errorf("fitMicroSegments(%q, %d) = M%d, %v; want M%d", tt.data, tt.ecl, v, err, tt.want)
+		}
+	}
+
+	if _, _, err := fitMicroSegments(strings.Repeat("1", 36), LevelL, 1, 4); !errors.Is(err, ErrDataTooLong) {
+		t.Errorf("expected ErrDataTooLong, got %v", err)
+	}
+	if _, _, err := fitMicroSegments("1", LevelH, 1, 4); err == nil {
+		t.Error("expected error for level H")
+	}
+}
+
+func TestMicroFormatInfo(t *testing.T) {
+	// Symbol 0, mask 0 has no BCH bits, leaving the XOR mask
+	if got := MicroFormatInfo(0, 0); got != 0x4445 {
+		t.Errorf("MicroFormatInfo(0, 0) = %#x, want 0x4445", got)
+	}
+	// Data bits stay in the top 5 bits
+	if got := MicroFormatInfo(7, 3) ^ 0x4445; got>>10 != 7<<2|3 {
+		t.Errorf("MicroFormatInfo(7, 3) data bits = %05b", got>>10)
+	}
+}
+
+func TestEncodeMicro(t *testing.T) {
+	for _, data := range []string{"1", "01234567", "HELLO", "hello", "ｱｲｳ漢字"} {
+		enc := New(data, LevelL)
+		enc.SetSymbol(SymbolMicro)
+		matrix, err := enc.Encode()
+		if err != nil {
+			t.Fatalf("%q: %v", data, err)
+		}
+		size := matrix.Size()
+		if size < 11 || size > 17 || size%2 == 0 {
+			t.Errorf("%q: invalid Micro QR size %d", data, size)
+		}
+		if f := matrix.Finders(); len(f) != 1 || f[0] != [2]int{0, 0} {
+			t.Errorf("%q: expected one finder at the origin, got %v", data, f)
+		}
+		// Timing runs along the top edge
+		for x := 8; x < size; x++ {
+			if matrix.Get(x, 0).Dark != (x%2 == 0) || matrix.Get(x, 0).Type != ModuleTiming {
+				t.Errorf("%q: bad timing module at (%d, 0)", data, x)
+			}
+		}
+	}
+
+	enc := New("1", LevelL)
+	enc.SetSymbol(SymbolMicro)
+	enc.SetCharset(CharsetUTF8)
+	if _, err := enc.Encode(); err == nil {
+		t.Error("expected error for ECI in Micro QR")
+	}
+}
+
+func TestEvaluateMicroMask(t *testing.T) {
+	m := NewMicroMatrix(1)
+	for i := 1; i < 11; i++ {
+		m.Set(10, i, Module{Dark: true})
+	}
+	// SUM1 = 10 (right edge), SUM2 = 1 (the shared corner)
+	if got := EvaluateMicroMask(m); got != 1*16+10 {
+		t.Errorf("EvaluateMicroMask = %d, want %d", got, 1*16+10)
+	}
+}
@@ -1,17 +1,29 @@
 package encoder
 
-import "strings"
+import (
+	"strings"
+	"unicode/utf8"
+)
 
 // Mode represents the encoding mode for QR data.
 type Mode int
 
 const (
-	ModeNumeric      Mode = iota // 0-9 only
-	ModeAlphanumeric             // 0-9, A-Z, space, $%*+-./:
-	ModeByte                     // Any 8-bit data (UTF-8)
-	ModeKanji                    // Kanji characters
+	ModeNumeric          Mode = iota // 0-9 only
+	ModeAlphanumeric                 // 0-9, A-Z, space, $%*+-./:
+	ModeByte                         // Any 8-bit data (UTF-8)
+	ModeKanji                        // Kanji characters
+	ModeECI                          // Character set header, no data
+	ModeStructuredAppend             // Sequence header, no data
+	ModeFNC1First                    // GS1 header, no data
+	ModeFNC1Second                   // Industry application header, no data
 )
 
+// fnc1Separator is the GS character that separates variable-length
+// elements in FNC1 mode data. Alphanumeric segments write it as % and a
+// literal % as %%.
+const fnc1Separator = '\x1d'
+
 func (m Mode) ModeIndicator() uint8 {
 	switch m {
 	case ModeNumeric:
@@ -22,10 +34,23 @@ func (m Mode) ModeIndicator() uint8 {
 		return 4
 	case ModeKanji:
 		return 8
+	case ModeECI:
+		return 7
+	case ModeStructuredAppend:
+		return 3
+	case ModeFNC1First:
+		return 5
+	case ModeFNC1Second:
+		return 9
 	}
 	return 0
 }
 
+// header reports whether the mode is a header without data.
+func (m Mode) header() bool {
+	return m == ModeECI || m == ModeStructuredAppend || m == ModeFNC1First || m == ModeFNC1Second
+}
+
 func (m Mode) CharCountBits(version Version) int {
 	if version < 1 || version > 40 {
 		return 0
@@ -68,9 +93,35 @@ func (m Mode) BitsPerChar() float64 {
 	return 0
 }
 
+// DataBits returns the exact number of bits needed to encode n characters
+// in this mode, excluding the mode indicator and character count.
+func (m Mode) DataBits(n int) int {
+	switch m {
+	case ModeNumeric:
+		return 10*(n/3) + []int{0, 4, 7}[n%3]
+	case ModeAlphanumeric:
+		return 11*(n/2) + 6*(n%2)
+	case ModeByte:
+		return 8 * n
+	case ModeKanji:
+		return 13 * n
+	}
+	return 0
+}
+
+// CharCount returns the value of the character count indicator for data:
+// characters in Kanji mode, bytes otherwise.
+func (m Mode) CharCount(data string) int {
+	if m == ModeKanji {
+		return utf8.RuneCountInString(data)
+	}
+	return len(data)
+}
+
 func AnalyzeData(data string) Mode {
 	isNum := true
 	isAlph := true
+	isKanj := data != ""
 	for _, r := range data {
 		if isAlph && (r < '0' || r > '9') {
 			isNum = false
@@ -78,11 +129,16 @@ func AnalyzeData(data string) Mode {
 				isAlph = false
 			}
 		}
+		if isKanj && !isKanji(r) {
+			isKanj = false
+		}
 	}
 	if isNum {
 		return ModeNumeric
 	} else if isAlph {
 		return ModeAlphanumeric
+	} else if isKanj {
+		return ModeKanji
 	}
 	return ModeByte
 }
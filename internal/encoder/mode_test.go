@@ -27,6 +27,14 @@ func TestAnalyzeData(t *testing.T) {
 		{"lowercase url", "https://example.com", ModeByte},
 		{"emoji", "QR📱CODE", ModeByte},
 		{"special not in set", "test@email.com", ModeByte},
+
+		// Kanji mode - only Shift_JIS double-byte characters
+		{"kanji", "漢字", ModeKanji},
+		{"kana", "こんにちは", ModeKanji},
+		{"kanji with ascii", "漢字A", ModeByte},
+		{"half-width kana", "ｱｲｳ", ModeByte},
+		{"hangul", "한국어", ModeByte},
+		{"empty", "", ModeNumeric},
 	}
 
 	for _, tt := range tests {
@@ -38,3 +46,44 @@ func TestAnalyzeData(t *testing.T) {
 		})
 	}
 }
+
+func TestShiftJIS(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want uint16
+	}{
+		{'漢', 0x8ABF},
+		{'字', 0x8E9A},
+		{'点', 0x935F},
+		{'茗', 0xE4AA},
+		{'　', 0x8140},
+	}
+	for _, tt := range tests {
+		got, ok := ShiftJIS(tt.r)
+		if !ok || got != tt.want {
+			t.Errorf("ShiftJIS(%q) = %#x, %v; want %#x", tt.r, got, ok, tt.want)
+		}
+	}
+	if _, ok := ShiftJIS('a'); ok {
+		t.Error("expected single-byte characters to be rejected")
+	}
+}
+
+func TestModeDataBits(t *testing.T) {
+	tests := []struct {
+		mode Mode
+		n    int
+		want int
+	}{
+		{ModeNumeric, 8, 27},
+		{ModeNumeric, 7, 24},
+		{ModeAlphanumeric, 5, 28},
+		{ModeByte, 3, 24},
+		{ModeKanji, 2, 26},
+	}
+	for _, tt := range tests {
+		if got := tt.mode.DataBits(tt.n); got != tt.want {
+			t.Errorf("%v.DataBits(%d) = %d, want %d", tt.mode, tt.n, got, tt.want)
+		}
+	}
+}
@@ -1,5 +1,11 @@
 package encoder
 
+import "errors"
+
+// ErrTooManyErrors is returned when a block holds more errors than its
+// error correction codewords can correct.
+var ErrTooManyErrors = errors.New("too many errors to correct")
+
 // GF256 implements Galois Field arithmetic for Reed-Solomon encoding.
 // QR codes use GF(2^8) with primitive polynomial x^8 + x^4 + x^3 + x^2 + 1.
 
@@ -105,3 +111,166 @@ func ReedSolomonEncode(data []byte, eccCount int) []byte {
 
 	return result
 }
+
+// ReedSolomonDecode corrects a block of data codewords followed by
+// eccCount ECC codewords in place and returns the positions it changed.
+//
+// Erasures are positions known to be unreliable, such as modules hidden
+// behind a logo. Correcting e unknown errors and f erasures needs
+// 2e+f <= eccCount ECC codewords; beyond that ErrTooManyErrors is
+// returned and the block is left unchanged.
+func ReedSolomonDecode(block []byte, eccCount int, erasures []int) ([]int, error) {
+	n := len(block)
+	for _, i := range erasures {
+		if i < 0 || i >= n {
+			return nil, errors.New("erasure position out of range")
+		}
+	}
+	if len(erasures) > eccCount {
+		return nil, ErrTooManyErrors
+	}
+
+	// 1. Syndromes: the block evaluated at the generator's roots
+	syndromes := make([]byte, eccCount)
+	clean := true
+	for j := range syndromes {
+		syndromes[j] = polyEvalHigh(block, expTable[j])
+		if syndromes[j] != 0 {
+			clean = false
+		}
+	}
+	if clean {
+		return nil, nil
+	}
+
+	// 2. Erasure locator: a root at the inverse location of each erasure.
+	// Codeword i is the coefficient of x^(n-1-i).
+	locator := []byte{1}
+	seen := make(map[int]bool, len(erasures))
+	for _, i := range erasures {
+		if seen[i] {
+			continue
+		}
+		seen[i] = true
+		locator = polyMul(locator, []byte{1, expTable[n-1-i]})
+	}
+	known := len(locator) - 1
+
+	// 3. Berlekamp-Massey on the Forney syndromes, S * erasure locator,
+	// finds the locator of the remaining errors
+	forney := polyMul(syndromes, locator)[:eccCount]
+	errLocator, errs := berlekampMassey(forney[known:])
+	if errs*2+known > eccCount {
+		return nil, ErrTooManyErrors
+	}
+	locator = polyMul(locator, errLocator)
+
+	// 4. Chien search: the locator's roots are the inverse locations
+	var positions []int
+	for i := range n {
+		if polyEval(locator, expTable[(255-(n-1-i))%255]) == 0 {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) != len(locator)-1 {
+		return nil, ErrTooManyErrors
+	}
+
+	// 5. Forney: error value = X * Omega(X^-1) / Lambda'(X^-1), where
+	// Omega = S * Lambda mod x^eccCount
+	omega := polyMul(syndromes, locator)[:eccCount]
+	// The formal derivative keeps the odd powers
+	derivative := make([]byte, len(locator)-1)
+	for i := 1; i < len(locator); i += 2 {
+		derivative[i-1] = locator[i]
+	}
+
+	values := make([]byte, len(positions))
+	for k, i := range positions {
+		power := n - 1 - i
+		xInv := expTable[(255-power)%255]
+		den := polyEval(derivative, xInv)
+		if den == 0 {
+			return nil, ErrTooManyErrors
+		}
+		values[k] = gfMul(expTable[power], gfDiv(polyEval(omega, xInv), den))
+	}
+
+	// Erasures that held the right value are not corrections
+	corrected := positions[:0]
+	for k, i := range positions {
+		if values[k] != 0 {
+			block[i] ^= values[k]
+			corrected = append(corrected, i)
+		}
+	}
+	return corrected, nil
+}
+
+// berlekampMassey returns the shortest error locator polynomial
+// (coefficients low to high) that generates the syndromes, and its
+// degree, the number of errors.
+func berlekampMassey(syndromes []byte) ([]byte, int) {
+	locator := make([]byte, len(syndromes)+1)
+	locator[0] = 1
+	prev := make([]byte, len(syndromes)+1)
+	prev[0] = 1
+
+	degree, shift := 0, 1
+	prevDiscrepancy := byte(1)
+	for n := range syndromes {
+		// Discrepancy between the syndrome and the current prediction
+		d := syndromes[n]
+		for i := 1; i <= degree; i++ {
+			d ^= gfMul(locator[i], syndromes[n-i])
+		}
+		if d == 0 {
+			shift++
+			continue
+		}
+
+		saved := append([]byte(nil), locator...)
+		coef := gfDiv(d, prevDiscrepancy)
+		for i := 0; i+shift < len(locator); i++ {
+			locator[i+shift] ^= gfMul(coef, prev[i])
+		}
+		if 2*degree <= n {
+			degree = n + 1 - degree
+			prev = saved
+			prevDiscrepancy = d
+			shift = 1
+		} else {
+			shift++
+		}
+	}
+	return locator[:degree+1], degree
+}
+
+// polyMul multiplies two polynomials with coefficients low to high.
+func polyMul(a, b []byte) []byte {
+	product := make([]byte, len(a)+len(b)-1)
+	for i, x := range a {
+		for j, y := range b {
+			product[i+j] ^= gfMul(x, y)
+		}
+	}
+	return product
+}
+
+// polyEval evaluates a polynomial with coefficients low to high at x.
+func polyEval(poly []byte, x byte) byte {
+	var y byte
+	for i := len(poly) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ poly[i]
+	}
+	return y
+}
+
+// polyEvalHigh evaluates a polynomial with coefficients high to low at x.
+func polyEvalHigh(poly []byte, x byte) byte {
+	var y byte
+	for _, c := range poly {
+		y = gfMul(y, x) ^ c
+	}
+	return y
+}
@@ -2,6 +2,7 @@ package encoder
 
 import (
 	"bytes"
+	"slices"
 	"testing"
 )
 
@@ -192,3 +193,56 @@ func TestReedSolomonEncodeVersion1L(t *testing.T) {
 		t.Error("ReedSolomonEncode produced all zeros")
 	}
 }
+
+func TestReedSolomonDecode(t *testing.T) {
+	data := []byte("Reed-Solomon round trip")
+	const eccCount = 10
+	block := append(append([]byte(nil), data...), ReedSolomonEncode(data, eccCount)...)
+
+	tests := []struct {
+		name      string
+		errors    []int // Positions to corrupt
+		erasures  []int // Positions flagged as unreliable
+		corrected []int
+		wantErr   bool
+	}{
+		{"no errors", nil, nil, nil, false},
+		{"one data error", []int{3}, nil, []int{3}, false},
+		{"one ecc error", []int{len(block) - 1}, nil, []int{len(block) - 1}, false},
+		{"max errors", []int{0, 7, 12, 22, 30}, nil, []int{0, 7, 12, 22, 30}, false},
+		{"too many errors", []int{0, 5, 7, 12, 22, 30}, nil, nil, true},
+		{"max erasures", []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, false},
+		{"errors and erasures", []int{0, 9, 20, 25}, []int{20, 25, 26, 27, 28, 29}, []int{0, 9, 20, 25}, false},
+		{"too many erasures", nil, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, nil, true},
+		{"erasures beyond budget", []int{0, 9, 14}, []int{20, 25, 26, 27, 28}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corrupted := append([]byte(nil), block...)
+			for i, pos := range tt.errors {
+				corrupted[pos] ^= byte(0x5A + i)
+			}
+			corrected, err := ReedSolomonDecode(corrupted, eccCount, tt.erasures)
+			if tt.wantErr {
+				if err == nil && bytes.Equal(corrupted, block) {
+					t.Error("expected an error beyond the correction capacity")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReedSolomonDecode() error = %v", err)
+			}
+			if !bytes.Equal(corrupted, block) {
+				t.Errorf("ReedSolomonDecode() = %X, want %X", corrupted, block)
+			}
+			if !slices.Equal(corrected, tt.corrected) {
+				t.Errorf("corrected positions = %v, want %v", corrected, tt.corrected)
+			}
+		})
+	}
+
+	if _, err := ReedSolomonDecode(append([]byte(nil), block...), eccCount, []int{len(block)}); err == nil {
+		t.Error("expected error for an erasure outside the block")
+	}
+}
@@ -0,0 +1,354 @@
+package encoder
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RMQRVersion identifies one of the 32 rMQR (rectangular Micro QR) sizes,
+// numbered from 1 (R7x43) to 32 (R17x139) by height, then width.
+type RMQRVersion int
+
+// rmqrInfo describes one rMQR size.
+type rmqrInfo struct {
+	height, width int
+	countBits     [4]int     // Character count bits for numeric, alnum, byte, Kanji
+	ecc           [2]ECCInfo // Levels M and H
+}
+
+// rmqrBlocks builds the ECCInfo of a level from its block groups.
+func rmqrBlocks(eccPerBlock int, groups ...BlockInfo) ECCInfo {
+	info := ECCInfo{ECCPerBlock: eccPerBlock, Group1: groups[0]}
+	if len(groups) > 1 {
+		info.Group2 = groups[1]
+	}
+	for _, g := range groups {
+		info.TotalCodewords += g.Count * g.TotalCodewords
+	}
+	return info
+}
+
+// rmqrTable holds the rMQR sizes indexed by version-1.
+var rmqrTable = [32]rmqrInfo{
+	{7, 43, [4]int{4, 3, 3, 2}, [2]ECCInfo{rmqrBlocks(7, BlockInfo{1, 13, 6}), rmqrBlocks(10, BlockInfo{1, 13, 3})}},
+	{7, 59, [4]int{5, 5, 4, 3}, [2]ECCInfo{rmqrBlocks(9, BlockInfo{1, 21, 12}), rmqrBlocks(14, BlockInfo{1, 21, 7})}},
+	{7, 77, [4]int{6, 5, 5, 4}, [2]ECCInfo{rmqrBlocks(12, BlockInfo{1, 32, 20}), rmqrBlocks(22, BlockInfo{1, 32, 10})}},
+	{7, 99, [4]int{7, 6, 5, 5}, [2]ECCInfo{rmqrBlocks(16, BlockInfo{1, 44, 28}), rmqrBlocks(30, BlockInfo{1, 44, 14})}},
+	{7, 139, [4]int{7, 6, 6, 5}, [2]ECCInfo{rmqrBlocks(24, BlockInfo{1, 68, 44}), rmqrBlocks(22, BlockInfo{2, 34, 12})}},
+	{9, 43, [4]int{5, 5, 4, 3}, [2]ECCInfo{rmqrBlocks(9, BlockInfo{1, 21, 12}), rmqrBlocks(14, BlockInfo{1, 21, 7})}},
+	{9, 59, [4]int{6, 5, 5, 4}, [2]ECCInfo{rmqrBlocks(12, BlockInfo{1, 33, 21}), rmqrBlocks(22, BlockInfo{1, 33, 11})}},
+	{9, 77, [4]int{7, 6, 5, 5}, [2]ECCInfo{rmqrBlocks(18, BlockInfo{1, 49, 31}), rmqrBlocks(16, BlockInfo{1, 24, 8}, BlockInfo{1, 25, 9})}},
+	{9, 99, [4]int{7, 6, 6, 5}, [2]ECCInfo{rmqrBlocks(24, BlockInfo{1, 66, 42}), rmqrBlocks(22, BlockInfo{2, 33, 11})}},
+	{9, 139, [4]int{8, 7, 6, 6}, [2]ECCInfo{rmqrBlocks(18, BlockInfo{1, 49, 31}, BlockInfo{1, 50, 32}), rmqrBlocks(22, BlockInfo{3, 33, 11})}},
+	{11, 27, [4]int{4, 4, 3, 2}, [2]ECCInfo{rmqrBlocks(8, BlockInfo{1, 15, 7}), rmqrBlocks(10, BlockInfo{1, 15, 5})}},
+	{11, 43, [4]int{6, 5, 5, 4}, [2]ECCInfo{rmqrBlocks(12, BlockInfo{1, 31, 19}), rmqrBlocks(20, BlockInfo{1, 31, 11})}},
+	{11, 59, [4]int{7, 6, 5, 5}, [2]ECCInfo{rmqrBlocks(16, BlockInfo{1, 47, 31}), rmqrBlocks(16, BlockInfo{1, 23, 7}, BlockInfo{1, 24, 8})}},
+	{11, 77, [4]int{7, 6, 6, 5}, [2]ECCInfo{rmqrBlocks(24, BlockInfo{1, 67, 43}), rmqrBlocks(22, BlockInfo{1, 33, 11}, BlockInfo{1, 34, 12})}},
+	{11, 99, [4]int{8, 7, 6, 6}, [2]ECCInfo{rmqrBlocks(16, BlockInfo{1, 44, 28}, BlockInfo{1, 45, 29}), rmqrBlocks(30, BlockInfo{1, 44, 14}, BlockInfo{1, 45, 15})}},
+	{11, 139, [4]int{8, 7, 7, 6}, [2]ECCInfo{rmqrBlocks(24, BlockInfo{2, 66, 42}), rmqrBlocks(30, BlockInfo{3, 44, 14})}},
+	{13, 27, [4]int{5, 5, 4, 3}, [2]ECCInfo{rmqrBlocks(9, BlockInfo{1, 21, 12}), rmqrBlocks(14, BlockInfo{1, 21, 7})}},
+	{13, 43, [4]int{6, 6, 5, 5}, [2]ECCInfo{rmqrBlocks(14, BlockInfo{1, 41, 27}), rmqrBlocks(28, BlockInfo{1, 41, 13})}},
+	{13, 59, [4]int{7, 6, 6, 5}, [2]ECCInfo{rmqrBlocks(22, BlockInfo{1, 60, 38}), rmqrBlocks(20, BlockInfo{2, 30, 10})}},
+	{13, 77, [4]int{7, 7, 6, 5}, [2]ECCInfo{rmqrBlocks(16, BlockInfo{1, 42, 26}, BlockInfo{1, 43, 27}), rmqrBlocks(28, BlockInfo{1, 42, 14}, BlockInfo{1, 43, 15})}},
+	{13, 99, [4]int{8, 7, 7, 6}, [2]ECCInfo{rmqrBlocks(20, BlockInfo{1, 56, 36}, BlockInfo{1, 57, 37}), rmqrBlocks(26, BlockInfo{1, 37, 11}, BlockInfo{2, 38, 12})}},
+	{13, 139, [4]int{8, 8, 7, 7}, [2]ECCInfo{rmqrBlocks(20, BlockInfo{2, 55, 35}, BlockInfo{1, 56, 36}), rmqrBlocks(28, BlockInfo{2, 41, 13}, BlockInfo{2, 42, 14})}},
+	{15, 43, [4]int{7, 6, 6, 5}, [2]ECCInfo{rmqrBlocks(18, BlockInfo{1, 51, 33}), rmqrBlocks(18, BlockInfo{1, 25, 7}, BlockInfo{1, 26, 8})}},
+	{15, 59, [4]int{7, 7, 6, 5}, [2]ECCInfo{rmqrBlocks(26, BlockInfo{1, 74, 48}), rmqrBlocks(24, BlockInfo{2, 37, 13})}},
+	{15, 77, [4]int{8, 7, 7, 6}, [2]ECCInfo{rmqrBlocks(18, BlockInfo{1, 51, 33}, BlockInfo{1, 52, 34}), rmqrBlocks(24, BlockInfo{2, 34, 10}, BlockInfo{1, 35, 11})}},
+	{15, 99, [4]int{8, 7, 7, 6}, [2]ECCInfo{rmqrBlocks(24, BlockInfo{2, 68, 44}), rmqrBlocks(22, BlockInfo{4, 34, 12})}},
+	{15, 139, [4]int{9, 8, 7, 7}, [2]ECCInfo{rmqrBlocks(24, BlockInfo{2, 66, 42}, BlockInfo{1, 67, 43}), rmqrBlocks(28, BlockInfo{1, 39, 11}, BlockInfo{4, 40, 12})}},
+	{17, 43, [4]int{7, 6, 6, 5}, [2]ECCInfo{rmqrBlocks(22, BlockInfo{1, 61, 39}), rmqrBlocks(20, BlockInfo{1, 30, 10}, BlockInfo{1, 31, 11})}},
+	{17, 59, [4]int{8, 7, 6, 6}, [2]ECCInfo{rmqrBlocks(16, BlockInfo{2, 44, 28}), rmqrBlocks(30, BlockInfo{2, 44, 14})}},
+	{17, 77, [4]int{8, 7, 7, 6}, [2]ECCInfo{rmqrBlocks(22, BlockInfo{2, 61, 39}), rmqrBlocks(28, BlockInfo{1, 40, 12}, BlockInfo{2, 41, 13})}},
+	{17, 99, [4]int{8, 8, 7, 6}, [2]ECCInfo{rmqrBlocks(20, BlockInfo{2, 53, 33}, BlockInfo{1, 54, 34}), rmqrBlocks(26, BlockInfo{4, 40, 14})}},
+	{17, 139, [4]int{9, 8, 8, 7}, [2]ECCInfo{rmqrBlocks(20, BlockInfo{4, 58, 38}), rmqrBlocks(26, BlockInfo{2, 38, 12}, BlockInfo{4, 39, 13})}},
+}
+
+// rmqrAlignmentColumns holds the center columns of the alignment
+// patterns by symbol width.
+var rmqrAlignmentColumns = map[int][]int{
+	27:  nil,
+	43:  {21},
+	59:  {19, 39},
+	77:  {25, 51},
+	99:  {23, 49, 75},
+	139: {27, 55, 83, 111},
+}
+
+// Width returns the number of modules per row.
+func (v RMQRVersion) Width() int {
+	return rmqrTable[v-1].width
+}
+
+// Height returns the number of modules per column.
+func (v RMQRVersion) Height() int {
+	return rmqrTable[v-1].height
+}
+
+// String returns the size designation, e.g. "R7x43".
+func (v RMQRVersion) String() string {
+	return fmt.Sprintf("R%dx%d", v.Height(), v.Width())
+}
+
+// rmqrECCInfo returns the error correction of a version at level M or H.
+func rmqrECCInfo(version RMQRVersion, ecl ErrorCorrectionLevel) ECCInfo {
+	if ecl == LevelH {
+		return rmqrTable[version-1].ecc[1]
+	}
+	return rmqrTable[version-1].ecc[0]
+}
+
+// rmqrHeader returns the segment headers of an rMQR version: 3-bit mode
+// indicators and per-size character counts.
+func rmqrHeader(version RMQRVersion) segmentHeader {
+	counts := rmqrTable[version-1].countBits
+	return segmentHeader{
+		modeBits: 3,
+		indicator: func(m Mode) uint {
+			switch m {
+			case ModeNumeric:
+				return 1
+			case ModeAlphanumeric:
+				return 2
+			case ModeByte:
+				return 3
+			case ModeKanji:
+				return 4
+			case ModeFNC1First:
+				return 5
+			case ModeFNC1Second:
+				return 6
+			}
+			return 7 // ECI
+		},
+		countBits: func(m Mode) int {
+			switch m {
+			case ModeNumeric:
+				return counts[0]
+			case ModeAlphanumeric:
+				return counts[1]
+			case ModeByte:
+				return counts[2]
+			case ModeKanji:
+				return counts[3]
+			}
+			return 0
+		},
+	}
+}
+
+// fitRMQRSegments splits data into segments, after headers, for the
+// smallest rMQR symbol, by area, from version lo to hi that holds them
+// at the given level and is at most maxHeight modules high. A maxHeight
+// of 0 allows any height.
+func fitRMQRSegments(data string, ecl ErrorCorrectionLevel, cs *Charset, headers []Segment, maxHeight int, lo, hi RMQRVersion) ([]Segment, RMQRVersion, error) {
+	if ecl != LevelM && ecl != LevelH {
+		return nil, 0, errors.New("rMQR supports error correction levels M and H only")
+	}
+
+	var best RMQRVersion
+	var bestSegs []Segment
+	for v := lo; v <= hi; v++ {
+		if maxHeight > 0 && v.Height() > maxHeight {
+			continue
+		}
+		if best != 0 && v.Width()*v.Height() >= best.Width()*best.Height() {
+			continue
+		}
+		h := rmqrHeader(v).withHeaders(headers)
+		segs, err := splitSegments(data, h, cs)
+		if err != nil {
+			return nil, 0, err
+		}
+		segs = append(headers[:len(headers):len(headers)], segs...)
+		capacity := rmqrECCInfo(v, ecl).DataCapacity() * 8
+		if bits := segmentsBits(segs, h); bits >= 0 && bits <= capacity {
+			best, bestSegs = v, segs
+		}
+	}
+	if best == 0 {
+		if maxHeight > 0 {
+			return nil, 0, fmt.Errorf("rMQR up to %d modules high: %w", maxHeight, ErrDataTooLong)
+		}
+		return nil, 0, fmt.Errorf("rMQR: %w", ErrDataTooLong)
+	}
+	return bestSegs, best, nil
+}
+
+// NewRMQRMatrix creates an rMQR matrix with its function patterns: the
+// finder at the left, the finder sub-pattern at the bottom right, corner
+// patterns, alignment patterns joined by vertical timing lines, timing
+// along all edges and the reserved format information areas.
+func NewRMQRMatrix(version RMQRVersion) *Matrix {
+	width, height := version.Width(), version.Height()
+	m := newMatrix(width, height)
+
+	// Finder and its separator
+	m.placeFinder(0, 0)
+	for i := 0; i < min(8, height); i++ {
+		m.Set(7, i, Module{Type: ModuleFinderSeparator, Reserved: true})
+	}
+	if height >= 9 {
+		for i := 0; i < 8; i++ {
+			m.Set(i, 7, Module{Type: ModuleFinderSeparator, Reserved: true})
+		}
+	}
+
+	// Finder sub-pattern, styled like an alignment pattern
+	m.placeAlignmentPattern(width-3, height-3)
+
+	// Corner finder patterns
+	corner := func(x, y int, dark bool) {
+		m.Set(x, y, Module{Dark: dark, Type: ModuleFinder, Reserved: true})
+	}
+	corner(width-1, 0, true)
+	corner(width-2, 0, true)
+	corner(width-1, 1, true)
+	corner(width-2, 1, false)
+	if height >= 9 {
+		corner(0, height-1, true)
+		corner(1, height-1, true)
+		corner(2, height-1, true)
+	}
+	if height >= 11 {
+		corner(0, height-2, true)
+		corner(1, height-2, false)
+	}
+
+	// Alignment patterns at the top and bottom edges: a dark ring
+	// around a light center
+	columns := rmqrAlignmentColumns[width]
+	for _, cx := range columns {
+		for _, cy := range []int{1, height - 2} {
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					m.Set(cx+dx, cy+dy, Module{Dark: dx != 0 || dy != 0, Type: ModuleAlignment, Reserved: true})
+				}
+			}
+		}
+	}
+
+	// Timing patterns fill the remaining edges and alignment columns
+	timing := func(x, y int, dark bool) {
+		if !m.Get(x, y).Reserved {
+			m.Set(x, y, Module{Dark: dark, Type: ModuleTiming, Reserved: true})
+		}
+	}
+	for x := 0; x < width; x++ {
+		timing(x, 0, x%2 == 0)
+		timing(x, height-1, x%2 == 0)
+	}
+	for _, x := range append([]int{0, width - 1}, columns...) {
+		for y := 0; y < height; y++ {
+			timing(x, y, y%2 == 0)
+		}
+	}
+
+	// Format information next to the finder and the sub-pattern
+	for i := 0; i < 18; i++ {
+		m.Set(8+i/5, 1+i%5, Module{Type: ModuleFormatInfo, Reserved: true})
+	}
+	for i := 0; i < 15; i++ {
+		m.Set(width-8+i/5, height-6+i%5, Module{Type: ModuleFormatInfo, Reserved: true})
+	}
+	for i := 0; i < 3; i++ {
+		m.Set(width-5+i, height-6, Module{Type: ModuleFormatInfo, Reserved: true})
+	}
+	return m
+}
+
+// rMQR format info mask patterns for XOR, one per side
+const (
+	rmqrFormatMaskFinder    = 0x1FAB2 // 011111101010110010
+	rmqrFormatMaskSubFinder = 0x20A7B // 100000101001111011
+)
+
+// RMQRFormatInfo encodes the level and version: 6 data bits plus 12 BCH
+// bits, before the per-side masks.
+func RMQRFormatInfo(ecl ErrorCorrectionLevel, version RMQRVersion) uint32 {
+	data := uint32(version - 1)
+	if ecl == LevelH {
+		data |= 1 << 5
+	}
+
+	// BCH(18,6) with generator x^12 + x^11 + x^10 + x^9 + x^8 + x^5 + x^2 + 1
+	info := data << 12
+	for i := 5; i >= 0; i-- {
+		if info&(1<<(i+12)) != 0 {
+			info ^= 0x1F25 << i
+		}
+	}
+	return data<<12 | info
+}
+
+// PlaceRMQRFormatInfo places both masked copies of the format info.
+func PlaceRMQRFormatInfo(matrix *Matrix, info uint32) {
+	width, height := matrix.Width(), matrix.Height()
+	set := func(x, y int, bits uint32, i int) {
+		matrix.Set(x, y, Module{Dark: (bits>>i)&1 == 1, Type: ModuleFormatInfo, Reserved: true})
+	}
+
+	finder := info ^ rmqrFormatMaskFinder
+	for i := 0; i < 18; i++ {
+		set(8+i/5, 1+i%5, finder, i)
+	}
+
+	sub := info ^ rmqrFormatMaskSubFinder
+	for i := 0; i < 15; i++ {
+		set(width-8+i/5, height-6+i%5, sub, i)
+	}
+	for i := 15; i < 18; i++ {
+		set(width-5+i-15, height-6, sub, i)
+	}
+}
+
+// encodeRMQR performs the rMQR encoding process.
+func (e *Encoder) encodeRMQR() (*Matrix, error) {
+	if e.sequence != nil {
+		return nil, errors.New("rMQR does not support Structured Append")
+	}
+
+	// 1-2. Split data into segments and pick the smallest rectangle
+	lo, hi, err := e.versions(32)
+	if err != nil {
+		return nil, fmt.Errorf("rMQR: %w", err)
+	}
+	segments, version, err := fitRMQRSegments(e.data, e.errorCorrection, e.charset, e.headers(), e.maxHeight, RMQRVersion(lo), RMQRVersion(hi))
+	if e.tooLong(err) {
+		return nil, fmt.Errorf("rMQR %s: %w", RMQRVersion(e.fixedVersion), ErrVersionTooSmall)
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.segments = segments
+	e.version = int(version)
+	e.usedBits = segmentsBits(segments, rmqrHeader(version))
+	e.level = e.boostLevel(e.usedBits, LevelH, func(l ErrorCorrectionLevel) int {
+		if l != LevelH {
+			return -1
+		}
+		return rmqrECCInfo(version, l).DataCapacity() * 8
+	})
+
+	// 3. Encode data with a 3-bit terminator
+	eccInfo := rmqrECCInfo(version, e.level)
+	e.layout = Layout{ECC: eccInfo, shortBits: -1}
+	bs := encodePadded(segments, rmqrHeader(version), 3, eccInfo.DataCapacity()*8)
+
+	// 4-5. Generate error correction and interleave blocks
+	dataBlocks, eccBlocks := GenerateECC(bs.Bytes(), eccInfo)
+	finalData := InterleaveBlocks(dataBlocks, eccBlocks)
+
+	// 6-7. Create matrix and place data
+	matrix := NewRMQRMatrix(version)
+	matrix.PlaceData(finalData)
+
+	// 8. rMQR always uses mask (y/2 + x/3) mod 2
+	ApplyMask(matrix, Mask4)
+	e.mask = int(Mask4)
+
+	// 9. Add format information
+	PlaceRMQRFormatInfo(matrix, RMQRFormatInfo(e.level, version))
+
+	return matrix, nil
+}
@@ -0,0 +1,100 @@
+package encoder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRMQRCodewords(t *testing.T) {
+	// The codeword table must fill the modules left by the function
+	// patterns, with fewer than 8 remainder modules
+	for v := RMQRVersion(1); v <= 32; v++ {
+		m := NewRMQRMatrix(v)
+		free := 0
+		for y := 0; y < m.Height(); y++ {
+			for x := 0; x < m.Width(); x++ {
+				if !m.Get(x, y).Reserved {
+					free++
+				}
+			}
+		}
+		for _, ecl := range []ErrorCorrectionLevel{LevelM, LevelH} {
+			if total := rmqrECCInfo(v, ecl).TotalCodewords; free/8 != total {
+				t.Errorf("%v: %d free modules for %d codewords", v, free, total)
+			}
+		}
+	}
+}
+
+func TestRMQRVersionString(t *testing.T) {
+	if got := RMQRVersion(1).String(); got != "R7x43" {
+		t.Errorf("version 1 = %s, want R7x43", got)
+	}
+	if got := RMQRVersion(32).String(); got != "R17x139" {
+		t.Errorf("version 32 = %s, want R17x139", got)
+	}
+}
+
+func TestFitRMQRSegments(t *testing.T) {
+	tests := []struct {
+		data      string
+		ecl       ErrorCorrectionLevel
+		maxHeight int
+		want      string
+	}{
+		{"123456789012", LevelM, 0, "R11x27"}, // Smaller than R7x43
+		{"123456789012", LevelM, 7, "R7x43"},
+		{"1234567890123", LevelM, 7, "R7x59"},
+		{"12345678901234567", LevelM, 0, "R13x27"},
+		{"12345678901234567", LevelM, 9, "R9x43"},
+		{strings.Repeat("1", 361), LevelM, 0, "R17x139"},
+	}
+	for _, tt := range tests {
+		_, v, err := fitRMQRSegments(tt.data, tt.ecl, nil, nil, tt.maxHeight, 1, 32)
+		if err != nil || v.String() != tt.want {
+			t.Errorf("fitRMQRSegments(%d digits, max height %d) = %v, %v; want %s", len(tt.data), tt.maxHeight, v, err, tt.want)
+		}
+	}
+
+	if _, _, err := fitRMQRSegments(strings.Repeat("1", 362), LevelM, nil, nil, 0, 1, 32); !errors.Is(err, ErrDataTooLong) {
+		t.Errorf("expected ErrDataTooLong, got %v", err)
+	}
+	if _, _, err := fitRMQRSegments(strings.Repeat("1", 200), LevelM, nil, nil, 7, 1, 32); !errors.Is(err, ErrDataTooLong) {
+		t.Errorf("expected ErrDataTooLong under the height limit, got %v", err)
+	}
+	if _, _, err := fitRMQRSegments("1", LevelL, nil, nil, 0, 1, 32); err == nil {
+		t.Error("expected error for level L")
+	}
+}
+
+func TestRMQRFormatInfo(t *testing.T) {
+	// R7x43-M has no data bits set, so no BCH bits either
+	if got := RMQRFormatInfo(LevelM, 1); got != 0 {
+		t.Errorf("RMQRFormatInfo(M, R7x43) = %#x, want 0", got)
+	}
+	if got := RMQRFormatInfo(LevelH, 32) >> 12; got != 1<<5|31 {
+		t.Errorf("RMQRFormatInfo(H, R17x139) data bits = %06b", got)
+	}
+}
+
+func TestEncodeRMQR(t *testing.T) {
+	enc := New("https://example.com/lot/4711", LevelM)
+	enc.SetSymbol(SymbolRMQR)
+	enc.SetMaxHeight(11)
+	matrix, err := enc.Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if matrix.Height() > 11 || matrix.Width() <= matrix.Height() {
+		t.Errorf("expected a wide symbol at most 11 high, got %dx%d", matrix.Width(), matrix.Height())
+	}
+	if f := matrix.Finders(); len(f) != 1 || f[0] != [2]int{0, 0} {
+		t.Errorf("expected one finder at the origin, got %v", f)
+	}
+	// The finder sub-pattern sits in the bottom-right corner
+	w, h := matrix.Width(), matrix.Height()
+	if a := matrix.Alignments(); len(a) != 1 || a[0] != [2]int{w - 3, h - 3} {
+		t.Errorf("expected the sub-pattern at (%d, %d), got %v", w-3, h-3, a)
+	}
+}
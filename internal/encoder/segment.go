@@ -0,0 +1,294 @@
+package encoder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Segment is a run of data encoded in a single mode, with its own mode
+// indicator and character count. Byte mode data holds the bytes in the
+// segment's character set. ECI, Structured Append and FNC1 segments are
+// headers without data.
+type Segment struct {
+	Mode        Mode
+	Data        string
+	ECI         int              // ECI assignment number, for ModeECI
+	Append      StructuredAppend // Sequence position, for ModeStructuredAppend
+	Application int              // Application indicator, for ModeFNC1Second
+}
+
+// segmentHeader describes the segment headers of one symbol version:
+// Micro QR and rMQR use shorter mode indicators and counts than QR.
+type segmentHeader struct {
+	modeBits  int             // Length of the mode indicator
+	indicator func(Mode) uint // Mode indicator value
+	countBits func(Mode) int  // Length of the character count, 0 if the mode is unavailable
+	fnc1      bool            // Data follows an FNC1 header; see fnc1Separator
+}
+
+// withHeaders returns h in FNC1 mode if headers include an FNC1 header.
+func (h segmentHeader) withHeaders(headers []Segment) segmentHeader {
+	for _, s := range headers {
+		if s.Mode == ModeFNC1First || s.Mode == ModeFNC1Second {
+			h.fnc1 = true
+		}
+	}
+	return h
+}
+
+// qrHeader returns the segment headers of a QR Code version.
+func qrHeader(version Version) segmentHeader {
+	return segmentHeader{
+		modeBits:  4,
+		indicator: func(m Mode) uint { return uint(m.ModeIndicator()) },
+		countBits: func(m Mode) int { return m.CharCountBits(version) },
+	}
+}
+
+// Bits returns the encoded length of the segment at the given version,
+// including its header.
+func (s Segment) Bits(version Version) int {
+	return s.bits(qrHeader(version))
+}
+
+func (s Segment) bits(h segmentHeader) int {
+	switch s.Mode {
+	case ModeECI:
+		return h.modeBits + eciBits(s.ECI)
+	case ModeStructuredAppend:
+		return h.modeBits + 16
+	case ModeFNC1First:
+		return h.modeBits
+	case ModeFNC1Second:
+		return h.modeBits + 8
+	}
+	return h.modeBits + h.countBits(s.Mode) + s.Mode.DataBits(s.Mode.CharCount(s.Data))
+}
+
+// fits reports whether the character count fits the count indicator.
+func (s Segment) fits(h segmentHeader) bool {
+	if s.Mode.header() {
+		return true
+	}
+	n := h.countBits(s.Mode)
+	return n > 0 && s.Mode.CharCount(s.Data) < 1<<n
+}
+
+// SegmentsBits returns the total encoded length of segs at the given
+// version, or -1 if a segment is too long for its count indicator.
+func SegmentsBits(segs []Segment, version Version) int {
+	return segmentsBits(segs, qrHeader(version))
+}
+
+func segmentsBits(segs []Segment, h segmentHeader) int {
+	total := 0
+	for _, s := range segs {
+		if !s.fits(h) {
+			return -1
+		}
+		total += s.bits(h)
+	}
+	return total
+}
+
+// versionRanges groups versions that share character count lengths, so
+// one segmentation is optimal for the whole range.
+var versionRanges = [][2]Version{{1, 9}, {10, 26}, {27, 40}}
+
+// DetermineSegments splits data into segments and finds the minimum
+// version that holds them, ECI header included, at the given error
+// correction level. A nil charset writes byte mode data as UTF-8
+// without an ECI header.
+func DetermineSegments(data string, ecl ErrorCorrectionLevel, cs *Charset) ([]Segment, Version, error) {
+	return fitSegments(data, ecl, cs, nil, 1, 40)
+}
+
+// fitSegments is DetermineSegments with header segments placed before
+// the data and only versions lo to hi considered.
+func fitSegments(data string, ecl ErrorCorrectionLevel, cs *Charset, headers []Segment, lo, hi Version) ([]Segment, Version, error) {
+	for _, r := range versionRanges {
+		if r[1] < lo || r[0] > hi {
+			continue
+		}
+		h := qrHeader(r[1]).withHeaders(headers)
+		segs, err := splitSegments(data, h, cs)
+		if err != nil {
+			return nil, 0, err
+		}
+		segs = append(headers[:len(headers):len(headers)], segs...)
+		bits := segmentsBits(segs, h)
+		if bits < 0 {
+			continue
+		}
+		for v := max(r[0], lo); v <= min(r[1], hi); v++ {
+			if bits <= GetECCInfo(v, ecl).DataCapacity()*8 {
+				return segs, v, nil
+			}
+		}
+	}
+	return nil, 0, ErrDataTooLong
+}
+
+// SplitSegments divides data into the sequence of mode segments with the
+// smallest encoded length at the given version. Costs are tracked in
+// sixths of a bit so numeric (10/3) and alphanumeric (11/2) characters
+// stay integral; each mode switch costs a full header.
+//
+// Byte mode data is converted to cs, and an ECI segment for cs leads
+// the result. A nil charset keeps UTF-8 without an ECI header.
+func SplitSegments(data string, version Version, cs *Charset) ([]Segment, error) {
+	return splitSegments(data, qrHeader(version), cs)
+}
+
+// errUnavailableMode is returned by splitSegments when a character needs
+// a mode the symbol version does not have.
+var errUnavailableMode = errors.New("character needs an unavailable mode")
+
+// splitSegments is SplitSegments for any segment header layout. Modes
+// the layout lacks are not used.
+func splitSegments(data string, h segmentHeader, cs *Charset) ([]Segment, error) {
+	if cs == nil {
+		cs = &Charset{appendRune: appendUTF8}
+	}
+
+	// Decode by hand so invalid UTF-8 survives as single bytes; bytes
+	// holds each rune's encoding in cs, nil if it has none
+	var runes []rune
+	var bytes [][]byte
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRuneInString(data[i:])
+		b, ok := cs.appendRune(nil, r, data[i:i+size])
+		if !ok {
+			b = nil
+			if !isKanji(r) {
+				return nil, fmt.Errorf("character %q cannot be encoded in %s", r, cs.Name)
+			}
+		}
+		runes = append(runes, r)
+		bytes = append(bytes, b)
+		i += size
+	}
+	if len(runes) == 0 {
+		return []Segment{{Mode: ModeNumeric}}, nil
+	}
+
+	modes := []Mode{ModeNumeric, ModeAlphanumeric, ModeByte, ModeKanji}
+	const inf = int(^uint(0) >> 2)
+
+	var header [4]int
+	for m, mode := range modes {
+		header[m] = (h.modeBits + h.countBits(mode)) * 6
+	}
+
+	// from[i][m] is the mode of rune i-1 on the cheapest path that
+	// encodes rune i in mode m
+	from := make([][4]int, len(runes))
+	var cost [4]int
+	for i, r := range runes {
+		var next [4]int
+		for m, mode := range modes {
+			next[m] = inf
+			c := charCost(mode, r, bytes[i], h.fnc1)
+			if c < 0 || h.countBits(mode) == 0 {
+				continue
+			}
+			if i == 0 {
+				next[m] = header[m] + c
+				continue
+			}
+			for p := range modes {
+				if cost[p] == inf {
+					continue
+				}
+				total := cost[p] + c
+				if p != m {
+					// Close the previous segment on a whole bit
+					total = (cost[p]+5)/6*6 + header[m] + c
+				}
+				if total < next[m] {
+					next[m], from[i][m] = total, p
+				}
+			}
+		}
+		if min(next[0], next[1], next[2], next[3]) == inf {
+			return nil, errUnavailableMode
+		}
+		cost = next
+	}
+
+	// Walk back from the cheapest final mode
+	best := 0
+	for m := range modes {
+		if (cost[m]+5)/6 < (cost[best]+5)/6 {
+			best = m
+		}
+	}
+	charModes := make([]int, len(runes))
+	for i := len(runes) - 1; i >= 0; i-- {
+		charModes[i] = best
+		best = from[i][best]
+	}
+
+	var segs []Segment
+	start, ascii := 0, true
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && charModes[i] == charModes[start] {
+			continue
+		}
+		mode := modes[charModes[start]]
+		var b []byte
+		for j := start; j < i; j++ {
+			switch {
+			case mode == ModeByte:
+				b = append(b, bytes[j]...)
+				ascii = ascii && runes[j] < utf8.RuneSelf
+			case mode == ModeAlphanumeric && h.fnc1 && runes[j] == '%':
+				b = append(b, "%%"...)
+			case mode == ModeAlphanumeric && h.fnc1 && runes[j] == fnc1Separator:
+				b = append(b, '%')
+			default:
+				b = utf8.AppendRune(b, runes[j])
+			}
+		}
+		segs = append(segs, Segment{Mode: mode, Data: string(b)})
+		start = i
+	}
+
+	if cs.Name != "" && !(cs.auto && ascii) {
+		segs = append([]Segment{{Mode: ModeECI, ECI: cs.ECI}}, segs...)
+	}
+	return segs, nil
+}
+
+// charCost returns the cost in sixths of a bit of r, whose byte mode
+// encoding is enc, or -1 if mode cannot encode r. In FNC1 mode the
+// separator is an alphanumeric character and % takes two.
+func charCost(mode Mode, r rune, enc []byte, fnc1 bool) int {
+	switch mode {
+	case ModeNumeric:
+		if r >= '0' && r <= '9' {
+			return 20
+		}
+	case ModeAlphanumeric:
+		if fnc1 && r == '%' {
+			return 66
+		}
+		if fnc1 && r == fnc1Separator {
+			return 33
+		}
+		if (r >= '0' && r <= '9') || (r >= 'A' && r <= 'Z') || strings.ContainsRune(" $%*+-./:", r) {
+			return 33
+		}
+	case ModeByte:
+		if enc != nil {
+			return len(enc) * 48
+		}
+	case ModeKanji:
+		if isKanji(r) {
+			return 78
+		}
+	}
+	return -1
+}
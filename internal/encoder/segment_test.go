@@ -0,0 +1,139 @@
+package encoder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitSegments(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []Segment
+	}{
+		{"empty", "", []Segment{{Mode: ModeNumeric, Data: ""}}},
+		{"single mode", "hello", []Segment{{Mode: ModeByte, Data: "hello"}}},
+		{"short digit run stays in byte mode", "abc12", []Segment{{Mode: ModeByte, Data: "abc12"}}},
+		{
+			"product url",
+			"https://example.com/ITEM/000123456789",
+			[]Segment{
+				{Mode: ModeByte, Data: "https://example.com"},
+				{Mode: ModeAlphanumeric, Data: "/ITEM/"},
+				{Mode: ModeNumeric, Data: "000123456789"},
+			},
+		},
+		{
+			"alphanumeric then numeric",
+			"HELLO123456789012",
+			[]Segment{{Mode: ModeAlphanumeric, Data: "HELLO"}, {Mode: ModeNumeric, Data: "123456789012"}},
+		},
+		{
+			"kanji around ascii",
+			"漢字abc漢字",
+			[]Segment{{Mode: ModeKanji, Data: "漢字"}, {Mode: ModeByte, Data: "abc"}, {Mode: ModeKanji, Data: "漢字"}},
+		},
+		{"invalid utf-8 is kept", "\xffabc", []Segment{{Mode: ModeByte, Data: "\xffabc"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitSegments(tt.data, 1, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitSegments(%q) = %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitSegmentsVersionRange(t *testing.T) {
+	// Longer count indicators make switching dearer at higher versions:
+	// 6 digits are split off at version 1 but not at version 27
+	data := "abcd123456efgh"
+	if segs, _ := SplitSegments(data, 1, nil); len(segs) != 3 {
+		t.Errorf("expected 3 segments at version 1, got %v", segs)
+	}
+	if segs, _ := SplitSegments(data, 27, nil); len(segs) != 1 {
+		t.Errorf("expected 1 segment at version 27, got %v", segs)
+	}
+}
+
+func TestSegmentsBitsMatchesEncoding(t *testing.T) {
+	segs, _ := SplitSegments("https://example.com/ITEM/000123456789", 1, nil)
+	bs := NewBitStream()
+	for _, seg := range segs {
+		encodeSegment(bs, seg, qrHeader(1))
+	}
+	if got := SegmentsBits(segs, 1); got != bs.Len() {
+		t.Errorf("SegmentsBits = %d, encoded %d bits", got, bs.Len())
+	}
+}
+
+func TestDetermineSegments(t *testing.T) {
+	// A single byte segment needs version 2-M; splitting off the digits
+	// fits version 1
+	data := "0123456789012345abc"
+	mode := AnalyzeData(data)
+	single, err := DetermineVersion(mode.CharCount(data), mode, LevelM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	segs, version, err := DetermineSegments(data, LevelM, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if single != 2 || version != 1 {
+		t.Errorf("expected versions 2 and 1, got %d and %d", single, version)
+	}
+	if len(segs) != 2 {
+		t.Errorf("expected 2 segments, got %v", segs)
+	}
+
+	if _, _, err := DetermineSegments(string(make([]byte, 3000)), LevelH, nil); err == nil {
+		t.Error("expected error for data too long")
+	}
+}
+
+func TestSplitSegmentsFNC1(t *testing.T) {
+	h := qrHeader(1).withHeaders([]Segment{{Mode: ModeFNC1First}})
+	tests := []struct {
+		name string
+		data string
+		want []Segment
+	}{
+		{"separator as percent", "10ABC\x1d21XYZ", []Segment{{Mode: ModeAlphanumeric, Data: "10ABC%21XYZ"}}},
+		{"escaped percent", "10A%B", []Segment{{Mode: ModeAlphanumeric, Data: "10A%%B"}}},
+		{"separator in bytes", "10abc\x1d21xyz", []Segment{{Mode: ModeByte, Data: "10abc\x1d21xyz"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitSegments(tt.data, h, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEncodeGS1(t *testing.T) {
+	enc := New("0109506000134352\x1d10ABC", LevelM)
+	enc.SetGS1(true)
+	if _, err := enc.Encode(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	segs := enc.Segments()
+	if len(segs) < 2 || segs[0].Mode != ModeFNC1First {
+		t.Errorf("expected an FNC1 header first, got %+v", segs)
+	}
+
+	enc.SetSymbol(SymbolMicro)
+	if _, err := enc.Encode(); err == nil {
+		t.Error("expected error for GS1 data in Micro QR")
+	}
+}
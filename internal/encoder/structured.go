@@ -0,0 +1,87 @@
+package encoder
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+// MaxStructuredAppend is the largest number of symbols in a Structured
+// Append sequence.
+const MaxStructuredAppend = 16
+
+// StructuredAppend places a symbol in a sequence of up to 16 symbols
+// that scanners join back into one message.
+type StructuredAppend struct {
+	Index  int  // Position in the sequence, from 0
+	Total  int  // Number of symbols in the sequence
+	Parity byte // Parity of the whole message, the same in every symbol
+}
+
+// Parity returns the Structured Append parity of data: the XOR of all
+// its bytes in cs, or in UTF-8 if cs is nil.
+func Parity(data string, cs *Charset) byte {
+	b := []byte(data)
+	if cs != nil {
+		if encoded, err := cs.Encode(data); err == nil {
+			b = encoded
+		}
+	}
+	var p byte
+	for _, c := range b {
+		p ^= c
+	}
+	return p
+}
+
+// SplitStructuredAppend divides data into the fewest parts, at most 16,
+// that each fit one symbol of at most maxVersion at the given error
+// correction level. Parts of a sequence leave room for the Structured
+// Append header; a single part is a standalone symbol. Parts are split
+// on character boundaries and are close to equal in length.
+func SplitStructuredAppend(data string, ecl ErrorCorrectionLevel, cs *Charset, maxVersion Version) ([]string, error) {
+	for n := 1; n <= MaxStructuredAppend; n++ {
+		parts := splitEven(data, n)
+		if parts == nil {
+			break
+		}
+
+		var headers []Segment
+		if n > 1 {
+			headers = []Segment{{Mode: ModeStructuredAppend, Append: StructuredAppend{Total: n}}}
+		}
+		fits := true
+		for _, part := range parts {
+			_, _, err := fitSegments(part, ecl, cs, headers, 1, maxVersion)
+			if errors.Is(err, ErrDataTooLong) {
+				fits = false
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if fits {
+			return parts, nil
+		}
+	}
+	return nil, errors.New("data too long for 16 QR symbols")
+}
+
+// splitEven cuts data into n parts of about the same byte length without
+// splitting characters, or returns nil if it has fewer than n characters.
+func splitEven(data string, n int) []string {
+	parts := make([]string, 0, n)
+	start := 0
+	for i := 1; i <= n; i++ {
+		end := len(data) * i / n
+		for end < len(data) && !utf8.RuneStart(data[end]) {
+			end++
+		}
+		if end <= start {
+			return nil
+		}
+		parts = append(parts, data[start:end])
+		start = end
+	}
+	return parts
+}
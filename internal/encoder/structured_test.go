@@ -0,0 +1,86 @@
+package encoder
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParity(t *testing.T) {
+	if got := Parity("ab", nil); got != 'a'^'b' {
+		t.Errorf("Parity(ab) = %#x, want %#x", got, 'a'^'b')
+	}
+	if got := Parity("", nil); got != 0 {
+		t.Errorf("Parity of empty data = %#x, want 0", got)
+	}
+	// The parity is over the bytes as encoded: é is 0xE9 in Latin-1 and
+	// 0xC3 0xA9 in UTF-8
+	latin1, _ := LookupCharset("iso-8859-1")
+	if got := Parity("é", latin1); got != 0xE9 {
+		t.Errorf("Parity(é) in Latin-1 = %#x, want 0xe9", got)
+	}
+	if got := Parity("é", nil); got != 0xC3^0xA9 {
+		t.Errorf("Parity(é) in UTF-8 = %#x, want %#x", got, 0xC3^0xA9)
+	}
+}
+
+func TestEncodeStructuredAppendHeader(t *testing.T) {
+	// Mode 0011, index 0001, total-1 0011, parity 10101011
+	bs := NewBitStream()
+	encodeSegment(bs, Segment{
+		Mode:   ModeStructuredAppend,
+		Append: StructuredAppend{Index: 1, Total: 4, Parity: 0xAB},
+	}, qrHeader(1))
+
+	want := []byte{0x31, 0x3A, 0xB0}
+	if got := bs.Bytes(); bs.Len() != 20 || !bytes.Equal(got, want) {
+		t.Errorf("got %d bits %x, want 20 bits %x", bs.Len(), got, want)
+	}
+}
+
+func TestSplitEven(t *testing.T) {
+	parts := splitEven("héllo wörld", 3)
+	if strings.Join(parts, "") != "héllo wörld" || len(parts) != 3 {
+		t.Errorf("unexpected parts %q", parts)
+	}
+	for _, p := range parts {
+		if !utf8.ValidString(p) {
+			t.Errorf("part %q splits a character", p)
+		}
+	}
+	if parts := splitEven("ab", 3); parts != nil {
+		t.Errorf("expected nil for too few characters, got %q", parts)
+	}
+}
+
+func TestSplitStructuredAppend(t *testing.T) {
+	parts, err := SplitStructuredAppend("hello", LevelM, nil, 40)
+	if err != nil || len(parts) != 1 {
+		t.Errorf("expected one standalone part, got %q, %v", parts, err)
+	}
+
+	// Version 40-L holds 2953 bytes, so 5000 need two symbols
+	data := strings.Repeat("abcdefghij", 500)
+	parts, err = SplitStructuredAppend(data, LevelL, nil, 40)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parts) != 2 || strings.Join(parts, "") != data {
+		t.Errorf("expected two parts joining to the data, got %d", len(parts))
+	}
+	for i, part := range parts {
+		enc := New(part, LevelL)
+		enc.SetStructuredAppend(&StructuredAppend{Index: i, Total: len(parts), Parity: Parity(data, nil)})
+		if _, err := enc.Encode(); err != nil {
+			t.Errorf("part %d: %v", i, err)
+		}
+	}
+
+	if _, err := SplitStructuredAppend(strings.Repeat("a", 17*2953), LevelL, nil, 40); err == nil {
+		t.Error("expected error for data beyond 16 symbols")
+	}
+	if _, err := SplitStructuredAppend("한국어", LevelL, CharsetASCII, 40); err == nil || strings.Contains(err.Error(), "16") {
+		t.Errorf("expected charset error, got %v", err)
+	}
+}
@@ -2,6 +2,13 @@ package encoder
 
 import "errors"
 
+// ErrDataTooLong is returned when data does not fit the largest version.
+var ErrDataTooLong = errors.New("data too long for any QR version")
+
+// ErrVersionTooSmall is returned when data does not fit the version set
+// with SetVersion.
+var ErrVersionTooSmall = errors.New("data too long for the fixed version")
+
 // Version represents a QR code version (1-40).
 // Version determines the size: (version * 4) + 17 modules per side.
 type Version int
@@ -12,19 +19,25 @@ func (v Version) Size() int {
 }
 
 // DetermineVersion finds the minimum version that can encode the data
-// with the given mode and error correction level.
+// with the given mode and error correction level. dataLen is the
+// character count as returned by Mode.CharCount.
 func DetermineVersion(dataLen int, mode Mode, ecl ErrorCorrectionLevel) (Version, error) {
 	for v := 1; v <= 40; v++ {
 		version := Version(v)
 		capacity := GetECCInfo(version, ecl).DataCapacity() * 8 // bits
 
+		// The count must fit in the character count indicator
+		if dataLen >= 1<<mode.CharCountBits(version) {
+			continue
+		}
+
 		// Calculate bits needed: mode indicator + char count + data
 		bitsNeeded := 4 + mode.CharCountBits(version)
-		bitsNeeded += int(float64(dataLen) * mode.BitsPerChar())
+		bitsNeeded += mode.DataBits(dataLen)
 
 		if bitsNeeded <= capacity {
 			return version, nil
 		}
 	}
-	return 0, errors.New("data too long for any QR version")
+	return 0, ErrDataTooLong
 }
@@ -0,0 +1,27 @@
+package encoder
+
+import "testing"
+
+func TestDetermineVersionBoundary(t *testing.T) {
+	// Odd alphanumeric counts and digits past a group of three take a
+	// fraction of a bit more than the average per character
+	tests := []struct {
+		name string
+		n    int
+		mode Mode
+		ecl  ErrorCorrectionLevel
+		want Version
+	}{
+		{"20 alphanumeric at M", 20, ModeAlphanumeric, LevelM, 1}, // 4+9+110 of 128 bits
+		{"21 alphanumeric at M", 21, ModeAlphanumeric, LevelM, 2}, // 4+9+116 of 128 bits
+		{"85 alphanumeric at H", 85, ModeAlphanumeric, LevelH, 7},
+		{"127 digits at L", 127, ModeNumeric, LevelL, 3},
+		{"128 digits at L", 128, ModeNumeric, LevelL, 4}, // 4+10+427 of 440 bits
+	}
+	for _, tt := range tests {
+		got, err := DetermineVersion(tt.n, tt.mode, tt.ecl)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: DetermineVersion() = %d, %v; want %d", tt.name, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// DrawImage scales src into the rectangle (x, y, w, h) of dst using bilinear
+// sampling and composites it with source-over blending. flipX and flipY
+// mirror the image horizontally and vertically.
+func DrawImage(dst *image.RGBA, src image.Image, x, y, w, h float64, flipX, flipY bool) {
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	sb := src.Bounds()
+	if sb.Empty() {
+		return
+	}
+	db := dst.Bounds()
+
+	px0 := max(db.Min.X, int(math.Floor(x)))
+	py0 := max(db.Min.Y, int(math.Floor(y)))
+	px1 := min(db.Max.X, int(math.Ceil(x+w)))
+	py1 := min(db.Max.Y, int(math.Ceil(y+h)))
+
+	sx := float64(sb.Dx()) / w
+	sy := float64(sb.Dy()) / h
+
+	for py := py0; py < py1; py++ {
+		cy := float64(py) + 0.5
+		if cy < y || cy >= y+h {
+			continue
+		}
+		v := (cy - y) * sy
+		if flipY {
+			v = float64(sb.Dy()) - v
+		}
+		for px := px0; px < px1; px++ {
+			cx := float64(px) + 0.5
+			if cx < x || cx >= x+w {
+				continue
+			}
+			u := (cx - x) * sx
+			if flipX {
+				u = float64(sb.Dx()) - u
+			}
+			c := sampleBilinear(src, sb, u, v)
+			if c.A == 0 {
+				continue
+			}
+			blendPremultiplied(dst, px, py, c)
+		}
+	}
+}
+
+// sampleBilinear samples src at continuous coordinates (u, v) relative to the
+// image origin, returning a premultiplied color.
+func sampleBilinear(src image.Image, b image.Rectangle, u, v float64) color.RGBA64 {
+	u -= 0.5
+	v -= 0.5
+	x0 := int(math.Floor(u))
+	y0 := int(math.Floor(v))
+	fx := u - float64(x0)
+	fy := v - float64(y0)
+
+	at := func(x, y int) [4]float64 {
+		x = max(0, min(b.Dx()-1, x))
+		y = max(0, min(b.Dy()-1, y))
+		r, g, bl, a := src.At(b.Min.X+x, b.Min.Y+y).RGBA()
+		return [4]float64{float64(r), float64(g), float64(bl), float64(a)}
+	}
+
+	c00 := at(x0, y0)
+	c10 := at(x0+1, y0)
+	c01 := at(x0, y0+1)
+	c11 := at(x0+1, y0+1)
+
+	var out [4]float64
+	for i := range out {
+		top := c00[i]*(1-fx) + c10[i]*fx
+		bottom := c01[i]*(1-fx) + c11[i]*fx
+		out[i] = top*(1-fy) + bottom*fy
+	}
+	return color.RGBA64{
+		R: uint16(out[0] + 0.5),
+		G: uint16(out[1] + 0.5),
+		B: uint16(out[2] + 0.5),
+		A: uint16(out[3] + 0.5),
+	}
+}
+
+// blendPremultiplied composites a premultiplied 16-bit color over dst.
+func blendPremultiplied(dst *image.RGBA, x, y int, c color.RGBA64) {
+	i := dst.PixOffset(x, y)
+	pix := dst.Pix[i : i+4 : i+4]
+	inv := 1 - float64(c.A)/0xffff
+	pix[0] = toByte(float64(c.R)/257 + float64(pix[0])*inv)
+	pix[1] = toByte(float64(c.G)/257 + float64(pix[1])*inv)
+	pix[2] = toByte(float64(c.B)/257 + float64(pix[2])*inv)
+	pix[3] = toByte(float64(c.A)/257 + float64(pix[3])*inv)
+}
@@ -0,0 +1,106 @@
+package raster
+
+import "math"
+
+// Segment is one drawing command of an Outline.
+type Segment struct {
+	Op     byte    // 'M' move, 'L' line, 'C' cubic curve or 'Z' close
+	Points []Point // End point last, after the two control points of 'C'
+}
+
+// Outline is path data with absolute coordinates, reduced to moves,
+// lines and cubic Bézier curves. Unlike Path it keeps curves exact, for
+// vector output formats.
+type Outline []Segment
+
+// ParseOutline parses SVG path data into an outline. Quadratic curves
+// are raised to cubic ones and arcs are split into cubic curves of at
+// most a quarter turn.
+func ParseOutline(d string) (Outline, error) {
+	b := &outlineBuilder{}
+	if err := parsePath(d, b); err != nil {
+		return nil, err
+	}
+	return b.outline, nil
+}
+
+// Bounds returns the bounding box of all segment points, control points
+// included.
+func (o Outline) Bounds() (minX, minY, maxX, maxY float64) {
+	minX, minY = math.Inf(1), math.Inf(1)
+	maxX, maxY = math.Inf(-1), math.Inf(-1)
+	for _, seg := range o {
+		for _, pt := range seg.Points {
+			minX = math.Min(minX, pt.X)
+			minY = math.Min(minY, pt.Y)
+			maxX = math.Max(maxX, pt.X)
+			maxY = math.Max(maxY, pt.Y)
+		}
+	}
+	return minX, minY, maxX, maxY
+}
+
+// outlineBuilder collects path commands as outline segments.
+type outlineBuilder struct {
+	outline    Outline
+	cur, start Point
+}
+
+func (b *outlineBuilder) add(op byte, pts ...Point) {
+	b.outline = append(b.outline, Segment{Op: op, Points: pts})
+	if len(pts) > 0 {
+		b.cur = pts[len(pts)-1]
+	}
+}
+
+func (b *outlineBuilder) moveTo(pt Point) {
+	b.add('M', pt)
+	b.start = pt
+}
+
+func (b *outlineBuilder) lineTo(pt Point) {
+	b.add('L', pt)
+}
+
+func (b *outlineBuilder) cubicTo(c1, c2, end Point) {
+	b.add('C', c1, c2, end)
+}
+
+func (b *outlineBuilder) quadTo(c, end Point) {
+	// Degree elevation: the control points lie 2/3 of the way to c
+	b.add('C',
+		Point{b.cur.X + 2*(c.X-b.cur.X)/3, b.cur.Y + 2*(c.Y-b.cur.Y)/3},
+		Point{end.X + 2*(c.X-end.X)/3, end.Y + 2*(c.Y-end.Y)/3},
+		end)
+}
+
+func (b *outlineBuilder) arcTo(rx, ry, rotation float64, largeArc, sweep bool, end Point) {
+	arc, ok := newEllipseArc(b.cur, end, rx, ry, rotation, largeArc, sweep)
+	if !ok {
+		b.lineTo(end)
+		return
+	}
+
+	n := int(math.Ceil(math.Abs(arc.delta)/(math.Pi/2) - 1e-9))
+	n = max(n, 1)
+	step := arc.delta / float64(n)
+	k := 4.0 / 3 * math.Tan(step/4) // Control distance of a circular arc
+	for i := range n {
+		a0 := arc.theta1 + step*float64(i)
+		a1 := a0 + step
+		p0, p1 := arc.at(a0), arc.at(a1)
+		if i == n-1 {
+			p1 = end // Land exactly on the endpoint
+		}
+		t0, t1 := arc.tangent(a0), arc.tangent(a1)
+		b.add('C',
+			Point{p0.X + k*t0.X, p0.Y + k*t0.Y},
+			Point{p1.X - k*t1.X, p1.Y - k*t1.Y},
+			p1)
+	}
+}
+
+func (b *outlineBuilder) closePath() {
+	b.add('Z')
+	b.cur = b.start
+}
@@ -0,0 +1,493 @@
+package raster
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Point is a position in pixel space.
+type Point struct {
+	X, Y float64
+}
+
+// Path is a flattened outline made of closed polygons.
+type Path struct {
+	Contours [][]Point
+}
+
+// Bounds returns the bounding box of all contour points.
+func (p *Path) Bounds() (minX, minY, maxX, maxY float64) {
+	minX, minY = math.Inf(1), math.Inf(1)
+	maxX, maxY = math.Inf(-1), math.Inf(-1)
+	for _, c := range p.Contours {
+		for _, pt := range c {
+			minX = math.Min(minX, pt.X)
+			minY = math.Min(minY, pt.Y)
+			maxX = math.Max(maxX, pt.X)
+			maxY = math.Max(maxY, pt.Y)
+		}
+	}
+	return minX, minY, maxX, maxY
+}
+
+// Empty reports whether the path contains no drawable contours.
+func (p *Path) Empty() bool {
+	for _, c := range p.Contours {
+		if len(c) > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseSVG parses SVG path data and flattens curves and arcs into polygons.
+// All commands of the SVG path grammar are supported, absolute and relative.
+func ParseSVG(d string) (*Path, error) {
+	p := &pathBuilder{}
+	if err := parsePath(d, p); err != nil {
+		return nil, err
+	}
+	p.closeContour()
+	return &Path{Contours: p.contours}, nil
+}
+
+// pathSink receives the drawing commands of parsed path data, with
+// every coordinate absolute.
+type pathSink interface {
+	moveTo(pt Point)
+	lineTo(pt Point)
+	cubicTo(c1, c2, end Point)
+	quadTo(c, end Point)
+	arcTo(rx, ry, rotation float64, largeArc, sweep bool, end Point)
+	closePath()
+}
+
+// parsePath reads SVG path data and sends its commands to sink.
+func parsePath(d string, sink pathSink) error {
+	t := &tokenizer{s: d}
+	p := &pathParser{sink: sink}
+
+	var cmd byte
+	for {
+		t.skipSeparators()
+		if t.done() {
+			break
+		}
+		if c := t.peek(); isCommand(c) {
+			cmd = c
+			t.pos++
+		} else if cmd == 0 {
+			return fmt.Errorf("path data must start with a command at offset %d", t.pos)
+		}
+
+		if err := p.apply(cmd, t); err != nil {
+			return err
+		}
+
+		// An implicit repeat of moveto is treated as lineto
+		switch cmd {
+		case 'M':
+			cmd = 'L'
+		case 'm':
+			cmd = 'l'
+		case 'Z', 'z':
+			cmd = 0
+		}
+	}
+	return nil
+}
+
+// pathParser resolves relative and smooth commands to absolute ones.
+type pathParser struct {
+	sink pathSink
+
+	cur, start Point
+	lastCtrl   Point // last control point for smooth curve commands
+	lastCmd    byte
+}
+
+func (p *pathParser) apply(cmd byte, t *tokenizer) error {
+	rel := cmd >= 'a' && cmd <= 'z'
+	upper := cmd &^ 0x20
+	off := Point{}
+	if rel {
+		off = p.cur
+	}
+
+	switch upper {
+	case 'M':
+		pt, err := t.point()
+		if err != nil {
+			return err
+		}
+		p.cur = Point{pt.X + off.X, pt.Y + off.Y}
+		p.start = p.cur
+		p.sink.moveTo(p.cur)
+	case 'L':
+		pt, err := t.point()
+		if err != nil {
+			return err
+		}
+		p.lineTo(Point{pt.X + off.X, pt.Y + off.Y})
+	case 'H':
+		x, err := t.number()
+		if err != nil {
+			return err
+		}
+		p.lineTo(Point{x + off.X, p.cur.Y})
+	case 'V':
+		y, err := t.number()
+		if err != nil {
+			return err
+		}
+		p.lineTo(Point{p.cur.X, y + off.Y})
+	case 'C', 'S':
+		var c1 Point
+		if upper == 'S' {
+			c1 = p.reflectedControl('C', 'S')
+		} else {
+			pt, err := t.point()
+			if err != nil {
+				return err
+			}
+			c1 = Point{pt.X + off.X, pt.Y + off.Y}
+		}
+		c2, err := t.point()
+		if err != nil {
+			return err
+		}
+		end, err := t.point()
+		if err != nil {
+			return err
+		}
+		c2 = Point{c2.X + off.X, c2.Y + off.Y}
+		end = Point{end.X + off.X, end.Y + off.Y}
+		p.sink.cubicTo(c1, c2, end)
+		p.cur = end
+		p.lastCtrl = c2
+	case 'Q', 'T':
+		var c Point
+		if upper == 'T' {
+			c = p.reflectedControl('Q', 'T')
+		} else {
+			pt, err := t.point()
+			if err != nil {
+				return err
+			}
+			c = Point{pt.X + off.X, pt.Y + off.Y}
+		}
+		end, err := t.point()
+		if err != nil {
+			return err
+		}
+		end = Point{end.X + off.X, end.Y + off.Y}
+		p.sink.quadTo(c, end)
+		p.cur = end
+		p.lastCtrl = c
+	case 'A':
+		var args [5]float64
+		for i := range args {
+			n, err := t.number()
+			if err != nil {
+				return err
+			}
+			args[i] = n
+		}
+		end, err := t.point()
+		if err != nil {
+			return err
+		}
+		end = Point{end.X + off.X, end.Y + off.Y}
+		p.sink.arcTo(args[0], args[1], args[2], args[3] != 0, args[4] != 0, end)
+		p.cur = end
+	case 'Z':
+		p.sink.closePath()
+		p.cur = p.start
+	default:
+		return fmt.Errorf("unsupported path command %q", cmd)
+	}
+
+	p.lastCmd = upper
+	return nil
+}
+
+func (p *pathParser) lineTo(pt Point) {
+	p.sink.lineTo(pt)
+	p.cur = pt
+}
+
+// reflectedControl returns the reflection of the previous control point for
+// smooth curve commands, or the current point if the previous command was
+// not a matching curve.
+func (p *pathParser) reflectedControl(curve, smooth byte) Point {
+	if p.lastCmd == curve || p.lastCmd == smooth {
+		return Point{2*p.cur.X - p.lastCtrl.X, 2*p.cur.Y - p.lastCtrl.Y}
+	}
+	return p.cur
+}
+
+// pathBuilder flattens path commands into polygons.
+type pathBuilder struct {
+	contours [][]Point
+	current  []Point
+
+	cur, start Point
+}
+
+func (p *pathBuilder) moveTo(pt Point) {
+	p.closeContour()
+	p.cur = pt
+	p.start = pt
+	p.current = []Point{pt}
+}
+
+func (p *pathBuilder) lineTo(pt Point) {
+	if p.current == nil {
+		p.current = []Point{p.cur}
+	}
+	p.current = append(p.current, pt)
+	p.cur = pt
+}
+
+func (p *pathBuilder) closePath() {
+	p.lineTo(p.start)
+	p.closeContour()
+	p.cur = p.start
+}
+
+func (p *pathBuilder) closeContour() {
+	if len(p.current) > 1 {
+		p.contours = append(p.contours, p.current)
+	}
+	p.current = nil
+}
+
+func (p *pathBuilder) cubicTo(c1, c2, end Point) {
+	start := p.cur
+	n := segmentCount(dist(start, c1) + dist(c1, c2) + dist(c2, end))
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		mt := 1 - t
+		a := mt * mt * mt
+		b := 3 * mt * mt * t
+		c := 3 * mt * t * t
+		d := t * t * t
+		p.lineTo(Point{
+			a*start.X + b*c1.X + c*c2.X + d*end.X,
+			a*start.Y + b*c1.Y + c*c2.Y + d*end.Y,
+		})
+	}
+}
+
+func (p *pathBuilder) quadTo(c, end Point) {
+	start := p.cur
+	n := segmentCount(dist(start, c) + dist(c, end))
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		mt := 1 - t
+		a := mt * mt
+		b := 2 * mt * t
+		d := t * t
+		p.lineTo(Point{
+			a*start.X + b*c.X + d*end.X,
+			a*start.Y + b*c.Y + d*end.Y,
+		})
+	}
+}
+
+func (p *pathBuilder) arcTo(rx, ry, rotation float64, largeArc, sweep bool, end Point) {
+	arc, ok := newEllipseArc(p.cur, end, rx, ry, rotation, largeArc, sweep)
+	if !ok {
+		p.lineTo(end)
+		return
+	}
+
+	n := segmentCount(math.Abs(arc.delta) * math.Max(arc.rx, arc.ry))
+	for i := 1; i <= n; i++ {
+		p.lineTo(arc.at(arc.theta1 + arc.delta*float64(i)/float64(n)))
+	}
+	// Land exactly on the endpoint to avoid gaps from rounding
+	p.current[len(p.current)-1] = end
+	p.cur = end
+}
+
+// ellipseArc is an elliptical arc in center parameterization.
+type ellipseArc struct {
+	cx, cy, rx, ry float64
+	cosPhi, sinPhi float64 // Rotation of the x-axis
+	theta1, delta  float64 // Start angle and signed sweep, in radians
+}
+
+// newEllipseArc converts an SVG arc from start to end using the
+// endpoint-to-center conversion from the SVG specification (Appendix
+// B.2.4). It reports false for arcs drawn as a straight line.
+func newEllipseArc(start, end Point, rx, ry, rotation float64, largeArc, sweep bool) (ellipseArc, bool) {
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 || (start == end) {
+		return ellipseArc{}, false
+	}
+
+	phi := rotation * math.Pi / 180
+	cosPhi, sinPhi := math.Cos(phi), math.Sin(phi)
+
+	// Step 1: compute (x1', y1')
+	dx := (start.X - end.X) / 2
+	dy := (start.Y - end.Y) / 2
+	x1 := cosPhi*dx + sinPhi*dy
+	y1 := -sinPhi*dx + cosPhi*dy
+
+	// Correct out-of-range radii
+	lambda := (x1*x1)/(rx*rx) + (y1*y1)/(ry*ry)
+	if lambda > 1 {
+		s := math.Sqrt(lambda)
+		rx *= s
+		ry *= s
+	}
+
+	// Step 2: compute (cx', cy')
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := 0.0
+	if den != 0 && num > 0 {
+		coef = math.Sqrt(num / den)
+	}
+	if largeArc == sweep {
+		coef = -coef
+	}
+	cxp := coef * rx * y1 / ry
+	cyp := -coef * ry * x1 / rx
+
+	// Step 3: compute (cx, cy)
+	cx := cosPhi*cxp - sinPhi*cyp + (start.X+end.X)/2
+	cy := sinPhi*cxp + cosPhi*cyp + (start.Y+end.Y)/2
+
+	// Step 4: compute start angle and sweep
+	theta1 := vectorAngle(1, 0, (x1-cxp)/rx, (y1-cyp)/ry)
+	delta := vectorAngle((x1-cxp)/rx, (y1-cyp)/ry, (-x1-cxp)/rx, (-y1-cyp)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	return ellipseArc{cx, cy, rx, ry, cosPhi, sinPhi, theta1, delta}, true
+}
+
+// at returns the point of the arc at angle theta.
+func (a ellipseArc) at(theta float64) Point {
+	ex := a.rx * math.Cos(theta)
+	ey := a.ry * math.Sin(theta)
+	return Point{
+		a.cosPhi*ex - a.sinPhi*ey + a.cx,
+		a.sinPhi*ex + a.cosPhi*ey + a.cy,
+	}
+}
+
+// tangent returns the derivative of the arc at angle theta.
+func (a ellipseArc) tangent(theta float64) Point {
+	ex := -a.rx * math.Sin(theta)
+	ey := a.ry * math.Cos(theta)
+	return Point{
+		a.cosPhi*ex - a.sinPhi*ey,
+		a.sinPhi*ex + a.cosPhi*ey,
+	}
+}
+
+func vectorAngle(ux, uy, vx, vy float64) float64 {
+	return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+}
+
+func dist(a, b Point) float64 {
+	return math.Hypot(b.X-a.X, b.Y-a.Y)
+}
+
+// segmentCount picks how many line segments approximate a curve of the
+// given approximate length in pixels.
+func segmentCount(length float64) int {
+	n := int(math.Ceil(length / 2))
+	return max(4, min(n, 128))
+}
+
+func isCommand(c byte) bool {
+	switch c | 0x20 {
+	case 'm', 'l', 'h', 'v', 'c', 's', 'q', 't', 'a', 'z':
+		return true
+	}
+	return false
+}
+
+// tokenizer reads numbers out of SVG path data.
+type tokenizer struct {
+	s   string
+	pos int
+}
+
+func (t *tokenizer) done() bool { return t.pos >= len(t.s) }
+func (t *tokenizer) peek() byte { return t.s[t.pos] }
+
+func (t *tokenizer) skipSeparators() {
+	for !t.done() {
+		switch t.peek() {
+		case ' ', '\t', '\n', '\r', ',':
+			t.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (t *tokenizer) point() (Point, error) {
+	x, err := t.number()
+	if err != nil {
+		return Point{}, err
+	}
+	y, err := t.number()
+	if err != nil {
+		return Point{}, err
+	}
+	return Point{x, y}, nil
+}
+
+func (t *tokenizer) number() (float64, error) {
+	t.skipSeparators()
+	start := t.pos
+	if !t.done() && (t.peek() == '+' || t.peek() == '-') {
+		t.pos++
+	}
+	seenDot, seenDigit := false, false
+	for !t.done() {
+		c := t.peek()
+		switch {
+		case c >= '0' && c <= '9':
+			seenDigit = true
+			t.pos++
+		case c == '.' && !seenDot:
+			seenDot = true
+			t.pos++
+		case (c == 'e' || c == 'E') && seenDigit:
+			t.pos++
+			if !t.done() && (t.peek() == '+' || t.peek() == '-') {
+				t.pos++
+			}
+			for !t.done() && t.peek() >= '0' && t.peek() <= '9' {
+				t.pos++
+			}
+			return t.parse(start)
+		default:
+			return t.parse(start)
+		}
+	}
+	return t.parse(start)
+}
+
+func (t *tokenizer) parse(start int) (float64, error) {
+	if start == t.pos {
+		return 0, fmt.Errorf("expected number at offset %d", start)
+	}
+	n, err := strconv.ParseFloat(t.s[start:t.pos], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q at offset %d", t.s[start:t.pos], start)
+	}
+	return n, nil
+}
@@ -0,0 +1,218 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+var black = color.RGBA{0, 0, 0, 255}
+
+func TestParseSVG(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		contours int
+		wantErr  bool
+	}{
+		{"square", "M0 0h1v1h-1z", 1, false},
+		{"absolute lines", "M0 0L10 0L10 10L0 10Z", 1, false},
+		{"implicit lineto", "M0 0 10 0 10 10z", 1, false},
+		{"two subpaths", "M0 0h1v1h-1zM2 2h1v1h-1z", 2, false},
+		{"arc", "M0.5 0A0.5 0.5 0 0 1 0.5 1A0.5 0.5 0 0 1 0.5 0z", 1, false},
+		{"cubic and smooth", "M0 0C1 0 1 1 0 1S-1 2 0 2z", 1, false},
+		{"compact numbers", "M.5.5l-.5.5z", 1, false},
+		{"exponent", "M1e1 0L0 1e-1z", 1, false},
+		{"missing command", "0 0L1 1", 0, true},
+		{"missing argument", "M0 0L1", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseSVG(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSVG() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && len(p.Contours) != tt.contours {
+				t.Errorf("got %d contours, want %d", len(p.Contours), tt.contours)
+			}
+		})
+	}
+}
+
+func TestParseSVGArcEndpoints(t *testing.T) {
+	p, err := ParseSVG("M0 5A5 5 0 0 1 10 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	minX, minY, maxX, maxY := p.Bounds()
+	// Upper half circle of radius 5 centered at (5, 5)
+	if math.Abs(minX) > 1e-9 || math.Abs(maxX-10) > 1e-9 {
+		t.Errorf("unexpected x bounds %.3f..%.3f", minX, maxX)
+	}
+	if math.Abs(minY) > 0.05 || math.Abs(maxY-5) > 1e-9 {
+		t.Errorf("unexpected y bounds %.3f..%.3f", minY, maxY)
+	}
+}
+
+func TestParseOutline(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		ops  string
+	}{
+		{"relative lines", "m1 1h2v2h-2z", "MLLLZ"},
+		{"quad raised to cubic", "M0 0Q5 10 10 0", "MC"},
+		{"smooth cubic", "M0 0C1 0 1 1 0 1S-1 2 0 2", "MCC"},
+		{"half circle", "M0 5A5 5 0 0 1 10 5", "MCC"},
+		{"degenerate arc", "M0 0A0 5 0 0 1 10 0", "ML"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := ParseOutline(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ops := make([]byte, len(o))
+			for i, seg := range o {
+				ops[i] = seg.Op
+			}
+			if string(ops) != tt.ops {
+				t.Errorf("expected ops %s, got %s", tt.ops, ops)
+			}
+		})
+	}
+}
+
+func TestParseOutlineArc(t *testing.T) {
+	o, err := ParseOutline("M0 5A5 5 0 0 1 10 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Upper half circle of radius 5 centered at (5, 5), split at the top
+	mid := o[1].Points[2]
+	if math.Abs(mid.X-5) > 1e-9 || math.Abs(mid.Y) > 1e-9 {
+		t.Errorf("expected quarter point (5, 0), got %v", mid)
+	}
+	if end := o[2].Points[2]; end != (Point{10, 5}) {
+		t.Errorf("expected end point (10, 5), got %v", end)
+	}
+	// Control points of a quarter circle lie 0.5523 radii from the ends
+	if c := o[1].Points[0]; math.Abs(c.Y-5+5*0.5523) > 1e-3 || math.Abs(c.X) > 1e-9 {
+		t.Errorf("unexpected control point %v", c)
+	}
+}
+
+func TestFillPixelAligned(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	p, _ := ParseSVG("M2 2h4v4h-4z")
+	Fill(img, p, NonZero, SolidPaint(black))
+
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			inside := x >= 2 && x < 6 && y >= 2 && y < 6
+			a := img.RGBAAt(x, y).A
+			if inside && a != 255 {
+				t.Errorf("pixel (%d,%d) alpha = %d, want 255", x, y, a)
+			}
+			if !inside && a != 0 {
+				t.Errorf("pixel (%d,%d) alpha = %d, want 0", x, y, a)
+			}
+		}
+	}
+}
+
+func TestFillPartialCoverage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	p, _ := ParseSVG("M0 0h1.5v4h-1.5z")
+	Fill(img, p, NonZero, SolidPaint(black))
+
+	if a := img.RGBAAt(1, 1).A; a < 120 || a > 135 {
+		t.Errorf("half-covered pixel alpha = %d, want ~128", a)
+	}
+}
+
+func TestFillCircleArea(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	p, _ := ParseSVG("M50 10A40 40 0 0 1 50 90A40 40 0 0 1 50 10z")
+	Fill(img, p, NonZero, SolidPaint(black))
+
+	total := 0.0
+	for i := 3; i < len(img.Pix); i += 4 {
+		total += float64(img.Pix[i]) / 255
+	}
+	want := math.Pi * 40 * 40
+	if math.Abs(total-want)/want > 0.01 {
+		t.Errorf("filled area = %.1f, want ~%.1f", total, want)
+	}
+}
+
+func TestFillEvenOdd(t *testing.T) {
+	ring := "M0 0h10v10h-10zM3 3h4v4h-4z"
+	p, _ := ParseSVG(ring)
+
+	evenOdd := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	Fill(evenOdd, p, EvenOdd, SolidPaint(black))
+	if a := evenOdd.RGBAAt(5, 5).A; a != 0 {
+		t.Errorf("even-odd hole alpha = %d, want 0", a)
+	}
+	if a := evenOdd.RGBAAt(1, 1).A; a != 255 {
+		t.Errorf("even-odd ring alpha = %d, want 255", a)
+	}
+
+	nonZero := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	Fill(nonZero, p, NonZero, SolidPaint(black))
+	if a := nonZero.RGBAAt(5, 5).A; a != 255 {
+		t.Errorf("non-zero overlap alpha = %d, want 255", a)
+	}
+}
+
+func TestFillClipsToImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	p, _ := ParseSVG("M-5 -5h20v20h-20z")
+	Fill(img, p, NonZero, SolidPaint(black))
+
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 255 {
+			t.Fatalf("expected fully covered image, got alpha %d at %d", img.Pix[i], i/4)
+		}
+	}
+}
+
+func TestFillDegenerateSegments(t *testing.T) {
+	// Zero-length sides and curves touching the window edge must not
+	// step outside the accumulator through rounding error.
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	p, err := ParseSVG("M45 10L45 10Q80 10 80 45L80 45Q80 80 45 80L45 80Q10 80 10 45L10 45Q10 10 45 10Z")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	Fill(img, p, NonZero, SolidPaint(black))
+
+	if a := img.RGBAAt(45, 45).A; a != 255 {
+		t.Errorf("expected covered center, got alpha %d", a)
+	}
+	if a := img.RGBAAt(11, 11).A; a != 0 {
+		t.Errorf("expected empty corner, got alpha %d", a)
+	}
+}
+
+func TestDrawImage(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.SetRGBA(0, 0, color.RGBA{255, 0, 0, 255})
+	src.SetRGBA(1, 0, color.RGBA{0, 0, 255, 255})
+
+	dst := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	DrawImage(dst, src, 0, 0, 8, 4, false, false)
+	if c := dst.RGBAAt(0, 0); c.R != 255 || c.B != 0 {
+		t.Errorf("expected red at left edge, got %v", c)
+	}
+
+	flipped := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	DrawImage(flipped, src, 0, 0, 8, 4, true, false)
+	if c := flipped.RGBAAt(0, 0); c.B != 255 || c.R != 0 {
+		t.Errorf("expected blue at left edge when flipped, got %v", c)
+	}
+}
@@ -1,6 +1,9 @@
 package shapes
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+)
 
 func init() {
 	// Register all built-in shapes
@@ -36,6 +39,12 @@ type roundedSquare struct {
 	radius float64 // corner radius as fraction of size (0-0.5)
 }
 
+// NewRoundedSquare creates a rounded square with a custom corner radius,
+// given as a fraction of the shape size (0-0.5).
+func NewRoundedSquare(radius float64) Shape {
+	return &roundedSquare{radius: math.Max(0, math.Min(0.5, radius))}
+}
+
 func (s *roundedSquare) Name() string { return "rounded" }
 func (s *roundedSquare) SVGPath() string {
 	r := s.radius
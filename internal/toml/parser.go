@@ -0,0 +1,702 @@
+package toml
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Parse reads a TOML document and returns its root table.
+func Parse(r io.Reader) (*TableValue, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(src) {
+		return nil, &Error{Line: 1, Column: 1, Message: "document is not valid UTF-8"}
+	}
+
+	p := &parser{src: string(src), line: 1, col: 1, root: newTable()}
+	p.root.defined = true
+	p.current, p.prefix = p.root, ""
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.root, nil
+}
+
+// ParseString parses a TOML document held in a string.
+func ParseString(s string) (*TableValue, error) {
+	return Parse(strings.NewReader(s))
+}
+
+type parser struct {
+	src       string
+	off       int
+	line, col int
+
+	root    *TableValue
+	current *TableValue // Table selected by the last [header]
+	prefix  string      // Dotted key of current
+}
+
+func (p *parser) parse() error {
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return nil
+		}
+		var err error
+		if p.peek() == '[' {
+			err = p.parseHeader()
+		} else {
+			err = p.parseKeyValue(p.current, p.prefix)
+		}
+		if err != nil {
+			return err
+		}
+		if err := p.endOfLine(); err != nil {
+			return err
+		}
+	}
+}
+
+// parseHeader handles a [table] line.
+func (p *parser) parseHeader() error {
+	pos := p.pos()
+	p.next() // [
+	if p.peek() == '[' {
+		return p.errorAt(pos, "arrays of tables are not supported")
+	}
+
+	p.skipSpace()
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	if p.peek() != ']' {
+		return p.errorf("expected ']' to close table header")
+	}
+	p.next()
+
+	t, prefix := p.root, ""
+	for i, key := range keys {
+		prefix = joinKey(prefix, key)
+		v := t.Get(key)
+		last := i == len(keys)-1
+		switch {
+		case v == nil:
+			child := &Value{Kind: Table, Key: prefix, KeyPos: pos, Pos: pos, Table: newTable()}
+			t.set(key, child)
+			v = child
+		case v.Kind != Table:
+			return p.errorAt(pos, "key %s is already defined as %s", prefix, v.Kind)
+		case v.Table.inline:
+			return p.errorAt(pos, "inline table %s cannot be extended", prefix)
+		case last && v.Table.defined:
+			return p.errorAt(pos, "table [%s] is defined twice", prefix)
+		}
+		if last {
+			// Report the header, not the first dotted key that implied it
+			v.KeyPos, v.Pos = pos, pos
+			v.Table.defined = true
+		}
+		t = v.Table
+	}
+
+	p.current, p.prefix = t, prefix
+	return nil
+}
+
+// parseKeyValue parses key = value and stores it in t.
+func (p *parser) parseKeyValue(t *TableValue, prefix string) error {
+	keyPos := p.pos()
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+
+	// Walk dotted keys, creating intermediate tables
+	for _, key := range keys[:len(keys)-1] {
+		prefix = joinKey(prefix, key)
+		v := t.Get(key)
+		if v == nil {
+			v = &Value{Kind: Table, Key: prefix, KeyPos: keyPos, Pos: keyPos, Table: newTable()}
+			v.Table.defined = true
+			t.set(key, v)
+		} else if v.Kind != Table || v.Table.inline {
+			return p.errorAt(keyPos, "key %s is already defined as %s", prefix, v.Kind)
+		}
+		t = v.Table
+	}
+
+	key := keys[len(keys)-1]
+	fullKey := joinKey(prefix, key)
+	if t.Get(key) != nil {
+		return p.errorAt(keyPos, "duplicate key %s", fullKey)
+	}
+
+	p.skipSpace()
+	if p.peek() != '=' {
+		return p.errorf("expected '=' after key %s", fullKey)
+	}
+	p.next()
+	p.skipSpace()
+
+	v, err := p.parseValue(fullKey)
+	if err != nil {
+		return err
+	}
+	v.KeyPos = keyPos
+	t.set(key, v)
+	return nil
+}
+
+// parseKey parses a possibly dotted key into its segments.
+func (p *parser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		var key string
+		switch c := p.peek(); {
+		case c == '"':
+			s, err := p.parseBasicString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		case c == '\'':
+			s, err := p.parseLiteralString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		case isBareKeyChar(c):
+			start := p.off
+			for !p.eof() && isBareKeyChar(p.peek()) {
+				p.next()
+			}
+			key = p.src[start:p.off]
+		default:
+			if p.eof() || c == '\n' || c == '\r' {
+				return nil, p.errorf("expected key")
+			}
+			return nil, p.errorf("unexpected character %q in key", c)
+		}
+		keys = append(keys, key)
+
+		p.skipSpace()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.next()
+		p.skipSpace()
+	}
+}
+
+// parseValue parses any value starting at the current position.
+func (p *parser) parseValue(key string) (*Value, error) {
+	pos := p.pos()
+	v := &Value{Key: key, Pos: pos}
+
+	switch c := p.peek(); {
+	case p.eof() || c == '\n' || c == '\r' || c == '#':
+		return nil, p.errorf("missing value for key %s", key)
+
+	case c == '"':
+		s, err := p.parseBasicString()
+		if err != nil {
+			return nil, err
+		}
+		v.Kind, v.Str = String, s
+
+	case c == '\'':
+		s, err := p.parseLiteralString()
+		if err != nil {
+			return nil, err
+		}
+		v.Kind, v.Str = String, s
+
+	case c == '[':
+		items, err := p.parseArray(key)
+		if err != nil {
+			return nil, err
+		}
+		v.Kind, v.Items = Array, items
+
+	case c == '{':
+		t, err := p.parseInlineTable(key)
+		if err != nil {
+			return nil, err
+		}
+		v.Kind, v.Table = Table, t
+
+	case strings.HasPrefix(p.src[p.off:], "true") && !p.bareAt(p.off+4):
+		p.advance(4)
+		v.Kind, v.Bool = Boolean, true
+
+	case strings.HasPrefix(p.src[p.off:], "false") && !p.bareAt(p.off+5):
+		p.advance(5)
+		v.Kind, v.Bool = Boolean, false
+
+	default:
+		if err := p.parseNumber(v); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// bareAt reports whether the byte at off continues a bare word.
+func (p *parser) bareAt(off int) bool {
+	return off < len(p.src) && isBareKeyChar(rune(p.src[off]))
+}
+
+func (p *parser) parseArray(key string) ([]*Value, error) {
+	p.next() // [
+	var items []*Value
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array for key %s", key)
+		}
+		if p.peek() == ']' {
+			p.next()
+			return items, nil
+		}
+
+		item, err := p.parseValue(fmt.Sprintf("%s[%d]", key, len(items)))
+		if err != nil {
+			return nil, err
+		}
+		item.KeyPos = item.Pos
+		items = append(items, item)
+
+		p.skipBlank(true)
+		switch p.peek() {
+		case ',':
+			p.next()
+		case ']':
+			p.next()
+			return items, nil
+		default:
+			if p.eof() {
+				return nil, p.errorf("unterminated array for key %s", key)
+			}
+			return nil, p.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+func (p *parser) parseInlineTable(key string) (*TableValue, error) {
+	p.next() // {
+	t := newTable()
+	t.defined, t.inline = true, true
+
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.next()
+		return t, nil
+	}
+	for {
+		p.skipSpace()
+		if err := p.parseKeyValue(t, key); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.next()
+		case '}':
+			p.next()
+			return t, nil
+		default:
+			return nil, p.errorf("expected ',' or '}' in inline table")
+		}
+	}
+}
+
+func (p *parser) parseNumber(v *Value) error {
+	start := p.off
+	for !p.eof() {
+		c := p.peek()
+		if !isBareKeyChar(c) && c != '.' && c != '+' && c != ':' {
+			break
+		}
+		p.next()
+	}
+	raw := p.src[start:p.off]
+	if raw == "" {
+		return p.errorAt(v.Pos, "unexpected character %q in value", p.peek())
+	}
+	if strings.ContainsAny(raw, ":") || isDate(raw) {
+		return p.errorAt(v.Pos, "dates and times are not supported")
+	}
+
+	if strings.Contains(raw, "__") || strings.HasPrefix(raw, "_") || strings.HasSuffix(raw, "_") {
+		return p.errorAt(v.Pos, "invalid number %q", raw)
+	}
+	clean := strings.ReplaceAll(raw, "_", "")
+
+	if n, ok := parseInteger(clean); ok {
+		v.Kind, v.Int = Integer, n
+		return nil
+	}
+
+	switch strings.TrimLeft(clean, "+-") {
+	case "inf":
+		v.Kind, v.Float = Float, math.Inf(1)
+		if clean[0] == '-' {
+			v.Float = math.Inf(-1)
+		}
+		return nil
+	case "nan":
+		v.Kind, v.Float = Float, math.NaN()
+		return nil
+	}
+
+	if isDecimalFloat(clean) {
+		f, err := strconv.ParseFloat(clean, 64)
+		if err == nil {
+			v.Kind, v.Float = Float, f
+			return nil
+		}
+	}
+	return p.errorAt(v.Pos, "invalid value %q", raw)
+}
+
+// parseInteger parses decimal, hex, octal and binary integers.
+func parseInteger(s string) (int64, bool) {
+	base := 10
+	digits := s
+	switch {
+	case strings.HasPrefix(s, "0x"):
+		base, digits = 16, s[2:]
+	case strings.HasPrefix(s, "0o"):
+		base, digits = 8, s[2:]
+	case strings.HasPrefix(s, "0b"):
+		base, digits = 2, s[2:]
+	default:
+		// Leading zeros are not allowed in decimal integers
+		unsigned := strings.TrimLeft(s, "+-")
+		if len(unsigned) > 1 && unsigned[0] == '0' {
+			return 0, false
+		}
+	}
+	if base != 10 && (digits == "" || digits[0] == '+' || digits[0] == '-') {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(digits, base, 64)
+	return n, err == nil
+}
+
+// isDecimalFloat checks the TOML float grammar, which is stricter than
+// strconv (no hex floats, digits required around the point).
+func isDecimalFloat(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	mantissa, exp, hasExp := strings.Cut(strings.ToLower(s), "e")
+	if hasExp {
+		exp = strings.TrimLeft(exp, "+-")
+		if !allDigits(exp) {
+			return false
+		}
+	}
+	whole, frac, hasFrac := strings.Cut(mantissa, ".")
+	if !allDigits(whole) || (hasFrac && !allDigits(frac)) {
+		return false
+	}
+	if len(whole) > 1 && whole[0] == '0' {
+		return false
+	}
+	return hasFrac || hasExp
+}
+
+func allDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isDate reports whether s looks like a date such as 2024-01-31.
+func isDate(s string) bool {
+	return len(s) >= 10 && allDigits(s[0:4]) && s[4] == '-' && allDigits(s[5:7]) && s[7] == '-'
+}
+
+func (p *parser) parseBasicString() (string, error) {
+	start := p.pos()
+	if strings.HasPrefix(p.src[p.off:], `"""`) {
+		return p.parseMultilineBasic(start)
+	}
+	p.next() // "
+
+	var sb strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorAt(start, "unterminated string")
+		}
+		c := p.next()
+		switch c {
+		case '"':
+			return sb.String(), nil
+		case '\\':
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+		default:
+			if isControl(c) {
+				return "", p.errorf("control character %U in string", c)
+			}
+			sb.WriteRune(c)
+		}
+	}
+}
+
+func (p *parser) parseMultilineBasic(start Position) (string, error) {
+	p.advance(3)
+	p.trimLeadingNewline()
+
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorAt(start, "unterminated multi-line string")
+		}
+		if strings.HasPrefix(p.src[p.off:], `"""`) {
+			p.advance(3)
+			// Up to two quotes may directly precede the closing delimiter
+			for i := 0; i < 2 && p.peek() == '"'; i++ {
+				sb.WriteByte('"')
+				p.next()
+			}
+			return sb.String(), nil
+		}
+		c := p.next()
+		switch {
+		case c == '\\':
+			// A backslash at the end of a line trims the following whitespace
+			if p.lineEndingBackslash() {
+				for strings.ContainsRune(" \t\r\n", p.peek()) && !p.eof() {
+					p.next()
+				}
+				continue
+			}
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+		case c == '\r' && p.peek() == '\n':
+			// Normalized to \n below
+		case isControl(c) && c != '\n' && c != '\t':
+			return "", p.errorf("control character %U in string", c)
+		default:
+			sb.WriteRune(c)
+		}
+	}
+}
+
+// lineEndingBackslash reports whether only whitespace follows up to the
+// end of the line.
+func (p *parser) lineEndingBackslash() bool {
+	rest := p.src[p.off:]
+	i := strings.IndexAny(rest, "\n")
+	if i < 0 {
+		return false
+	}
+	return strings.TrimRight(rest[:i], " \t\r") == ""
+}
+
+func (p *parser) parseEscape(sb *strings.Builder) error {
+	// Report errors at the backslash, which was already consumed
+	pos := Position{Line: p.line, Column: p.col - 1}
+	if p.eof() {
+		return p.errorAt(pos, "unterminated escape sequence")
+	}
+	c := p.next()
+	switch c {
+	case 'b':
+		sb.WriteByte('\b')
+	case 't':
+		sb.WriteByte('\t')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'r':
+		sb.WriteByte('\r')
+	case 'e':
+		sb.WriteByte(0x1b)
+	case '"':
+		sb.WriteByte('"')
+	case '\\':
+		sb.WriteByte('\\')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.off+n > len(p.src) {
+			return p.errorAt(pos, "invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.src[p.off:p.off+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorAt(pos, "invalid unicode escape")
+		}
+		p.advance(n)
+		sb.WriteRune(rune(code))
+	default:
+		return p.errorAt(pos, "invalid escape sequence \\%c", c)
+	}
+	return nil
+}
+
+func (p *parser) parseLiteralString() (string, error) {
+	start := p.pos()
+	if strings.HasPrefix(p.src[p.off:], "'''") {
+		p.advance(3)
+		p.trimLeadingNewline()
+		end := strings.Index(p.src[p.off:], "'''")
+		if end < 0 {
+			return "", p.errorAt(start, "unterminated multi-line string")
+		}
+		// Up to two quotes may directly precede the closing delimiter
+		for i := 0; i < 2 && p.off+end+3 < len(p.src) && p.src[p.off+end+3] == '\''; i++ {
+			end++
+		}
+		s := strings.ReplaceAll(p.src[p.off:p.off+end], "\r\n", "\n")
+		p.advance(end + 3)
+		return s, nil
+	}
+
+	p.next() // '
+	var sb strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorAt(start, "unterminated string")
+		}
+		c := p.next()
+		if c == '\'' {
+			return sb.String(), nil
+		}
+		if isControl(c) && c != '\t' {
+			return "", p.errorf("control character %U in string", c)
+		}
+		sb.WriteRune(c)
+	}
+}
+
+func (p *parser) trimLeadingNewline() {
+	if strings.HasPrefix(p.src[p.off:], "\r\n") {
+		p.advance(2)
+	} else if p.peek() == '\n' {
+		p.next()
+	}
+}
+
+func isControl(c rune) bool {
+	return c < 0x20 && c != '\t' || c == 0x7f
+}
+
+// endOfLine expects only whitespace or a comment before the next line.
+func (p *parser) endOfLine() error {
+	p.skipSpace()
+	if p.peek() == '#' {
+		p.skipComment()
+	}
+	switch {
+	case p.eof():
+		return nil
+	case p.peek() == '\n':
+		p.next()
+		return nil
+	case strings.HasPrefix(p.src[p.off:], "\r\n"):
+		p.advance(2)
+		return nil
+	}
+	return p.errorf("unexpected %q after value, expected end of line", p.peek())
+}
+
+// skipBlank skips whitespace and comments, and newlines if requested.
+func (p *parser) skipBlank(newlines bool) {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t':
+			p.next()
+		case '\r', '\n':
+			if !newlines {
+				return
+			}
+			p.next()
+		case '#':
+			if !newlines {
+				return
+			}
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) skipSpace() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.next()
+	}
+}
+
+func (p *parser) skipComment() {
+	for !p.eof() && p.peek() != '\n' {
+		p.next()
+	}
+}
+
+func (p *parser) eof() bool {
+	return p.off >= len(p.src)
+}
+
+// peek returns the next character without consuming it, or 0 at EOF.
+func (p *parser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	c, _ := utf8.DecodeRuneInString(p.src[p.off:])
+	return c
+}
+
+// next consumes and returns the next character.
+func (p *parser) next() rune {
+	c, n := utf8.DecodeRuneInString(p.src[p.off:])
+	p.off += n
+	if c == '\n' {
+		p.line++
+		p.col = 1
+	} else {
+		p.col++
+	}
+	return c
+}
+
+func (p *parser) advance(n int) {
+	end := p.off + n
+	for p.off < end && !p.eof() {
+		p.next()
+	}
+}
+
+func (p *parser) pos() Position {
+	return Position{Line: p.line, Column: p.col}
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return p.errorAt(p.pos(), format, args...)
+}
+
+func (p *parser) errorAt(pos Position, format string, args ...any) error {
+	return &Error{Line: pos.Line, Column: pos.Column, Message: fmt.Sprintf(format, args...)}
+}
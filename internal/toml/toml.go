@@ -0,0 +1,121 @@
+// Package toml parses the subset of TOML used by qr-gode style files.
+//
+// Supported are tables, dotted keys, inline tables, arrays, strings
+// (basic, literal and multi-line), integers, floats and booleans.
+// Arrays of tables and date-time values are rejected. Every value keeps
+// the line and column it was defined at, so callers can report precise
+// errors for keys they don't understand.
+package toml
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind identifies the type of a value.
+type Kind int
+
+const (
+	String Kind = iota
+	Integer
+	Float
+	Boolean
+	Array
+	Table
+)
+
+func (k Kind) String() string {
+	switch k {
+	case String:
+		return "string"
+	case Integer:
+		return "integer"
+	case Float:
+		return "float"
+	case Boolean:
+		return "boolean"
+	case Array:
+		return "array"
+	case Table:
+		return "table"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Position is a 1-based line and column in the source.
+// Columns count characters, not bytes.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Value is a parsed TOML value.
+type Value struct {
+	Kind   Kind
+	Key    string   // Full dotted key, e.g. "style.modules.size"
+	KeyPos Position // Where the key or table header was written
+	Pos    Position // Where the value starts
+
+	Str   string
+	Int   int64
+	Float float64
+	Bool  bool
+	Items []*Value
+	Table *TableValue
+}
+
+// TableValue holds the entries of a table in definition order.
+type TableValue struct {
+	Keys   []string
+	Values map[string]*Value
+
+	defined bool // Defined by a [header] or as a value, not just implied
+	inline  bool // Inline tables can't be extended later
+}
+
+func newTable() *TableValue {
+	return &TableValue{Values: make(map[string]*Value)}
+}
+
+// Get returns the value for key, or nil if it isn't set.
+func (t *TableValue) Get(key string) *Value {
+	return t.Values[key]
+}
+
+func (t *TableValue) set(key string, v *Value) {
+	t.Keys = append(t.Keys, key)
+	t.Values[key] = v
+}
+
+// Error is a syntax error with its location.
+type Error struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// joinKey appends a key segment to a dotted key, quoting it if needed.
+func joinKey(prefix, key string) string {
+	if !isBareKey(key) {
+		key = fmt.Sprintf("%q", key)
+	}
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func isBareKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	return strings.IndexFunc(key, func(r rune) bool { return !isBareKeyChar(r) }) < 0
+}
+
+func isBareKeyChar(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-'
+}
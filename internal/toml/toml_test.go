@@ -0,0 +1,183 @@
+package toml
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseTables(t *testing.T) {
+	doc, err := ParseString(`# comment
+title = "qr"
+
+[style]
+size = 512 # trailing comment
+
+[style.modules]
+shape = "dot"
+size = 0.7
+
+[style.finder_patterns.outer]
+corner_radius = 0.3
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := doc.Get("title").Str; got != "qr" {
+		t.Errorf("expected title 'qr', got %q", got)
+	}
+	style := doc.Get("style")
+	if style == nil || style.Kind != Table {
+		t.Fatal("expected style table")
+	}
+	if got := style.Table.Get("size").Int; got != 512 {
+		t.Errorf("expected size 512, got %d", got)
+	}
+	modules := style.Table.Get("modules").Table
+	if got := modules.Get("size").Float; got != 0.7 {
+		t.Errorf("expected size 0.7, got %v", got)
+	}
+	if got := strings.Join(modules.Keys, ","); got != "shape,size" {
+		t.Errorf("expected keys in definition order, got %s", got)
+	}
+
+	outer := style.Table.Get("finder_patterns").Table.Get("outer")
+	if outer.Key != "style.finder_patterns.outer" {
+		t.Errorf("expected full key, got %q", outer.Key)
+	}
+	radius := outer.Table.Get("corner_radius")
+	if radius.Key != "style.finder_patterns.outer.corner_radius" {
+		t.Errorf("expected full key, got %q", radius.Key)
+	}
+	if radius.KeyPos != (Position{Line: 12, Column: 1}) {
+		t.Errorf("expected key at 12:1, got %+v", radius.KeyPos)
+	}
+	if radius.Pos != (Position{Line: 12, Column: 17}) {
+		t.Errorf("expected value at 12:17, got %+v", radius.Pos)
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	doc, err := ParseString(`
+basic = "tab\tquote\"unicode\u00e9"
+literal = 'C:\path'
+multi = """
+line one
+line two"""
+folded = """one \
+    two"""
+raw = '''
+keep \n'''
+int = -42
+hex = 0xff
+under = 1_000
+float = 3.5e2
+inf = -inf
+yes = true
+no = false
+stops = [
+  "#ff0000", # first
+  "#0000ff",
+]
+point = { x = 1, y = 2.5 }
+dotted.key = "x"
+"quoted key" = 1
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	strs := map[string]string{
+		"basic":   "tab\tquote\"unicodeé",
+		"literal": `C:\path`,
+		"multi":   "line one\nline two",
+		"folded":  "one two",
+		"raw":     `keep \n`,
+	}
+	for key, want := range strs {
+		if got := doc.Get(key).Str; got != want {
+			t.Errorf("%s: expected %q, got %q", key, want, got)
+		}
+	}
+
+	ints := map[string]int64{"int": -42, "hex": 255, "under": 1000}
+	for key, want := range ints {
+		v := doc.Get(key)
+		if v.Kind != Integer || v.Int != want {
+			t.Errorf("%s: expected integer %d, got %s %d", key, want, v.Kind, v.Int)
+		}
+	}
+
+	if v := doc.Get("float"); v.Kind != Float || v.Float != 350 {
+		t.Errorf("expected float 350, got %s %v", v.Kind, v.Float)
+	}
+	if v := doc.Get("inf"); !math.IsInf(v.Float, -1) {
+		t.Errorf("expected -inf, got %v", v.Float)
+	}
+	if !doc.Get("yes").Bool || doc.Get("no").Bool {
+		t.Error("expected booleans true and false")
+	}
+
+	stops := doc.Get("stops")
+	if stops.Kind != Array || len(stops.Items) != 2 || stops.Items[1].Str != "#0000ff" {
+		t.Errorf("unexpected array %+v", stops.Items)
+	}
+	if stops.Items[1].Key != "stops[1]" {
+		t.Errorf("expected item key stops[1], got %q", stops.Items[1].Key)
+	}
+
+	point := doc.Get("point")
+	if point.Kind != Table || point.Table.Get("y").Float != 2.5 {
+		t.Error("expected inline table with y = 2.5")
+	}
+	if got := doc.Get("dotted").Table.Get("key").Str; got != "x" {
+		t.Errorf("expected dotted key value 'x', got %q", got)
+	}
+	if doc.Get("quoted key") == nil {
+		t.Error("expected quoted key")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		line int
+		col  int
+		msg  string
+	}{
+		{"duplicate key", "a = 1\na = 2", 2, 1, "duplicate key a"},
+		{"duplicate table", "[a]\n[a]", 2, 1, "defined twice"},
+		{"missing equals", "a 1", 1, 3, "expected '='"},
+		{"missing value", "a =\n", 1, 4, "missing value"},
+		{"unterminated string", `a = "abc`, 1, 5, "unterminated string"},
+		{"bad escape", `a = "\q"`, 1, 6, "invalid escape"},
+		{"trailing garbage", "a = 1 2", 1, 7, "expected end of line"},
+		{"array of tables", "[[a]]", 1, 1, "arrays of tables"},
+		{"date", "a = 2024-01-31", 1, 5, "dates and times"},
+		{"leading zero", "a = 012", 1, 5, "invalid value"},
+		{"bare word", "a = blue", 1, 5, "invalid value"},
+		{"unterminated array", "a = [1, 2", 1, 10, "unterminated array"},
+		{"table over value", "a = 1\n[a.b]", 2, 1, "already defined as integer"},
+		{"extend inline", "a = {b = 1}\n[a]", 2, 1, "inline table a cannot be extended"},
+		{"column counts characters", `"é" = 1 x`, 1, 9, "expected end of line"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseString(tt.src)
+			var perr *Error
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected *Error, got %v", err)
+			}
+			if perr.Line != tt.line || perr.Column != tt.col {
+				t.Errorf("expected %d:%d, got %d:%d (%s)", tt.line, tt.col, perr.Line, perr.Column, perr.Message)
+			}
+			if !strings.Contains(perr.Message, tt.msg) {
+				t.Errorf("expected message containing %q, got %q", tt.msg, perr.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,611 @@
+package qrgode
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ahmedtahas/qr-gode/internal/colors"
+	"github.com/ahmedtahas/qr-gode/internal/encoder"
+	"github.com/ahmedtahas/qr-gode/internal/shapes"
+	"github.com/ahmedtahas/qr-gode/internal/toml"
+)
+
+// ConfigError reports a problem in a TOML configuration file.
+// Line and Column are 1-based and point at the offending key or value.
+type ConfigError struct {
+	File    string // Empty when parsed from a reader
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *ConfigError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// LoadConfig reads a TOML style file and returns the config it describes,
+// along with the data from its [qr] table (empty if not set).
+//
+// Relative image paths in the file are resolved against the file's
+// directory. See examples/configs for the supported layout.
+func LoadConfig(path string) (*Config, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+
+	cfg, data, err := parseConfig(f, filepath.Dir(path))
+	if err != nil {
+		var cerr *ConfigError
+		if errors.As(err, &cerr) {
+			cerr.File = path
+		}
+		return nil, "", err
+	}
+	return cfg, data, nil
+}
+
+// ParseConfig reads a TOML style document from r. It behaves like
+// LoadConfig, except that relative image paths are left as written.
+func ParseConfig(r io.Reader) (*Config, string, error) {
+	return parseConfig(r, "")
+}
+
+func parseConfig(r io.Reader, baseDir string) (*Config, string, error) {
+	doc, err := toml.Parse(r)
+	if err != nil {
+		var terr *toml.Error
+		if errors.As(err, &terr) {
+			return nil, "", &ConfigError{Line: terr.Line, Column: terr.Column, Message: terr.Message}
+		}
+		return nil, "", err
+	}
+
+	d := &configDecoder{cfg: DefaultConfig(), baseDir: baseDir}
+	if err := d.decode(doc); err != nil {
+		return nil, "", err
+	}
+	return d.cfg, d.data, nil
+}
+
+// configDecoder maps a parsed TOML document onto a Config.
+type configDecoder struct {
+	cfg     *Config
+	data    string
+	baseDir string
+
+	quietZone bool // quiet_zone was set, so symbol keeps it
+}
+
+// fieldFunc decodes the value of one key.
+type fieldFunc func(v *toml.Value) error
+
+func (d *configDecoder) decode(doc *toml.TableValue) error {
+	return d.fields(doc, "", map[string]fieldFunc{
+		"qr":    d.table(d.decodeQR),
+		"style": d.table(d.decodeStyle),
+	})
+}
+
+func (d *configDecoder) decodeQR(t *toml.TableValue, name string) error {
+	return d.fields(t, name, map[string]fieldFunc{
+		"data": func(v *toml.Value) (err error) {
+			d.data, err = d.str(v)
+			return err
+		},
+		"error_correction": func(v *toml.Value) error {
+			s, err := d.str(v)
+			if err != nil {
+				return err
+			}
+			levels := map[string]ErrorCorrectionLevel{"L": LevelL, "M": LevelM, "Q": LevelQ, "H": LevelH}
+			level, ok := levels[strings.ToUpper(s)]
+			if !ok {
+				return d.errorf(v.Pos, "%s: unknown level %q (expected L, M, Q or H)", v.Key, s)
+			}
+			d.cfg.ErrorCorrection = level
+			return nil
+		},
+		"boost_ecl": func(v *toml.Value) (err error) {
+			d.cfg.BoostECL, err = d.bool(v)
+			return err
+		},
+		"gs1": func(v *toml.Value) (err error) {
+			d.cfg.GS1, err = d.bool(v)
+			return err
+		},
+		"symbol": func(v *toml.Value) error {
+			s, err := d.str(v)
+			if err != nil {
+				return err
+			}
+			symbols := map[string]SymbolType{"qr": SymbolQR, "micro": SymbolMicro, "rmqr": SymbolRMQR}
+			symbol, ok := symbols[strings.ToLower(s)]
+			if !ok {
+				return d.errorf(v.Pos, "%s: unknown symbol %q (expected qr, micro or rmqr)", v.Key, s)
+			}
+			d.cfg.Symbol = symbol
+			if !d.quietZone {
+				d.cfg.QuietZone = symbol.quietZone()
+			}
+			return nil
+		},
+		"max_height": func(v *toml.Value) (err error) {
+			d.cfg.MaxHeight, err = d.int(v)
+			return err
+		},
+		"version": func(v *toml.Value) (err error) {
+			d.cfg.Version, err = d.int(v)
+			return err
+		},
+		"min_version": func(v *toml.Value) (err error) {
+			d.cfg.MinVersion, err = d.int(v)
+			return err
+		},
+		"mask": func(v *toml.Value) (err error) {
+			d.cfg.Mask, err = d.int(v)
+			return err
+		},
+		"charset": func(v *toml.Value) error {
+			s, err := d.str(v)
+			if err != nil {
+				return err
+			}
+			if _, ok := encoder.LookupCharset(s); !ok {
+				return d.errorf(v.Pos, "%s: unknown character set %q (expected %s)",
+					v.Key, s, strings.Join(encoder.CharsetNames(), ", "))
+			}
+			d.cfg.Charset = s
+			return nil
+		},
+	})
+}
+
+func (d *configDecoder) decodeStyle(t *toml.TableValue, name string) error {
+	cfg := d.cfg
+	return d.fields(t, name, map[string]fieldFunc{
+		"size": func(v *toml.Value) (err error) {
+			cfg.Size, err = d.int(v)
+			return err
+		},
+		"physical_size": func(v *toml.Value) (err error) {
+			cfg.PhysicalSize, err = d.float(v)
+			return err
+		},
+		"cmyk": func(v *toml.Value) (err error) {
+			cfg.CMYK, err = d.bool(v)
+			return err
+		},
+		"spot": d.table(d.decodeSpot),
+		"quiet_zone": func(v *toml.Value) (err error) {
+			d.quietZone = true
+			cfg.QuietZone, err = d.int(v)
+			return err
+		},
+		"background": func(v *toml.Value) (err error) {
+			cfg.Background, err = d.color(v)
+			return err
+		},
+		"modules": d.table(func(t *toml.TableValue, name string) error {
+			return d.fields(t, name, map[string]fieldFunc{
+				"shape": d.shapeField(&cfg.Modules.Shape),
+				"color": d.colorField(&cfg.Modules.Color),
+				"size": func(v *toml.Value) (err error) {
+					cfg.Modules.Size, err = d.float(v)
+					return err
+				},
+			})
+		}),
+		"finder_patterns": d.table(d.decodeFinders),
+		"alignment":       d.table(d.decodeAlignment),
+		"timing": d.table(func(t *toml.TableValue, name string) error {
+			return d.fields(t, name, map[string]fieldFunc{
+				"shape": d.shapeField(&cfg.Timing.Shape),
+				"color": d.colorField(&cfg.Timing.Color),
+			})
+		}),
+		"logo":   d.table(d.decodeLogo),
+		"images": d.table(d.decodeImages),
+	})
+}
+
+func (d *configDecoder) decodeFinders(t *toml.TableValue, name string) error {
+	f := &d.cfg.Finders
+	layer := func(l **FinderLayerStyle) fieldFunc {
+		return d.table(func(t *toml.TableValue, name string) error {
+			*l = &FinderLayerStyle{}
+			return d.fields(t, name, map[string]fieldFunc{
+				"shape": d.shapeField(&(*l).Shape),
+				"color": d.colorField(&(*l).Color),
+				"corner_radius": func(v *toml.Value) (err error) {
+					(*l).CornerRadius, err = d.float(v)
+					return err
+				},
+			})
+		})
+	}
+	return d.fields(t, name, map[string]fieldFunc{
+		"shape":  d.shapeField(&f.Shape),
+		"color":  d.colorField(&f.Color),
+		"outer":  layer(&f.Outer),
+		"middle": layer(&f.Middle),
+		"center": layer(&f.Center),
+	})
+}
+
+func (d *configDecoder) decodeAlignment(t *toml.TableValue, name string) error {
+	a := &d.cfg.Alignment
+	layer := func(l **AlignmentLayerStyle) fieldFunc {
+		return d.table(func(t *toml.TableValue, name string) error {
+			*l = &AlignmentLayerStyle{}
+			return d.fields(t, name, map[string]fieldFunc{
+				"shape": d.shapeField(&(*l).Shape),
+				"color": d.colorField(&(*l).Color),
+			})
+		})
+	}
+	return d.fields(t, name, map[string]fieldFunc{
+		"shape":  d.shapeField(&a.Shape),
+		"color":  d.colorField(&a.Color),
+		"outer":  layer(&a.Outer),
+		"center": layer(&a.Center),
+	})
+}
+
+func (d *configDecoder) decodeLogo(t *toml.TableValue, name string) error {
+	logo := &LogoConfig{}
+	d.cfg.Logo = logo
+	return d.fields(t, name, map[string]fieldFunc{
+		"path": d.pathField(&logo.Path),
+		"width": func(v *toml.Value) (err error) {
+			logo.Width, err = d.int(v)
+			return err
+		},
+		"height": func(v *toml.Value) (err error) {
+			logo.Height, err = d.int(v)
+			return err
+		},
+		"size": func(v *toml.Value) (err error) {
+			logo.Size, err = d.float(v)
+			return err
+		},
+		"padding": func(v *toml.Value) (err error) {
+			logo.Padding, err = d.float(v)
+			return err
+		},
+		"background": func(v *toml.Value) (err error) {
+			logo.Background, err = d.str(v)
+			return err
+		},
+		"policy": func(v *toml.Value) error {
+			s, err := d.str(v)
+			if err != nil {
+				return err
+			}
+			policies := map[string]LogoPolicy{"raise": LogoRaise, "shrink": LogoShrink, "fail": LogoFail, "ignore": LogoIgnore}
+			policy, ok := policies[strings.ToLower(s)]
+			if !ok {
+				return d.errorf(v.Pos, "%s: unknown policy %q (expected raise, shrink, fail or ignore)", v.Key, s)
+			}
+			logo.Policy = policy
+			return nil
+		},
+		"overlay": func(v *toml.Value) (err error) {
+			logo.Overlay, err = d.bool(v)
+			return err
+		},
+	})
+}
+
+func (d *configDecoder) decodeSpot(t *toml.TableValue, name string) error {
+	spot := &SpotColor{}
+	d.cfg.Spot = spot
+	component := func(c *float64) fieldFunc {
+		return func(v *toml.Value) (err error) {
+			*c, err = d.float(v)
+			return err
+		}
+	}
+	return d.fields(t, name, map[string]fieldFunc{
+		"name": func(v *toml.Value) (err error) {
+			spot.Name, err = d.str(v)
+			return err
+		},
+		"c": component(&spot.C),
+		"m": component(&spot.M),
+		"y": component(&spot.Y),
+		"k": component(&spot.K),
+	})
+}
+
+func (d *configDecoder) decodeImages(t *toml.TableValue, name string) error {
+	images := &CustomImages{}
+	d.cfg.Images = images
+	return d.fields(t, name, map[string]fieldFunc{
+		"module":    d.pathField(&images.Module),
+		"finder":    d.pathField(&images.Finder),
+		"alignment": d.pathField(&images.Alignment),
+	})
+}
+
+// fields decodes every key of t with the matching handler.
+// Keys without a handler are reported at their position.
+func (d *configDecoder) fields(t *toml.TableValue, name string, handlers map[string]fieldFunc) error {
+	for _, key := range t.Keys {
+		v := t.Get(key)
+		fn, ok := handlers[key]
+		if !ok {
+			where := "at top level"
+			if name != "" {
+				where = fmt.Sprintf("in [%s]", name)
+			}
+			return d.errorf(v.KeyPos, "unknown key %q %s (expected %s)", key, where, keyList(handlers))
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// table returns a handler for a key that must hold a table.
+func (d *configDecoder) table(fn func(t *toml.TableValue, name string) error) fieldFunc {
+	return func(v *toml.Value) error {
+		if v.Kind != toml.Table {
+			return d.typeError(v, "table")
+		}
+		return fn(v.Table, v.Key)
+	}
+}
+
+func (d *configDecoder) shapeField(dst *string) fieldFunc {
+	return func(v *toml.Value) error {
+		s, err := d.str(v)
+		if err != nil {
+			return err
+		}
+		if s == "rounded-square" {
+			s = string(ShapeRounded)
+		}
+		if shapes.Get(s) == nil {
+			return d.errorf(v.Pos, "%s: unknown shape %q (expected %s)", v.Key, s, keyList(shapes.Registry))
+		}
+		*dst = s
+		return nil
+	}
+}
+
+func (d *configDecoder) colorField(dst *colors.Color) fieldFunc {
+	return func(v *toml.Value) (err error) {
+		*dst, err = d.color(v)
+		return err
+	}
+}
+
+func (d *configDecoder) pathField(dst *string) fieldFunc {
+	return func(v *toml.Value) error {
+		s, err := d.str(v)
+		if err != nil {
+			return err
+		}
+		*dst = d.resolvePath(s)
+		return nil
+	}
+}
+
+// color decodes a color given as a string or as a typed table.
+func (d *configDecoder) color(v *toml.Value) (colors.Color, error) {
+	if v.Kind == toml.String {
+		if _, err := colors.ToRGBA(v.Str); err != nil {
+			return nil, d.errorf(v.Pos, "%s: %v", v.Key, err)
+		}
+		return colors.NewSolid(v.Str), nil
+	}
+	if v.Kind != toml.Table {
+		return nil, d.typeError(v, "string or table")
+	}
+
+	t := v.Table
+	typ := t.Get("type")
+	if typ == nil {
+		return nil, d.errorf(v.Pos, "%s: missing color type", v.Key)
+	}
+	kind, err := d.str(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	var c colors.Color
+	switch kind {
+	case "solid":
+		var hex string
+		err = d.fields(t, v.Key, map[string]fieldFunc{
+			"type": ignoreField,
+			"color": func(v *toml.Value) (err error) {
+				hex, err = d.colorString(v)
+				return err
+			},
+		})
+		c = colors.NewSolid(hex)
+
+	case "linear-gradient":
+		var angle float64
+		var stops []string
+		err = d.fields(t, v.Key, map[string]fieldFunc{
+			"type": ignoreField,
+			"angle": func(v *toml.Value) (err error) {
+				angle, err = d.float(v)
+				return err
+			},
+			"stops": func(v *toml.Value) (err error) {
+				stops, err = d.stops(v)
+				return err
+			},
+		})
+		c = colors.NewLinearGradient(angle, stops)
+
+	case "radial-gradient":
+		cx, cy := 0.5, 0.5
+		var stops []string
+		err = d.fields(t, v.Key, map[string]fieldFunc{
+			"type": ignoreField,
+			"cx": func(v *toml.Value) (err error) {
+				cx, err = d.float(v)
+				return err
+			},
+			"cy": func(v *toml.Value) (err error) {
+				cy, err = d.float(v)
+				return err
+			},
+			"stops": func(v *toml.Value) (err error) {
+				stops, err = d.stops(v)
+				return err
+			},
+		})
+		c = colors.NewRadialGradient(cx, cy, stops)
+
+	case "image":
+		var path string
+		var pathPos toml.Position
+		var darken float64
+		err = d.fields(t, v.Key, map[string]fieldFunc{
+			"type": ignoreField,
+			"path": func(v *toml.Value) (err error) {
+				path, err = d.str(v)
+				pathPos = v.Pos
+				return err
+			},
+			"darken": func(v *toml.Value) (err error) {
+				darken, err = d.float(v)
+				if err == nil && (darken < 0 || darken > 1) {
+					err = d.errorf(v.Pos, "%s: must be between 0 and 1", v.Key)
+				}
+				return err
+			},
+		})
+		if err == nil && path != "" {
+			c, err = colors.LoadImage(d.resolvePath(path), darken)
+			if err != nil {
+				err = d.errorf(pathPos, "%s.path: %v", v.Key, err)
+			}
+		}
+
+	default:
+		return nil, d.errorf(typ.Pos, "%s: unsupported color type %q (expected solid, linear-gradient, radial-gradient or image)", typ.Key, kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Required keys are checked after the others so typos are reported first
+	if kind == "solid" && t.Get("color") == nil {
+		return nil, d.errorf(v.Pos, "%s: missing color", v.Key)
+	}
+	if kind == "image" && t.Get("path") == nil {
+		return nil, d.errorf(v.Pos, "%s: missing path", v.Key)
+	}
+	if strings.HasSuffix(kind, "-gradient") && t.Get("stops") == nil {
+		return nil, d.errorf(v.Pos, "%s: missing stops", v.Key)
+	}
+	return c, nil
+}
+
+func ignoreField(*toml.Value) error { return nil }
+
+// stops decodes an array of at least two color strings.
+func (d *configDecoder) stops(v *toml.Value) ([]string, error) {
+	if v.Kind != toml.Array {
+		return nil, d.typeError(v, "array")
+	}
+	if len(v.Items) < 2 {
+		return nil, d.errorf(v.Pos, "%s: need at least 2 color stops, got %d", v.Key, len(v.Items))
+	}
+	stops := make([]string, len(v.Items))
+	for i, item := range v.Items {
+		s, err := d.colorString(item)
+		if err != nil {
+			return nil, err
+		}
+		stops[i] = s
+	}
+	return stops, nil
+}
+
+// colorString decodes a string holding a valid color.
+func (d *configDecoder) colorString(v *toml.Value) (string, error) {
+	s, err := d.str(v)
+	if err != nil {
+		return "", err
+	}
+	if _, err := colors.ToRGBA(s); err != nil {
+		return "", d.errorf(v.Pos, "%s: %v", v.Key, err)
+	}
+	return s, nil
+}
+
+func (d *configDecoder) str(v *toml.Value) (string, error) {
+	if v.Kind != toml.String {
+		return "", d.typeError(v, "string")
+	}
+	return v.Str, nil
+}
+
+func (d *configDecoder) bool(v *toml.Value) (bool, error) {
+	if v.Kind != toml.Boolean {
+		return false, d.typeError(v, "boolean")
+	}
+	return v.Bool, nil
+}
+
+func (d *configDecoder) int(v *toml.Value) (int, error) {
+	if v.Kind != toml.Integer {
+		return 0, d.typeError(v, "integer")
+	}
+	return int(v.Int), nil
+}
+
+// float accepts integers as well, so size = 1 works.
+func (d *configDecoder) float(v *toml.Value) (float64, error) {
+	switch v.Kind {
+	case toml.Float:
+		return v.Float, nil
+	case toml.Integer:
+		return float64(v.Int), nil
+	}
+	return 0, d.typeError(v, "number")
+}
+
+// resolvePath makes a relative path relative to the config file.
+func (d *configDecoder) resolvePath(path string) string {
+	if d.baseDir == "" || path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(d.baseDir, path)
+}
+
+func (d *configDecoder) typeError(v *toml.Value, want string) error {
+	return d.errorf(v.Pos, "%s: expected %s, got %s", v.Key, want, v.Kind)
+}
+
+func (d *configDecoder) errorf(pos toml.Position, format string, args ...any) error {
+	return &ConfigError{Line: pos.Line, Column: pos.Column, Message: fmt.Sprintf(format, args...)}
+}
+
+// keyList formats the keys of m as a sorted, comma-separated list.
+func keyList[V any](m map[string]V) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
@@ -0,0 +1,235 @@
+package qrgode
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ahmedtahas/qr-gode/internal/colors"
+)
+
+func TestParseConfig(t *testing.T) {
+	cfg, data, err := ParseConfig(strings.NewReader(`
+[qr]
+data = "https://example.com"
+error_correction = "q"
+charset = "auto"
+
+[style]
+size = 400
+quiet_zone = 2
+physical_size = 46
+background = "#f8f9fa"
+
+[style.modules]
+shape = "circle"
+size = 0.85
+
+[style.modules.color]
+type = "radial-gradient"
+cx = 0.4
+stops = ["#ff6b6b", "#4ecdc4"]
+
+[style.finder_patterns]
+shape = "rounded-square"
+
+[style.finder_patterns.center]
+color = "#e17055"
+corner_radius = 0.5
+
+[style.timing]
+color = "#636e72"
+
+[style.logo]
+path = "logo.png"
+size = 0.2
+padding = 0.02
+policy = "shrink"
+overlay = true
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if data != "https://example.com" {
+		t.Errorf("expected data, got %q", data)
+	}
+	if cfg.ErrorCorrection != LevelQ {
+		t.Errorf("expected LevelQ, got %d", cfg.ErrorCorrection)
+	}
+	if cfg.Charset != CharsetAuto {
+		t.Errorf("expected auto charset, got %q", cfg.Charset)
+	}
+	if cfg.Size != 400 || cfg.QuietZone != 2 || cfg.PhysicalSize != 46 {
+		t.Errorf("expected size 400, quiet zone 2 and physical size 46, got %d, %d and %v", cfg.Size, cfg.QuietZone, cfg.PhysicalSize)
+	}
+	if cfg.Modules.Shape != "circle" || cfg.Modules.Size != 0.85 {
+		t.Errorf("unexpected modules %+v", cfg.Modules)
+	}
+	grad, ok := cfg.Modules.Color.(*colors.RadialGradient)
+	if !ok {
+		t.Fatalf("expected radial gradient, got %T", cfg.Modules.Color)
+	}
+	if grad.CenterX != 0.4 || grad.CenterY != 0.5 || len(grad.Stops) != 2 {
+		t.Errorf("unexpected gradient %+v", grad)
+	}
+	if cfg.Finders.Shape != "rounded" {
+		t.Errorf("expected rounded-square alias to map to rounded, got %q", cfg.Finders.Shape)
+	}
+	if c := cfg.Finders.Center; c == nil || c.CornerRadius != 0.5 || c.Color.ColorAt(0, 0) != "#e17055" {
+		t.Errorf("unexpected finder center %+v", c)
+	}
+	if cfg.Timing.Color == nil {
+		t.Error("expected timing color")
+	}
+	if cfg.Logo == nil || cfg.Logo.Path != "logo.png" || cfg.Logo.Size != 0.2 || cfg.Logo.Padding != 0.02 || cfg.Logo.Policy != LogoShrink || !cfg.Logo.Overlay {
+		t.Errorf("unexpected logo %+v", cfg.Logo)
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		line int
+		col  int
+		msg  string
+	}{
+		{"unknown table", "[styles]\nsize = 1", 1, 1, `unknown key "styles" at top level`},
+		{"unknown key", "[style]\nsize = 512\ncolour = \"#000\"", 3, 1, `unknown key "colour" in [style]`},
+		{"nested unknown key", "[style.finder_patterns.outer]\nradius = 0.3", 2, 1, `unknown key "radius" in [style.finder_patterns.outer]`},
+		{"wrong type", "[style]\nsize = \"big\"", 2, 8, "style.size: expected integer, got string"},
+		{"bad level", "[qr]\nerror_correction = \"X\"", 2, 20, "unknown level"},
+		{"bad charset", "[qr]\ncharset = \"ebcdic\"", 2, 11, `unknown character set "ebcdic"`},
+		{"bad symbol", "[qr]\nsymbol = \"aztec\"", 2, 10, `unknown symbol "aztec"`},
+		{"bad boost", "[qr]\nboost_ecl = \"yes\"", 2, 13, "qr.boost_ecl: expected boolean"},
+		{"bad spot", "[style.spot]\nname = \"Gold\"\ncyan = 1", 3, 1, `unknown key "cyan" in [style.spot]`},
+		{"bad shape", "[style.modules]\nshape = \"hexagon\"", 2, 9, `unknown shape "hexagon"`},
+		{"bad logo policy", "[style.logo]\npolicy = \"hide\"", 2, 10, `unknown policy "hide"`},
+		{"bad color", "[style]\nbackground = \"#gg0000\"", 2, 14, "style.background"},
+		{"bad stop", "[style.modules.color]\ntype = \"linear-gradient\"\nstops = [\"#fff\", \"nope\"]", 3, 18, "style.modules.color.stops[1]"},
+		{"unknown color type", "[style.modules.color]\ntype = \"plaid\"", 2, 8, `unsupported color type "plaid"`},
+		{"missing color type", "[style.modules.color]\nstops = [\"#fff\", \"#000\"]", 1, 1, "missing color type"},
+		{"missing stops", "[style.modules.color]\ntype = \"linear-gradient\"", 1, 1, "missing stops"},
+		{"missing image path", "[style.modules.color]\ntype = \"image\"\ndarken = 0.2", 1, 1, "missing path"},
+		{"missing image file", "[style.modules.color]\ntype = \"image\"\npath = \"nope.jpg\"", 3, 8, "style.modules.color.path"},
+		{"darken range", "[style.modules.color]\ntype = \"image\"\ndarken = 2", 3, 10, "must be between 0 and 1"},
+		{"gradient typo", "[style.modules.color]\ntype = \"linear-gradient\"\nangel = 45", 3, 1, `unknown key "angel"`},
+		{"syntax error", "[style]\nsize = ", 2, 8, "missing value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseConfig(strings.NewReader(tt.src))
+			var cerr *ConfigError
+			if !errors.As(err, &cerr) {
+				t.Fatalf("expected *ConfigError, got %v", err)
+			}
+			if cerr.Line != tt.line || cerr.Column != tt.col {
+				t.Errorf("expected %d:%d, got %d:%d (%s)", tt.line, tt.col, cerr.Line, cerr.Column, cerr.Message)
+			}
+			if !strings.Contains(cerr.Message, tt.msg) {
+				t.Errorf("expected message containing %q, got %q", tt.msg, cerr.Message)
+			}
+		})
+	}
+}
+
+func TestParseConfigPrint(t *testing.T) {
+	cfg, _, err := ParseConfig(strings.NewReader("[style]\nphysical_size = 30\ncmyk = true\n[style.spot]\nname = \"PANTONE 286 C\"\nc = 1\nm = 0.66\nk = 0.02"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.PhysicalSize != 30 || !cfg.CMYK {
+		t.Errorf("expected physical size 30 and CMYK, got %v and %v", cfg.PhysicalSize, cfg.CMYK)
+	}
+	want := SpotColor{Name: "PANTONE 286 C", C: 1, M: 0.66, K: 0.02}
+	if cfg.Spot == nil || *cfg.Spot != want {
+		t.Errorf("expected spot color %+v, got %+v", want, cfg.Spot)
+	}
+}
+
+func TestParseConfigSymbol(t *testing.T) {
+	// Micro QR brings its 2-module quiet zone unless one is set
+	cfg, _, err := ParseConfig(strings.NewReader("[qr]\nsymbol = \"micro\""))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Symbol != SymbolMicro || cfg.QuietZone != 2 {
+		t.Errorf("expected Micro QR with quiet zone 2, got %d and %d", cfg.Symbol, cfg.QuietZone)
+	}
+
+	cfg, _, _ = ParseConfig(strings.NewReader("[style]\nquiet_zone = 3\n[qr]\nsymbol = \"micro\""))
+	if cfg.QuietZone != 3 {
+		t.Errorf("expected explicit quiet zone 3, got %d", cfg.QuietZone)
+	}
+
+	cfg, _, err = ParseConfig(strings.NewReader("[qr]\nversion = 7\nmin_version = 3\nmask = 2\nboost_ecl = true\ngs1 = true"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Version != 7 || cfg.MinVersion != 3 || cfg.Mask != 2 {
+		t.Errorf("expected version 7, min version 3 and mask 2, got %d, %d and %d", cfg.Version, cfg.MinVersion, cfg.Mask)
+	}
+	if !cfg.BoostECL || !cfg.GS1 {
+		t.Error("expected boost_ecl and gs1 to be set")
+	}
+
+	cfg, _, err = ParseConfig(strings.NewReader("[qr]\nsymbol = \"rmqr\"\nmax_height = 9"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Mask != -1 {
+		t.Errorf("expected automatic mask by default, got %d", cfg.Mask)
+	}
+	if cfg.Symbol != SymbolRMQR || cfg.MaxHeight != 9 {
+		t.Errorf("expected rMQR up to 9 modules high, got %d and %d", cfg.Symbol, cfg.MaxHeight)
+	}
+}
+
+func TestLoadConfigExamples(t *testing.T) {
+	for _, name := range []string{"minimal", "gradient", "detailed-finders", "image-sampled"} {
+		t.Run(name, func(t *testing.T) {
+			cfg, data, err := LoadConfig(filepath.Join("examples", "configs", name+".toml"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if data == "" {
+				t.Error("expected data from [qr] table")
+			}
+			if _, err := Generate(data, cfg); err != nil {
+				t.Errorf("failed to generate: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigResolvesPaths(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "style.toml")
+	if err := os.WriteFile(path, []byte("[style.logo]\npath = \"./logo.png\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, _, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := filepath.Join(dir, "logo.png"); cfg.Logo.Path != want {
+		t.Errorf("expected %s, got %s", want, cfg.Logo.Path)
+	}
+}
+
+func TestLoadConfigErrorIncludesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.toml")
+	if err := os.WriteFile(path, []byte("[style]\nshape = \"circle\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := LoadConfig(path)
+	if err == nil || !strings.HasPrefix(err.Error(), path+":2:1: ") {
+		t.Errorf("expected error prefixed with %s:2:1, got %v", path, err)
+	}
+}
@@ -0,0 +1,180 @@
+package qrgode
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ahmedtahas/qr-gode/internal/encoder"
+)
+
+// logoShrinkSteps is the number of steps LogoShrink takes from the
+// configured logo size down to nothing.
+const logoShrinkSteps = 20
+
+// LogoCoverageError is returned when a logo hides more codewords of a
+// Reed-Solomon block than the error correction level can recover, and
+// LogoConfig.Policy could not make room for it.
+type LogoCoverageError struct {
+	Level       ErrorCorrectionLevel // Error correction level of the symbol
+	Block       int                  // Worst damaged block, from 1
+	Damaged     int                  // Codewords of the block behind the logo
+	Correctable int                  // Codeword errors the block can correct
+}
+
+func (e *LogoCoverageError) Error() string {
+	return fmt.Sprintf("logo hides %d codewords of block %d, level %s corrects %d",
+		e.Damaged, e.Block, e.Level, e.Correctable)
+}
+
+// encodeRenderer encodes data with a validated config, as part of a
+// Structured Append sequence if sa is not nil, and returns a renderer for
+// it. GS1 data is converted to its element string first. A logo that
+// hides more than the symbol recovers is handled by Logo.Policy.
+func encodeRenderer(data string, cfg *Config, sa *encoder.StructuredAppend) (*renderer, error) {
+	if cfg.GS1 {
+		var err error
+		if data, err = gs1Data(data); err != nil {
+			return nil, err
+		}
+	}
+	r, err := encodeSymbol(data, cfg, sa, 0)
+	if err != nil {
+		return nil, err
+	}
+	if !r.hasLogo() || cfg.Logo.Policy == LogoIgnore {
+		return r, nil
+	}
+	coverage, err := r.logoCoverage()
+	if err != nil || coverage == nil {
+		return r, err
+	}
+
+	switch cfg.Logo.Policy {
+	case LogoRaise:
+		return raiseForLogo(data, cfg, sa, r.symbol, coverage)
+	case LogoShrink:
+		return r.shrinkLogo(coverage)
+	}
+	return nil, coverage
+}
+
+// encodeSymbol encodes data at minVersion or above and returns a
+// renderer for it.
+func encodeSymbol(data string, cfg *Config, sa *encoder.StructuredAppend, minVersion int) (*renderer, error) {
+	enc := newEncoder(data, cfg)
+	enc.SetStructuredAppend(sa)
+	enc.SetMinVersion(max(cfg.MinVersion, minVersion))
+	matrix, err := enc.Encode()
+	if err != nil {
+		return nil, err
+	}
+	r := newRenderer(matrix, cfg)
+	r.layout = enc.Layout()
+	r.symbol = newSymbol(enc, matrix, cfg)
+	return r, nil
+}
+
+// raiseForLogo tries the error correction levels above the one of symbol
+// and then, for QR Codes without a fixed version, larger versions at
+// the highest level that fits until the logo leaves every block
+// recoverable. It returns the last coverage if nothing does.
+func raiseForLogo(data string, cfg *Config, sa *encoder.StructuredAppend, symbol *Symbol, coverage *LogoCoverageError) (*renderer, error) {
+	raised := *cfg
+	raised.ErrorCorrection = symbol.ErrorCorrection
+	levels := raisedLevels(cfg.Symbol, symbol.ErrorCorrection)
+	version, minVersion := symbol.Version, 0
+	for {
+		last := raised.ErrorCorrection
+		switch {
+		case len(levels) > 0:
+			raised.ErrorCorrection, levels = levels[0], levels[1:]
+		case cfg.Symbol == SymbolQR && cfg.Version == 0 && version < 40:
+			minVersion = version + 1
+		default:
+			return nil, coverage
+		}
+
+		r, err := encodeSymbol(data, &raised, sa, minVersion)
+		tooLong := errors.Is(err, encoder.ErrDataTooLong) || errors.Is(err, encoder.ErrVersionTooSmall)
+		if tooLong && minVersion == 0 {
+			// Higher levels do not fit either, raise the version instead
+			raised.ErrorCorrection, levels = last, nil
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		c, err := r.logoCoverage()
+		if err != nil || c == nil {
+			return r, err
+		}
+		coverage, version = c, r.symbol.Version
+	}
+}
+
+// raisedLevels returns the error correction levels the symbol supports
+// above level, lowest first.
+func raisedLevels(symbol SymbolType, level ErrorCorrectionLevel) []ErrorCorrectionLevel {
+	var levels []ErrorCorrectionLevel
+	for l := level + 1; l <= LevelH; l++ {
+		switch {
+		case symbol == SymbolMicro && l == LevelH:
+			continue
+		case symbol == SymbolRMQR && l != LevelH:
+			continue
+		}
+		levels = append(levels, l)
+	}
+	return levels
+}
+
+// shrinkLogo shrinks the logo step by step until every block recovers.
+// It returns the last coverage if no size does.
+func (r *renderer) shrinkLogo(coverage *LogoCoverageError) (*renderer, error) {
+	for step := 1; step < logoShrinkSteps; step++ {
+		r.logoScale = 1 - float64(step)/logoShrinkSteps
+		c, err := r.logoCoverage()
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			return r, nil
+		}
+		coverage = c
+	}
+	return nil, coverage
+}
+
+// logoCoverage counts the codewords the logo exclusion zone, or the
+// modules under an overlay logo, hide in each block. It returns a
+// *LogoCoverageError for the worst block if any cannot be recovered, or
+// nil if all can.
+func (r *renderer) logoCoverage() (*LogoCoverageError, error) {
+	minX, minY, maxX, maxY, active, err := r.calculateExclusionZone()
+	if err == nil && r.hasLogo() && r.config.Logo.Overlay {
+		minX, minY, maxX, maxY, err = r.overlayZone()
+		active = err == nil
+	}
+	if err != nil || !active {
+		return nil, err
+	}
+	damage := r.layout.Damage(r.matrix, func(x, y int) bool {
+		return x >= minX && x <= maxX && y >= minY && y <= maxY
+	})
+
+	var worst *LogoCoverageError
+	for i, d := range damage {
+		if d.Recoverable() {
+			continue
+		}
+		if worst == nil || d.Damaged-d.Correctable > worst.Damaged-worst.Correctable {
+			worst = &LogoCoverageError{
+				Level:       r.symbol.ErrorCorrection,
+				Block:       i + 1,
+				Damaged:     d.Damaged,
+				Correctable: d.Correctable,
+			}
+		}
+	}
+	return worst, nil
+}
@@ -0,0 +1,108 @@
+package qrgode
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+)
+
+// testLogo returns a square logo for policy tests.
+func testLogo() image.Image {
+	logo := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	draw.Draw(logo, logo.Bounds(), image.NewUniform(color.RGBA{30, 30, 200, 255}), image.Point{}, draw.Src)
+	return logo
+}
+
+// largeLogoCode returns a level L code with a 30% logo, which hides far
+// more than level L recovers.
+func largeLogoCode(policy LogoPolicy) *QRCode {
+	q := New("https://example.com").ErrorCorrection(LevelL).LogoImage(testLogo()).Size(400).LogoPolicy(policy)
+	q.config.Logo.Size = 0.3
+	return q
+}
+
+func TestLogoPolicyFail(t *testing.T) {
+	_, err := largeLogoCode(LogoFail).SVG()
+	var cerr *LogoCoverageError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("expected *LogoCoverageError, got %v", err)
+	}
+	if cerr.Level != LevelL || cerr.Damaged <= cerr.Correctable {
+		t.Errorf("unexpected coverage %+v", cerr)
+	}
+}
+
+func TestLogoPolicyRaise(t *testing.T) {
+	q := largeLogoCode(LogoRaise)
+	r, err := q.renderer()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.config.ErrorCorrection != LevelH {
+		t.Errorf("expected level raised to H, got %s", r.config.ErrorCorrection)
+	}
+	if q.config.ErrorCorrection != LevelL {
+		t.Error("expected the builder config to keep level L")
+	}
+	if err := q.Verify(); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
+
+func TestLogoPolicyShrink(t *testing.T) {
+	q := largeLogoCode(LogoShrink)
+	r, err := q.renderer()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.config.ErrorCorrection != LevelL || r.logoScale >= 1 {
+		t.Errorf("expected a smaller logo at level L, got scale %.2f at %s", r.logoScale, r.config.ErrorCorrection)
+	}
+	if err := q.Verify(); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
+
+func TestLogoPolicyIgnore(t *testing.T) {
+	if _, err := largeLogoCode(LogoIgnore).SVG(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestLogoPolicyRaiseSequence(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ErrorCorrection = LevelL
+	cfg.Logo = &LogoConfig{Image: testLogo(), Size: 0.25}
+	svgs, err := GenerateStructuredAppend(strings.Repeat("Structured Append with a logo. ", 100), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(svgs) < 2 {
+		t.Errorf("expected a sequence, got %d symbols", len(svgs))
+	}
+}
+
+func TestLogoOverlay(t *testing.T) {
+	// The modules under an overlay still count against error correction
+	_, err := largeLogoCode(LogoFail).LogoOverlay().SVG()
+	var cerr *LogoCoverageError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("expected *LogoCoverageError, got %v", err)
+	}
+
+	// but are drawn rather than cleared
+	cleared, err := largeLogoCode(LogoIgnore).SVG()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	overlay, err := largeLogoCode(LogoIgnore).LogoOverlay().SVG()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(overlay) <= len(cleared) {
+		t.Errorf("expected the modules behind the logo drawn, got %d and %d bytes", len(overlay), len(cleared))
+	}
+}
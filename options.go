@@ -10,6 +10,65 @@ func WithErrorCorrection(level ErrorCorrectionLevel) Option {
 	}
 }
 
+// WithBoostECL raises the error correction level as far as the data
+// still fits the same version.
+func WithBoostECL() Option {
+	return func(c *Config) {
+		c.BoostECL = true
+	}
+}
+
+// WithGS1 reads the data as GS1 element strings in human readable form.
+func WithGS1() Option {
+	return func(c *Config) {
+		c.GS1 = true
+	}
+}
+
+// WithSymbol sets the symbol type and its standard quiet zone.
+func WithSymbol(symbol SymbolType) Option {
+	return func(c *Config) {
+		c.Symbol = symbol
+		c.QuietZone = symbol.quietZone()
+	}
+}
+
+// WithMaxHeight limits rMQR symbols to at most the given number of
+// modules high.
+func WithMaxHeight(modules int) Option {
+	return func(c *Config) {
+		c.MaxHeight = modules
+	}
+}
+
+// WithVersion fixes the symbol version.
+func WithVersion(version int) Option {
+	return func(c *Config) {
+		c.Version = version
+	}
+}
+
+// WithMinVersion sets the smallest symbol version to use.
+func WithMinVersion(version int) Option {
+	return func(c *Config) {
+		c.MinVersion = version
+	}
+}
+
+// WithMask fixes the mask pattern, or selects the best with -1.
+func WithMask(mask int) Option {
+	return func(c *Config) {
+		c.Mask = mask
+	}
+}
+
+// WithCharset sets the character set announced with an ECI header.
+func WithCharset(name string) Option {
+	return func(c *Config) {
+		c.Charset = name
+	}
+}
+
 // WithSize sets the output size in pixels.
 func WithSize(size int) Option {
 	return func(c *Config) {
@@ -17,6 +76,29 @@ func WithSize(size int) Option {
 	}
 }
 
+// WithPhysicalSize sets the printed width of PDF and EPS output in
+// millimetres.
+func WithPhysicalSize(mm float64) Option {
+	return func(c *Config) {
+		c.PhysicalSize = mm
+	}
+}
+
+// WithCMYK writes the colors of EPS output as CMYK.
+func WithCMYK() Option {
+	return func(c *Config) {
+		c.CMYK = true
+	}
+}
+
+// WithSpotColor prints the modules of EPS output in a named spot color
+// ink with the given CMYK equivalent.
+func WithSpotColor(name string, cyan, magenta, yellow, black float64) Option {
+	return func(c *Config) {
+		c.Spot = &SpotColor{Name: name, C: cyan, M: magenta, Y: yellow, K: black}
+	}
+}
+
 // WithQuietZone sets the margin around the QR code.
 func WithQuietZone(modules int) Option {
 	return func(c *Config) {
@@ -0,0 +1,318 @@
+package qrgode
+
+import (
+	"strings"
+
+	"github.com/ahmedtahas/qr-gode/internal/colors"
+	"github.com/ahmedtahas/qr-gode/internal/encoder"
+	"github.com/ahmedtahas/qr-gode/internal/shapes"
+)
+
+// layered reports whether finder patterns are drawn as three concentric
+// shapes instead of module by module.
+func (s FinderStyle) layered() bool {
+	return s.Shape != "" || s.Outer != nil || s.Middle != nil || s.Center != nil
+}
+
+// layered reports whether alignment patterns are drawn as concentric
+// shapes instead of module by module.
+func (s AlignmentStyle) layered() bool {
+	return s.Shape != "" || s.Outer != nil || s.Center != nil
+}
+
+// patternLayer is a resolved shape and color for one layer of a pattern.
+type patternLayer struct {
+	shape shapes.Shape
+	color colors.Color
+}
+
+// resolveLayer applies the layer > pattern > module precedence for a
+// pattern layer's shape and color.
+func resolveLayer(shapeName string, cornerRadius float64, color colors.Color, style patternLayer) patternLayer {
+	layer := style
+	if shapeName != "" || cornerRadius > 0 {
+		layer.shape = resolveShape(shapeName, cornerRadius)
+	}
+	if color != nil {
+		layer.color = color
+	}
+	return layer
+}
+
+// resolveShape looks up a registered shape, falling back to square.
+// A positive corner radius turns square-like shapes into rounded squares.
+func resolveShape(name string, cornerRadius float64) shapes.Shape {
+	if cornerRadius > 0 {
+		switch name {
+		case "", "square", "rounded", "rounded-square":
+			return shapes.NewRoundedSquare(cornerRadius)
+		}
+	}
+	if s := shapes.Get(name); s != nil {
+		return s
+	}
+	return shapes.Get("square")
+}
+
+// moduleGroup collects modules drawn with the same shape and color.
+type moduleGroup struct {
+	id      string
+	shape   shapes.Shape
+	color   colors.Color
+	svgPath string // Cached unit path of shape
+	path    strings.Builder
+
+	// Per-module colors get one path per sampled color
+	sampled map[string]*strings.Builder
+	order   []string
+}
+
+// pathAt returns the path to add the module at matrix position (x, y) to.
+func (g *moduleGroup) pathAt(x, y, width, height int) *strings.Builder {
+	if !colors.IsPerModule(g.color) {
+		return &g.path
+	}
+	hex := g.color.ColorAt((float64(x)+0.5)/float64(width), (float64(y)+0.5)/float64(height))
+	path := g.sampled[hex]
+	if path == nil {
+		if g.sampled == nil {
+			g.sampled = make(map[string]*strings.Builder)
+		}
+		path = &strings.Builder{}
+		g.sampled[hex] = path
+		g.order = append(g.order, hex)
+	}
+	return path
+}
+
+// layers returns the fill layers of the group, one per sampled color for
+// per-module colors.
+func (g *moduleGroup) layers() []fillLayer {
+	if g.sampled == nil {
+		if g.path.Len() == 0 {
+			return nil
+		}
+		return []fillLayer{{id: g.id, color: g.color, path: g.path.String()}}
+	}
+	layers := make([]fillLayer, 0, len(g.order))
+	for _, hex := range g.order {
+		layers = append(layers, fillLayer{id: g.id, color: colors.NewSolid(hex), path: g.sampled[hex].String()})
+	}
+	return layers
+}
+
+// layerSet merges paths into layers with the same id and fill, keeping
+// the order in which layers were first added.
+type layerSet struct {
+	layers []fillLayer
+	index  map[string]int
+}
+
+func (s *layerSet) add(layer fillLayer) {
+	key := layer.id + " " + layer.color.SVGFill(layer.id)
+	if i, ok := s.index[key]; ok {
+		s.layers[i].path += layer.path
+		return
+	}
+	if s.index == nil {
+		s.index = make(map[string]int)
+	}
+	s.index[key] = len(s.layers)
+	s.layers = append(s.layers, layer)
+}
+
+// sampleAt resolves a per-module color to the solid color at matrix
+// position (x, y). Other colors are returned unchanged.
+func (r *renderer) sampleAt(c colors.Color, x, y float64) colors.Color {
+	if !colors.IsPerModule(c) {
+		return c
+	}
+	return colors.NewSolid(c.ColorAt(x/float64(r.matrix.Width()), y/float64(r.matrix.Height())))
+}
+
+// finderLayers draws the finder patterns as outer ring, optional middle
+// fill and center eye.
+func (r *renderer) finderLayers(moduleColor colors.Color) []fillLayer {
+	style := r.config.Finders
+
+	base := patternLayer{shape: resolveShape(style.Shape, 0), color: moduleColor}
+	if style.Color != nil {
+		base.color = style.Color
+	}
+
+	outer, middle, center := base, base, base
+	var hasMiddle bool
+	if l := style.Outer; l != nil {
+		outer = resolveLayer(l.Shape, l.CornerRadius, l.Color, base)
+	}
+	// The gap between ring and eye follows the middle shape if one is set
+	hole := outer.shape
+	if l := style.Middle; l != nil {
+		middle = resolveLayer(l.Shape, l.CornerRadius, l.Color, base)
+		if l.Shape != "" || l.CornerRadius > 0 {
+			hole = middle.shape
+		}
+		hasMiddle = l.Color != nil
+	}
+	if l := style.Center; l != nil {
+		center = resolveLayer(l.Shape, l.CornerRadius, l.Color, base)
+	}
+
+	moduleSize := r.moduleSize()
+	quietZone := float64(r.config.QuietZone)
+
+	// Per-module colors are sampled once per pattern, at its center
+	var set layerSet
+	for _, origin := range r.matrix.Finders() {
+		px := (quietZone + float64(origin[0])) * moduleSize
+		py := (quietZone + float64(origin[1])) * moduleSize
+		cx, cy := float64(origin[0])+3.5, float64(origin[1])+3.5
+
+		set.add(fillLayer{
+			id:      "finder-outer-fill",
+			color:   r.sampleAt(outer.color, cx, cy),
+			path:    transformPath(outer.shape.SVGPath(), px, py, 7*moduleSize) + transformPath(hole.SVGPath(), px+moduleSize, py+moduleSize, 5*moduleSize),
+			evenOdd: true,
+		})
+		if hasMiddle {
+			set.add(fillLayer{
+				id:    "finder-middle-fill",
+				color: r.sampleAt(middle.color, cx, cy),
+				path:  transformPath(middle.shape.SVGPath(), px+moduleSize, py+moduleSize, 5*moduleSize),
+			})
+		}
+		set.add(fillLayer{
+			id:    "finder-center-fill",
+			color: r.sampleAt(center.color, cx, cy),
+			path:  transformPath(center.shape.SVGPath(), px+2*moduleSize, py+2*moduleSize, 3*moduleSize),
+		})
+	}
+	return set.layers
+}
+
+// alignmentLayers draws alignment patterns as an outer ring and center dot.
+// Patterns whose center falls in the logo zone are skipped.
+func (r *renderer) alignmentLayers(moduleColor colors.Color, inLogoZone func(x, y int) bool) []fillLayer {
+	style := r.config.Alignment
+
+	base := patternLayer{shape: resolveShape(style.Shape, 0), color: moduleColor}
+	if style.Color != nil {
+		base.color = style.Color
+	}
+
+	outer, center := base, base
+	if l := style.Outer; l != nil {
+		outer = resolveLayer(l.Shape, 0, l.Color, base)
+	}
+	if l := style.Center; l != nil {
+		center = resolveLayer(l.Shape, 0, l.Color, base)
+	}
+
+	moduleSize := r.moduleSize()
+	quietZone := r.config.QuietZone
+
+	var set layerSet
+	for _, pos := range r.matrix.Alignments() {
+		ax, ay := pos[0], pos[1]
+		if inLogoZone(ax, ay) {
+			continue
+		}
+		px := float64(quietZone+ax-2) * moduleSize
+		py := float64(quietZone+ay-2) * moduleSize
+		cx, cy := float64(ax)+0.5, float64(ay)+0.5
+
+		set.add(fillLayer{
+			id:      "alignment-outer-fill",
+			color:   r.sampleAt(outer.color, cx, cy),
+			path:    transformPath(outer.shape.SVGPath(), px, py, 5*moduleSize) + transformPath(outer.shape.SVGPath(), px+moduleSize, py+moduleSize, 3*moduleSize),
+			evenOdd: true,
+		})
+		set.add(fillLayer{
+			id:    "alignment-center-fill",
+			color: r.sampleAt(center.color, cx, cy),
+			path:  transformPath(center.shape.SVGPath(), px+2*moduleSize, py+2*moduleSize, moduleSize),
+		})
+	}
+	return set.layers
+}
+
+// moduleGroups sorts the dark modules drawn one by one into groups by
+// shape and color. Finder and alignment modules are left out when those
+// patterns are drawn as layers.
+func (r *renderer) moduleGroups(moduleShape shapes.Shape, moduleColor colors.Color, inLogoZone func(x, y int) bool) []*moduleGroup {
+	cfg := r.config
+	modules := &moduleGroup{id: "module-fill", shape: moduleShape, color: moduleColor}
+	groups := []*moduleGroup{modules}
+	byType := make(map[encoder.ModuleType]*moduleGroup)
+
+	if t := cfg.Timing; t.Shape != "" || t.Color != nil {
+		g := &moduleGroup{id: "timing-fill", shape: moduleShape, color: moduleColor}
+		if t.Shape != "" {
+			g.shape = resolveShape(t.Shape, 0)
+		}
+		if t.Color != nil {
+			g.color = t.Color
+		}
+		groups = append(groups, g)
+		byType[encoder.ModuleTiming] = g
+	}
+	if !cfg.Finders.layered() && cfg.Finders.Color != nil {
+		g := &moduleGroup{id: "finder-fill", shape: moduleShape, color: cfg.Finders.Color}
+		groups = append(groups, g)
+		byType[encoder.ModuleFinder] = g
+	}
+	if !cfg.Alignment.layered() && cfg.Alignment.Color != nil {
+		g := &moduleGroup{id: "alignment-fill", shape: moduleShape, color: cfg.Alignment.Color}
+		groups = append(groups, g)
+		byType[encoder.ModuleAlignment] = g
+	}
+
+	for _, g := range groups {
+		g.svgPath = g.shape.SVGPath()
+	}
+
+	width, height := r.matrix.Width(), r.matrix.Height()
+	quietZone := r.config.QuietZone
+	moduleSize := r.moduleSize()
+
+	// Modules.Size shrinks modules within their cell, except for the
+	// finder and alignment patterns scanners lock onto
+	scaled := moduleSize
+	if s := cfg.Modules.Size; s > 0 && s < 1 {
+		scaled = moduleSize * s
+	}
+	inset := (moduleSize - scaled) / 2
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			mod := r.matrix.Get(x, y)
+			if !mod.Dark || inLogoZone(x, y) {
+				continue
+			}
+			if mod.Type == encoder.ModuleFinder && cfg.Finders.layered() && r.matrix.InFinder(x, y) {
+				continue
+			}
+			if mod.Type == encoder.ModuleAlignment && cfg.Alignment.layered() && r.matrix.InAlignment(x, y) {
+				continue
+			}
+
+			g := byType[mod.Type]
+			if g == nil {
+				g = modules
+			}
+			path := g.pathAt(x, y, width, height)
+
+			// Calculate position with quiet zone offset
+			px := float64(quietZone+x) * moduleSize
+			py := float64(quietZone+y) * moduleSize
+			if mod.Type == encoder.ModuleFinder || mod.Type == encoder.ModuleAlignment {
+				path.WriteString(transformPath(g.svgPath, px, py, moduleSize))
+			} else {
+				path.WriteString(transformPath(g.svgPath, px+inset, py+inset, scaled))
+			}
+			path.WriteString(" ")
+		}
+	}
+
+	return groups
+}
@@ -0,0 +1,128 @@
+package qrgode
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestDefaultSVGSinglePath(t *testing.T) {
+	svg, err := New("test").SVGString()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := strings.Count(svg, "<path"); n != 1 {
+		t.Errorf("expected 1 path by default, got %d", n)
+	}
+}
+
+func TestFinderLayersSVG(t *testing.T) {
+	svg, err := New("test").
+		FinderShape(ShapeCircle).
+		FinderOuter("", "#ff0000").
+		FinderCenter(ShapeDiamond, "#00ff00").
+		SVGString()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	checks := []string{
+		`fill="#ff0000" fill-rule="evenodd"`,
+		`fill="#00ff00"`,
+	}
+	for _, want := range checks {
+		if !strings.Contains(svg, want) {
+			t.Errorf("expected SVG to contain %q", want)
+		}
+	}
+	if strings.Contains(svg, "finder-middle-fill") {
+		t.Error("middle layer should not be filled without a color")
+	}
+}
+
+func TestFinderLayersPNG(t *testing.T) {
+	// Version 1 with quiet zone 4 is 29 modules, 10px each
+	img, err := New("test").
+		Size(290).
+		FinderOuter("", "#ff0000").
+		FinderMiddle("", "#0000ff").
+		FinderCenter("", "#00ff00").
+		Image()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		x, y int
+		want color.RGBA
+	}{
+		{"outer", 45, 45, color.RGBA{255, 0, 0, 255}},
+		{"middle", 55, 55, color.RGBA{0, 0, 255, 255}},
+		{"center", 75, 75, color.RGBA{0, 255, 0, 255}},
+		{"top-right center", 215, 75, color.RGBA{0, 255, 0, 255}},
+	}
+	for _, tt := range tests {
+		if got := pixelAt(img, tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestFinderCornerRadius(t *testing.T) {
+	img, err := New("test").Size(290).FinderCornerRadius(0.5).Image()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A fully rounded outer ring leaves the corner pixel white
+	if got := pixelAt(img, 40, 40); got != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("expected rounded corner to be background, got %v", got)
+	}
+	if got := pixelAt(img, 75, 75); got != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("expected center eye to be dark, got %v", got)
+	}
+}
+
+func TestTimingColor(t *testing.T) {
+	img, err := New("test").Size(290).TimingColor("#ff00ff").Image()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Horizontal timing module at (8, 6) is dark
+	if got := pixelAt(img, 125, 105); got != (color.RGBA{255, 0, 255, 255}) {
+		t.Errorf("expected timing color, got %v", got)
+	}
+}
+
+func TestAlignmentLayersSVG(t *testing.T) {
+	// Long enough to need an alignment pattern
+	svg, err := New(strings.Repeat("alignment", 5)).
+		AlignmentOuter(ShapeCircle, "#123456").
+		AlignmentCenter("", "#654321").
+		SVGString()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(svg, `fill="#123456" fill-rule="evenodd"`) {
+		t.Error("expected alignment outer ring with evenodd fill")
+	}
+	if !strings.Contains(svg, `fill="#654321"`) {
+		t.Error("expected alignment center color")
+	}
+}
+
+func TestFinderColorWithoutShape(t *testing.T) {
+	svg, err := New("test").FinderColor("#abcdef").SVGString()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(svg, `fill="#abcdef"`) {
+		t.Error("expected finder modules in finder color")
+	}
+	if strings.Contains(svg, "evenodd") {
+		t.Error("finder color alone should keep module-by-module drawing")
+	}
+}
@@ -0,0 +1,229 @@
+package qrgode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/ahmedtahas/qr-gode/internal/encoder"
+	"github.com/ahmedtahas/qr-gode/payload"
+)
+
+// NewPayload creates a QR code generator for structured data such as a
+// WiFi network or a contact, encoded in the format scanners recognize.
+// Invalid payload fields are reported as a *payload.Error when
+// generating.
+func NewPayload(p payload.Payload) *QRCode {
+	data, err := p.Encode()
+	q := New(data)
+	q.payload = p
+	if err != nil {
+		q.errs = append(q.errs, err)
+	}
+	return q
+}
+
+// NewWiFi creates a QR code generator that joins a WiFi network when
+// scanned.
+//
+// Example:
+//
+//	qr := qrgode.NewWiFi(payload.WiFi{SSID: "Office", Password: "correct horse"})
+//	png, err := qr.PNG()
+func NewWiFi(w payload.WiFi) *QRCode {
+	return NewPayload(w)
+}
+
+// NewVCard creates a QR code generator for a contact as a vCard.
+//
+// Example:
+//
+//	qr := qrgode.NewVCard(payload.VCard{
+//		GivenName:  "Jane",
+//		FamilyName: "Doe",
+//		Phones:     []payload.Phone{{Number: "+1 555 0100", Types: []string{"cell"}}},
+//	}).FitVersion(10)
+func NewVCard(v payload.VCard) *QRCode {
+	return NewPayload(v)
+}
+
+// NewMeCard creates a QR code generator for a contact as a MeCard, which
+// is more compact than a vCard.
+func NewMeCard(m payload.MeCard) *QRCode {
+	return NewPayload(m)
+}
+
+// NewEPC creates a QR code generator for a SEPA credit transfer in the
+// EPC069-12 format, known as GiroCode, read by European banking apps.
+// The standard asks for error correction level M and at most version 13:
+// the level is set, and generating fails if it is changed, raised by
+// BoostECL or a logo, or the data needs a larger version. Character sets
+// other than UTF-8 are also announced with an ECI header.
+//
+// Example:
+//
+//	qr := qrgode.NewEPC(payload.EPC{
+//		Name:   "Red Cross of Belgium",
+//		IBAN:   "BE72 0000 0000 1616",
+//		Amount: 12.34,
+//		Text:   "Urgency fund",
+//	})
+func NewEPC(e payload.EPC) *QRCode {
+	q := NewPayload(e).ErrorCorrection(LevelM)
+	level := LevelM
+	q.fixedLevel = &level
+	q.fitVersion = 13
+	if e.Charset > payload.EPCUTF8 {
+		q.Charset(e.Charset.Name())
+	}
+	return q
+}
+
+// NewSwissQRBill creates a QR code generator for the payment part of a
+// Swiss QR-bill, read by Swiss banking apps. As the guidelines ask, the
+// code uses error correction level M, at most version 25, is 46 × 46 mm
+// in PDF and EPS output and carries a 7 × 7 mm Swiss cross drawn over
+// its center. Generating fails if the level is changed or the cross
+// hides more than level M recovers.
+//
+// The quiet zone is left out, as the payment part keeps a 5 mm margin
+// around the code; adding one with QuietZone shrinks the code and its
+// cross below their required sizes.
+//
+// Example:
+//
+//	qr := qrgode.NewSwissQRBill(payload.SwissQRBill{
+//		IBAN:      "CH44 3199 9123 0008 8901 2",
+//		Creditor:  payload.SwissAddress{Name: "Robert Schneider AG", PostalCode: "2501", Town: "Biel", Country: "CH"},
+//		Amount:    1949.75,
+//		Reference: "210000000003139471430009017",
+//	})
+//	pdf, err := qr.PDF()
+func NewSwissQRBill(b payload.SwissQRBill) *QRCode {
+	q := NewPayload(b).ErrorCorrection(LevelM).PhysicalSize(swissQRSize).QuietZone(0)
+	level := LevelM
+	q.fixedLevel = &level
+	q.fitVersion = 25
+	q.config.Logo = &LogoConfig{
+		Image:      swissCross(),
+		Size:       swissCrossSize / swissQRSize,
+		Background: "transparent",
+		Policy:     LogoFail,
+		Overlay:    true,
+	}
+	return q
+}
+
+// Sizes of a Swiss QR-bill code and its cross, in millimetres.
+const (
+	swissQRSize    = 46.0
+	swissCrossSize = 7.0
+)
+
+// swissCross returns the Swiss cross of a QR-bill on a grid of 36
+// units for its 7 mm: a white cross in the proportions of the flag, on
+// a black square of 32 units with a white border of 2.
+func swissCross() image.Image {
+	const unit = 8 // Pixels per unit
+	arm := func(across, along int) bool {
+		return across >= 15 && across < 21 && along >= 8 && along < 28
+	}
+	img := image.NewGray(image.Rect(0, 0, 36*unit, 36*unit))
+	for y := 0; y < 36*unit; y++ {
+		for x := 0; x < 36*unit; x++ {
+			ux, uy := x/unit, y/unit
+			black := ux >= 2 && ux < 34 && uy >= 2 && uy < 34
+			if black && !arm(ux, uy) && !arm(uy, ux) {
+				img.SetGray(x, y, color.Gray{})
+			} else {
+				img.SetGray(x, y, color.Gray{Y: 0xff})
+			}
+		}
+	}
+	return img
+}
+
+// FitVersion keeps a payload within the given QR Code version (1-40) at
+// the configured error correction level, by dropping its optional fields
+// least important first, such as the note and addresses of a contact.
+// Generating fails if the required fields alone do not fit. Only
+// payloads from NewPayload and the helpers like NewVCard can be fitted.
+func (q *QRCode) FitVersion(version int) *QRCode {
+	switch {
+	case q.payload == nil:
+		q.errs = append(q.errs, &ValidationError{Field: "FitVersion", Message: "requires a payload from NewPayload"})
+	case version < 1 || version > 40:
+		q.errs = append(q.errs, &ValidationError{Field: "FitVersion", Message: "must be between 1 and 40"})
+	default:
+		q.fitVersion = version
+	}
+	return q
+}
+
+// fittedData returns the data to encode, with optional payload fields
+// dropped until it fits the version set with FitVersion.
+func (q *QRCode) fittedData() (string, error) {
+	if q.fitVersion == 0 {
+		return q.data, nil
+	}
+	if q.config.Symbol != SymbolQR {
+		return "", &ValidationError{Field: "FitVersion", Message: "only applies to QR Code symbols"}
+	}
+
+	if q.fixedLevel != nil && (q.config.ErrorCorrection != *q.fixedLevel || q.config.BoostECL) {
+		return "", q.levelError()
+	}
+
+	ecl := encoder.ErrorCorrectionLevel(q.config.ErrorCorrection)
+	p := q.payload
+	for {
+		data, err := p.Encode()
+		if err != nil {
+			return "", err
+		}
+
+		// Byte mode is the worst case for the segments the encoder picks
+		n := encoder.ModeByte.CharCount(data)
+		if q.config.Charset != "" {
+			n += 2 // Room for an ECI header
+		}
+		if version, err := encoder.DetermineVersion(n, encoder.ModeByte, ecl); err == nil && int(version) <= q.fitVersion {
+			return data, nil
+		}
+
+		t, ok := p.(payload.Trimmer)
+		if !ok {
+			break
+		}
+		if p, ok = t.Trim(); !ok {
+			break
+		}
+	}
+	return "", &ValidationError{
+		Field:   "FitVersion",
+		Message: fmt.Sprintf("required payload fields do not fit version %d at level %s", q.fitVersion, q.config.ErrorCorrection),
+	}
+}
+
+// checkPayloadSymbol checks that the encoded symbol keeps the level and
+// version limits of the payload, which a logo, BoostECL or a fixed
+// version can break.
+func (q *QRCode) checkPayloadSymbol(s *Symbol) error {
+	if q.fixedLevel != nil && s.ErrorCorrection != *q.fixedLevel {
+		return q.levelError()
+	}
+	if q.fitVersion > 0 && s.Version > q.fitVersion {
+		return &ValidationError{
+			Field:   "Version",
+			Message: fmt.Sprintf("must be at most %d for this payload, got %d", q.fitVersion, s.Version),
+		}
+	}
+	return nil
+}
+
+func (q *QRCode) levelError() error {
+	return &ValidationError{
+		Field:   "ErrorCorrection",
+		Message: fmt.Sprintf("must stay level %s for this payload", *q.fixedLevel),
+	}
+}
@@ -0,0 +1,286 @@
+package payload
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ahmedtahas/qr-gode/internal/encoder"
+)
+
+// EPCCharset is the character set of an EPC payload, given by number
+// in its third line.
+type EPCCharset int
+
+const (
+	EPCUTF8     EPCCharset = iota + 1 // UTF-8 (default)
+	EPCLatin1                         // ISO-8859-1, Western European
+	EPCLatin2                         // ISO-8859-2, Central European
+	EPCLatin4                         // ISO-8859-4, Baltic
+	EPCCyrillic                       // ISO-8859-5
+	EPCGreek                          // ISO-8859-7
+	EPCLatin6                         // ISO-8859-10, Nordic
+	EPCLatin9                         // ISO-8859-15, Western European with €
+)
+
+var epcCharsetNames = []string{"utf-8", "iso-8859-1", "iso-8859-2", "iso-8859-4",
+	"iso-8859-5", "iso-8859-7", "iso-8859-10", "iso-8859-15"}
+
+// Name returns the name of the character set, as accepted by
+// Config.Charset, or "" for an unknown number.
+func (c EPCCharset) Name() string {
+	if c < EPCUTF8 || c > EPCLatin9 {
+		return ""
+	}
+	return epcCharsetNames[c-1]
+}
+
+// EPCMaxBytes is the largest EPC payload, which fits version 13 at
+// error correction level M.
+const EPCMaxBytes = 331
+
+// EPC is a SEPA credit transfer in the format of the European Payments
+// Council guideline EPC069-12, known as GiroCode, read by European
+// banking apps:
+//
+//	BCD
+//	002
+//	1
+//	SCT
+//	BPOTBEB1
+//	Red Cross of Belgium
+//	BE72000000001616
+//	EUR12.34
+//	CHAR
+//
+//	Urgency fund
+//
+// The standard requires error correction level M and at most version
+// 13; qrgode.NewEPC sets both.
+type EPC struct {
+	Version     string     // "001" or "002", empty for 002
+	Charset     EPCCharset // 0 for UTF-8
+	BIC         string     // Bank of the beneficiary, required in version 001
+	Name        string     // Beneficiary, at most 70 characters
+	IBAN        string     // Account of the beneficiary; spaces are removed
+	Amount      float64    // Euros, 0.01-999999999.99, 0 to leave it to the payer
+	Purpose     string     // Four letter ISO 20022 purpose code, like "CHAR"
+	Reference   string     // Structured creditor reference, like an RF reference
+	Text        string     // Unstructured remittance, instead of Reference
+	Information string     // Note from the beneficiary to the payer
+}
+
+func (e EPC) version() string {
+	if e.Version == "" {
+		return "002"
+	}
+	return e.Version
+}
+
+func (e EPC) charset() EPCCharset {
+	if e.Charset == 0 {
+		return EPCUTF8
+	}
+	return e.Charset
+}
+
+// compact removes spaces and upper-cases account identifiers.
+func compact(s string) string {
+	return strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+}
+
+// Validate checks the fields against the lengths and formats of
+// EPC069-12, the IBAN and RF reference check digits, and that the
+// payload holds at most EPCMaxBytes bytes.
+func (e EPC) Validate() error {
+	_, err := e.Encode()
+	return err
+}
+
+// Encode validates the transfer and returns its EPC payload, lines
+// separated by LF with trailing empty lines left out. Account
+// identifiers are written without spaces, in upper case.
+func (e EPC) Encode() (string, error) {
+	fail := func(field, format string, args ...any) error {
+		return &Error{Payload: "EPC", Field: field, Message: fmt.Sprintf(format, args...)}
+	}
+
+	if v := e.version(); v != "001" && v != "002" {
+		return "", fail("Version", "must be 001 or 002, got %q", e.Version)
+	}
+	cs, ok := encoder.LookupCharset(e.charset().Name())
+	if !ok {
+		return "", fail("Charset", "must be 1-8, got %d", e.Charset)
+	}
+
+	bic := compact(e.BIC)
+	switch {
+	case bic == "" && e.version() == "001":
+		return "", fail("BIC", "cannot be empty in version 001")
+	case bic != "" && !validBIC(bic):
+		return "", fail("BIC", "%q is not a BIC of 8 or 11 characters", e.BIC)
+	}
+
+	iban := compact(e.IBAN)
+	if err := checkIBAN(iban); err != nil {
+		return "", fail("IBAN", "%v", err)
+	}
+
+	amount, err := formatAmount(e.Amount)
+	if err != nil {
+		return "", fail("Amount", "%v", err)
+	}
+	if amount != "" {
+		amount = "EUR" + amount
+	}
+
+	if e.Purpose != "" && (len(e.Purpose) != 4 || !isLetters(e.Purpose)) {
+		return "", fail("Purpose", "must be four upper-case letters, got %q", e.Purpose)
+	}
+	if e.Reference != "" && e.Text != "" {
+		return "", fail("Reference", "cannot be combined with Text")
+	}
+	if strings.HasPrefix(e.Reference, "RF") {
+		if err := checkCreditorReference(e.Reference); err != nil {
+			return "", fail("Reference", "%v", err)
+		}
+	}
+
+	texts := []struct {
+		field, value string
+		max          int
+	}{
+		{"Name", e.Name, 70},
+		{"Reference", e.Reference, 35},
+		{"Text", e.Text, 140},
+		{"Information", e.Information, 70},
+	}
+	for _, t := range texts {
+		if n := utf8.RuneCountInString(t.value); n > t.max {
+			return "", fail(t.field, "must be at most %d characters, got %d", t.max, n)
+		}
+		if strings.ContainsAny(t.value, "\r\n") {
+			return "", fail(t.field, "cannot contain line breaks")
+		}
+		if _, err := cs.Encode(t.value); err != nil {
+			return "", fail(t.field, "%v", err)
+		}
+	}
+	if strings.TrimSpace(e.Name) == "" {
+		return "", fail("Name", "cannot be empty")
+	}
+
+	lines := []string{"BCD", e.version(), strconv.Itoa(int(e.charset())), "SCT",
+		bic, e.Name, iban, amount, e.Purpose, e.Reference, e.Text, e.Information}
+	for lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	data := strings.Join(lines, "\n")
+
+	encoded, _ := cs.Encode(data)
+	if len(encoded) > EPCMaxBytes {
+		return "", fail("Data", "payload is %d bytes, at most %d fit", len(encoded), EPCMaxBytes)
+	}
+	return data, nil
+}
+
+// formatAmount returns amount with two decimals, or "" for 0. Amounts
+// of credit transfers range from 0.01 to 999999999.99.
+func formatAmount(amount float64) (string, error) {
+	if amount == 0 {
+		return "", nil
+	}
+	cents := math.Round(amount * 100)
+	switch {
+	case amount < 0.01 || amount > 999999999.99:
+		return "", fmt.Errorf("must be between 0.01 and 999999999.99, got %v", amount)
+	case math.Abs(amount*100-cents) > 1e-6:
+		return "", fmt.Errorf("must have at most two decimals, got %v", amount)
+	}
+	return strconv.FormatFloat(cents/100, 'f', 2, 64), nil
+}
+
+// validBIC reports whether bic has the form of a business identifier
+// code: bank, country, location and optional branch.
+func validBIC(bic string) bool {
+	if len(bic) != 8 && len(bic) != 11 {
+		return false
+	}
+	return isLetters(bic[:6]) && isUpperAlnum(bic[6:])
+}
+
+// checkIBAN checks the form and ISO 7064 mod 97-10 check digits of a
+// compacted IBAN.
+func checkIBAN(iban string) error {
+	switch {
+	case iban == "":
+		return errors.New("cannot be empty")
+	case len(iban) < 15 || len(iban) > 34:
+		return fmt.Errorf("%q must be 15-34 characters", iban)
+	case !isUpperAlnum(iban) || !isLetters(iban[:2]) || !isDigits(iban[2:4]):
+		return fmt.Errorf("%q must start with a country code and two check digits", iban)
+	case mod97(iban[4:]+iban[:4]) != 1:
+		return fmt.Errorf("%q has wrong check digits", iban)
+	}
+	return nil
+}
+
+// checkCreditorReference checks the form and check digits of an ISO
+// 11649 creditor reference: RF, two check digits and up to 21 letters
+// and digits.
+func checkCreditorReference(ref string) error {
+	switch {
+	case len(ref) < 5 || len(ref) > 25:
+		return fmt.Errorf("RF reference %q must be 5-25 characters", ref)
+	case !isUpperAlnum(ref) || !isDigits(ref[2:4]):
+		return fmt.Errorf("RF reference %q must be RF, two check digits and letters or digits", ref)
+	case mod97(ref[4:]+ref[:4]) != 1:
+		return fmt.Errorf("RF reference %q has wrong check digits", ref)
+	}
+	return nil
+}
+
+// mod97 returns the remainder of dividing s by 97, with letters counted
+// as the numbers 10-35 as in IBANs.
+func mod97(s string) int {
+	r := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' {
+			r = (r*100 + int(c-'A'+10)) % 97
+		} else {
+			r = (r*10 + int(c-'0')) % 97
+		}
+	}
+	return r
+}
+
+func isUpperAlnum(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !('A' <= s[i] && s[i] <= 'Z' || '0' <= s[i] && s[i] <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetters(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package payload
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEPCEncode(t *testing.T) {
+	tests := []struct {
+		name string
+		epc  EPC
+		want string
+	}{
+		{"guideline example", EPC{
+			Version: "001",
+			BIC:     "BPOTBEB1",
+			Name:    "Red Cross of Belgium",
+			IBAN:    "BE72000000001616",
+			Amount:  1,
+			Purpose: "CHAR",
+			Text:    "Urgency fund",
+		}, "BCD\n001\n1\nSCT\nBPOTBEB1\nRed Cross of Belgium\nBE72000000001616\nEUR1.00\nCHAR\n\nUrgency fund"},
+		{"compacted and trimmed", EPC{
+			Name:   "Franz Mustermänn",
+			IBAN:   "de89 3704 0044 0532 0130 00",
+			Amount: 12.34,
+		}, "BCD\n002\n1\nSCT\n\nFranz Mustermänn\nDE89370400440532013000\nEUR12.34"},
+		{"creditor reference", EPC{
+			Charset:     EPCLatin1,
+			Name:        "Example",
+			IBAN:        "DE89370400440532013000",
+			Reference:   "RF18539007547034",
+			Information: "Thanks",
+		}, "BCD\n002\n2\nSCT\n\nExample\nDE89370400440532013000\n\n\nRF18539007547034\n\nThanks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.epc.Encode()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEPCValidate(t *testing.T) {
+	valid := EPC{Name: "Example", IBAN: "DE89370400440532013000"}
+	modify := func(f func(e *EPC)) EPC {
+		e := valid
+		f(&e)
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		epc   EPC
+		field string
+	}{
+		{"bad version", modify(func(e *EPC) { e.Version = "003" }), "Version"},
+		{"bad charset", modify(func(e *EPC) { e.Charset = 9 }), "Charset"},
+		{"no bic in 001", modify(func(e *EPC) { e.Version = "001" }), "BIC"},
+		{"bad bic", modify(func(e *EPC) { e.BIC = "BPOT1EB1" }), "BIC"},
+		{"no name", modify(func(e *EPC) { e.Name = "" }), "Name"},
+		{"long name", modify(func(e *EPC) { e.Name = strings.Repeat("x", 71) }), "Name"},
+		{"no iban", modify(func(e *EPC) { e.IBAN = "" }), "IBAN"},
+		{"bad iban check", modify(func(e *EPC) { e.IBAN = "DE89370400440532013001" }), "IBAN"},
+		{"bad iban form", modify(func(e *EPC) { e.IBAN = "1289370400440532013000" }), "IBAN"},
+		{"small amount", modify(func(e *EPC) { e.Amount = 0.001 }), "Amount"},
+		{"large amount", modify(func(e *EPC) { e.Amount = 1e9 }), "Amount"},
+		{"three decimals", modify(func(e *EPC) { e.Amount = 1.234 }), "Amount"},
+		{"bad purpose", modify(func(e *EPC) { e.Purpose = "CHARITY" }), "Purpose"},
+		{"both remittances", modify(func(e *EPC) { e.Reference, e.Text = "RF18539007547034", "x" }), "Reference"},
+		{"bad rf reference", modify(func(e *EPC) { e.Reference = "RF19539007547034" }), "Reference"},
+		{"long text", modify(func(e *EPC) { e.Text = strings.Repeat("x", 141) }), "Text"},
+		{"line break", modify(func(e *EPC) { e.Text = "a\nb" }), "Text"},
+		{"not latin-1", modify(func(e *EPC) { e.Charset, e.Name = EPCLatin1, "Łódź" }), "Name"},
+		{"too long", modify(func(e *EPC) {
+			e.Name, e.Text, e.Information = strings.Repeat("ü", 70), strings.Repeat("ü", 140), strings.Repeat("ü", 70)
+		}), "Data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var perr *Error
+			if err := tt.epc.Validate(); !errors.As(err, &perr) || perr.Field != tt.field {
+				t.Errorf("expected error in %s, got %v", tt.field, err)
+			}
+		})
+	}
+
+	if err := valid.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
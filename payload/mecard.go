@@ -0,0 +1,129 @@
+package payload
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// mecardSpecial holds the characters escaped in MeCard values.
+const mecardSpecial = `\;,:`
+
+// MeCard is a contact in the compact MECARD format introduced by NTT
+// DoCoMo, which needs fewer modules than a vCard:
+//
+//	MECARD:N:Doe,Jane;TEL:+15551234;EMAIL:jane@example.com;;
+type MeCard struct {
+	FamilyName string
+	GivenName  string
+	Reading    string // Phonetic reading of the name (SOUND)
+	Nickname   string
+	Org        string
+	Phones     []string
+	Emails     []string
+	Address    string // One line; line breaks become spaces
+	URL        string
+	Birthday   string // YYYYMMDD
+	Note       string // Line breaks become spaces
+}
+
+// Validate checks that the contact has a name, and the phone numbers,
+// email addresses, link and birthday.
+func (m MeCard) Validate() error {
+	fail := func(field, format string, args ...any) error {
+		return &Error{Payload: "MeCard", Field: field, Message: fmt.Sprintf(format, args...)}
+	}
+
+	if strings.TrimSpace(m.FamilyName+m.GivenName) == "" {
+		return fail("FamilyName", "cannot be empty without a given name")
+	}
+	for i, p := range m.Phones {
+		if err := checkPhone(p); err != nil {
+			return fail(fmt.Sprintf("Phones[%d]", i), "%v", err)
+		}
+	}
+	for i, e := range m.Emails {
+		if err := checkEmail(e); err != nil {
+			return fail(fmt.Sprintf("Emails[%d]", i), "%v", err)
+		}
+	}
+	if err := checkURI(m.URL); err != nil {
+		return fail("URL", "%v", err)
+	}
+	if m.Birthday != "" {
+		if _, err := time.Parse("20060102", m.Birthday); err != nil {
+			return fail("Birthday", "must be a date as YYYYMMDD, got %q", m.Birthday)
+		}
+	}
+	return nil
+}
+
+// Encode validates the contact and returns it as a MeCard, with
+// backslashes before \ ; , and : in the values.
+func (m MeCard) Encode() (string, error) {
+	if err := m.Validate(); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	field := func(name, value string) {
+		if value != "" {
+			b.WriteString(name + ":" + value + ";")
+		}
+	}
+	text := func(s string) string {
+		s = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+		return escape(s, mecardSpecial)
+	}
+
+	b.WriteString("MECARD:")
+	name := text(m.FamilyName)
+	if m.GivenName != "" {
+		name += "," + text(m.GivenName)
+	}
+	field("N", name)
+	field("SOUND", text(m.Reading))
+	field("NICKNAME", text(m.Nickname))
+	field("ORG", text(m.Org))
+	for _, p := range m.Phones {
+		field("TEL", text(p))
+	}
+	for _, e := range m.Emails {
+		field("EMAIL", text(e))
+	}
+	field("ADR", text(m.Address))
+	field("URL", text(m.URL))
+	field("BDAY", m.Birthday)
+	field("NOTE", text(m.Note))
+	b.WriteString(";")
+	return b.String(), nil
+}
+
+// Trim returns the contact without its least important optional field:
+// the note, birthday, URL, address, reading, nickname, then all but the
+// first email address and phone number, and the organization.
+func (m MeCard) Trim() (Payload, bool) {
+	switch {
+	case m.Note != "":
+		m.Note = ""
+	case m.Birthday != "":
+		m.Birthday = ""
+	case m.URL != "":
+		m.URL = ""
+	case m.Address != "":
+		m.Address = ""
+	case m.Reading != "":
+		m.Reading = ""
+	case m.Nickname != "":
+		m.Nickname = ""
+	case len(m.Emails) > 1:
+		m.Emails = m.Emails[:len(m.Emails)-1]
+	case len(m.Phones) > 1:
+		m.Phones = m.Phones[:len(m.Phones)-1]
+	case m.Org != "":
+		m.Org = ""
+	default:
+		return m, false
+	}
+	return m, true
+}
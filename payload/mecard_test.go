@@ -0,0 +1,51 @@
+package payload
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMeCardEncode(t *testing.T) {
+	card := MeCard{
+		FamilyName: "Doe",
+		GivenName:  "Jane",
+		Org:        "Example, Inc",
+		Phones:     []string{"+15550100"},
+		Emails:     []string{"jane@example.com"},
+		Address:    "1 Main St\nSpringfield",
+		URL:        "https://example.com",
+		Birthday:   "19900131",
+		Note:       "Met at a;b",
+	}
+	got, err := card.Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `MECARD:N:Doe,Jane;ORG:Example\, Inc;TEL:+15550100;EMAIL:jane@example.com;` +
+		`ADR:1 Main St Springfield;URL:https\://example.com;BDAY:19900131;NOTE:Met at a\;b;;`
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestMeCardValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		card  MeCard
+		field string
+	}{
+		{"no name", MeCard{Phones: []string{"123"}}, "FamilyName"},
+		{"bad phone", MeCard{FamilyName: "x", Phones: []string{"none"}}, "Phones[0]"},
+		{"bad email", MeCard{FamilyName: "x", Emails: []string{"@example.com"}}, "Emails[0]"},
+		{"bad birthday", MeCard{FamilyName: "x", Birthday: "19901331"}, "Birthday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var perr *Error
+			if err := tt.card.Validate(); !errors.As(err, &perr) || perr.Field != tt.field {
+				t.Errorf("expected error in %s, got %v", tt.field, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Package payload builds the standard text formats that phone cameras
+// and scanner apps act on, such as joining a WiFi network or saving a
+// contact.
+//
+// Each payload type validates its fields and encodes them with the
+// escaping its format requires:
+//
+//	data, err := payload.WiFi{SSID: "Office", Password: "correct horse"}.Encode()
+//	// WIFI:T:WPA;S:Office;P:correct horse;;
+//
+// The qrgode package turns payloads into QR codes with NewPayload and
+// helpers like NewWiFi and NewVCard.
+package payload
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Payload is structured data with a text encoding that scanners
+// recognize.
+type Payload interface {
+	// Encode validates the fields and returns the text to encode in the
+	// QR code.
+	Encode() (string, error)
+}
+
+// Trimmer is a payload with optional fields that can be dropped to fit
+// a smaller QR code, like the notes and addresses of a contact.
+type Trimmer interface {
+	Payload
+
+	// Trim returns a copy without the least important optional field
+	// still set, or false if only required fields are left.
+	Trim() (Payload, bool)
+}
+
+// Error reports a payload field that is missing or out of range.
+type Error struct {
+	Payload string // Payload type, like "WiFi"
+	Field   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Payload, e.Field, e.Message)
+}
+
+// escape puts a backslash before each of the special characters in s.
+func escape(s, special string) string {
+	if !strings.ContainsAny(s, special) {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(special, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
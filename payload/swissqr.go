@@ -0,0 +1,234 @@
+package payload
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SwissQRMaxChars is the largest Swiss QR-bill payload, in characters.
+const SwissQRMaxChars = 997
+
+// SwissQRBill is the payment part of a Swiss QR-bill: the SPC payload of
+// the Swiss Implementation Guidelines for the QR-bill, version 2.3, read
+// by Swiss banking apps.
+//
+// The guidelines ask for error correction level M, a 46 × 46 mm code and
+// a 7 × 7 mm Swiss cross in its center; qrgode.NewSwissQRBill sets all
+// three.
+type SwissQRBill struct {
+	IBAN       string        // CH or LI account; spaces are removed
+	Creditor   SwissAddress  // Account holder
+	Amount     float64       // 0.01-999999999.99, 0 to leave it to the payer
+	Currency   string        // "CHF" or "EUR", empty for CHF
+	Debtor     *SwissAddress // Payer, nil if not known
+	Reference  string        // QRR reference for a QR-IBAN, else empty or an RF creditor reference
+	Message    string        // Unstructured message to the payer
+	BillInfo   string        // Structured billing information, like //S1/10/10201409
+	AltSchemes []string      // Parameters of up to two alternative procedures
+}
+
+// SwissAddress is a structured address of a Swiss QR-bill.
+type SwissAddress struct {
+	Name           string // At most 70 characters
+	Street         string // At most 70 characters
+	BuildingNumber string // At most 16 characters
+	PostalCode     string // At most 16 characters, without a country prefix
+	Town           string // At most 35 characters
+	Country        string // Two letter ISO 3166-1 code, like "CH"
+}
+
+// Reference types of a Swiss QR-bill.
+const (
+	swissRefQRR  = "QRR"  // 27 digit QR reference, for QR-IBANs
+	swissRefSCOR = "SCOR" // ISO 11649 creditor reference
+	swissRefNone = "NON"
+)
+
+func (b SwissQRBill) currency() string {
+	if b.Currency == "" {
+		return "CHF"
+	}
+	return b.Currency
+}
+
+// IsQRIBAN reports whether iban is a QR-IBAN, whose institution
+// identifier lies in the range 30000-31999 reserved for payments with a
+// QR reference.
+func IsQRIBAN(iban string) bool {
+	iban = compact(iban)
+	if len(iban) != 21 || !isDigits(iban[4:9]) {
+		return false
+	}
+	iid, _ := strconv.Atoi(iban[4:9])
+	return iid >= 30000 && iid <= 31999
+}
+
+// Validate checks the IBAN, that the reference suits it (a QRR
+// reference with a QR-IBAN, an RF creditor reference or none
+// otherwise), the addresses, field lengths and character set.
+func (b SwissQRBill) Validate() error {
+	_, err := b.Encode()
+	return err
+}
+
+// Encode validates the bill and returns its SPC payload, lines
+// separated by LF. The account and reference are written without
+// spaces.
+func (b SwissQRBill) Encode() (string, error) {
+	fail := func(field, format string, args ...any) error {
+		return &Error{Payload: "SwissQRBill", Field: field, Message: fmt.Sprintf(format, args...)}
+	}
+
+	iban := compact(b.IBAN)
+	if err := checkIBAN(iban); err != nil {
+		return "", fail("IBAN", "%v", err)
+	}
+	if c := iban[:2]; (c != "CH" && c != "LI") || len(iban) != 21 {
+		return "", fail("IBAN", "%q is not a Swiss or Liechtenstein IBAN", iban)
+	}
+
+	ref := compact(b.Reference)
+	refType := swissRefNone
+	switch {
+	case IsQRIBAN(iban):
+		if err := checkQRReference(ref); err != nil {
+			return "", fail("Reference", "a QR-IBAN needs a QRR reference: %v", err)
+		}
+		refType = swissRefQRR
+	case strings.HasPrefix(ref, "RF"):
+		if err := checkCreditorReference(ref); err != nil {
+			return "", fail("Reference", "%v", err)
+		}
+		refType = swissRefSCOR
+	case ref != "":
+		return "", fail("Reference", "must be an RF creditor reference or empty without a QR-IBAN, got %q", b.Reference)
+	}
+
+	amount, err := formatAmount(b.Amount)
+	if err != nil {
+		return "", fail("Amount", "%v", err)
+	}
+	if c := b.currency(); c != "CHF" && c != "EUR" {
+		return "", fail("Currency", "must be CHF or EUR, got %q", b.Currency)
+	}
+
+	creditor, err := b.Creditor.lines()
+	if err != nil {
+		return "", fail("Creditor", "%v", err)
+	}
+	debtor := make([]string, 7)
+	if b.Debtor != nil {
+		if debtor, err = b.Debtor.lines(); err != nil {
+			return "", fail("Debtor", "%v", err)
+		}
+	}
+
+	if n := utf8.RuneCountInString(b.Message + b.BillInfo); n > 140 {
+		return "", fail("Message", "must be at most 140 characters with BillInfo, got %d", n)
+	}
+	if len(b.AltSchemes) > 2 {
+		return "", fail("AltSchemes", "must be at most two, got %d", len(b.AltSchemes))
+	}
+	texts := []struct{ field, value string }{{"Message", b.Message}, {"BillInfo", b.BillInfo}}
+	for i, alt := range b.AltSchemes {
+		field := fmt.Sprintf("AltSchemes[%d]", i)
+		if n := utf8.RuneCountInString(alt); n > 100 {
+			return "", fail(field, "must be at most 100 characters, got %d", n)
+		}
+		texts = append(texts, struct{ field, value string }{field, alt})
+	}
+	for _, t := range texts {
+		if err := checkSwissText(t.value); err != nil {
+			return "", fail(t.field, "%v", err)
+		}
+	}
+
+	lines := []string{"SPC", "0200", "1", iban}
+	lines = append(lines, creditor...)
+	lines = append(lines, make([]string, 7)...) // Ultimate creditor, for future use
+	lines = append(lines, amount, b.currency())
+	lines = append(lines, debtor...)
+	lines = append(lines, refType, ref, b.Message, "EPD", b.BillInfo)
+	lines = append(lines, b.AltSchemes...)
+	for lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	data := strings.Join(lines, "\n")
+
+	if n := utf8.RuneCountInString(data); n > SwissQRMaxChars {
+		return "", fail("Data", "payload is %d characters, at most %d fit", n, SwissQRMaxChars)
+	}
+	return data, nil
+}
+
+// lines returns the seven lines of a structured ("S") address.
+func (a SwissAddress) lines() ([]string, error) {
+	fields := []struct {
+		name, value string
+		max         int
+		required    bool
+	}{
+		{"Name", a.Name, 70, true},
+		{"Street", a.Street, 70, false},
+		{"BuildingNumber", a.BuildingNumber, 16, false},
+		{"PostalCode", a.PostalCode, 16, true},
+		{"Town", a.Town, 35, true},
+	}
+	for _, f := range fields {
+		switch n := utf8.RuneCountInString(f.value); {
+		case f.required && strings.TrimSpace(f.value) == "":
+			return nil, fmt.Errorf("%s cannot be empty", f.name)
+		case n > f.max:
+			return nil, fmt.Errorf("%s must be at most %d characters, got %d", f.name, f.max, n)
+		}
+		if err := checkSwissText(f.value); err != nil {
+			return nil, fmt.Errorf("%s: %v", f.name, err)
+		}
+	}
+	if len(a.Country) != 2 || !isLetters(a.Country) {
+		return nil, fmt.Errorf("Country must be a two letter code in upper case, got %q", a.Country)
+	}
+	return []string{"S", a.Name, a.Street, a.BuildingNumber, a.PostalCode, a.Town, a.Country}, nil
+}
+
+// checkQRReference checks a QRR reference: 27 digits, the last a
+// modulo 10 recursive check digit of the others.
+func checkQRReference(ref string) error {
+	if len(ref) != 27 || !isDigits(ref) {
+		return fmt.Errorf("%q is not 27 digits", ref)
+	}
+	if qrReferenceCheckDigit(ref[:26]) != ref[26] {
+		return fmt.Errorf("%q has a wrong check digit", ref)
+	}
+	return nil
+}
+
+// qrReferenceCheckDigit returns the modulo 10 recursive check digit of
+// digits, as on Swiss payment slips.
+func qrReferenceCheckDigit(digits string) byte {
+	table := [10]int{0, 9, 4, 6, 8, 2, 7, 1, 3, 5}
+	carry := 0
+	for i := 0; i < len(digits); i++ {
+		carry = table[(carry+int(digits[i]-'0'))%10]
+	}
+	return byte('0' + (10-carry)%10)
+}
+
+// checkSwissText checks that s holds only characters of the QR-bill
+// character set: Latin letters, digits and punctuation, without line
+// breaks.
+func checkSwissText(s string) error {
+	for _, r := range s {
+		switch {
+		case r >= 0x20 && r <= 0x7e, // Basic Latin
+			r >= 0xa0 && r <= 0x17f,  // Latin-1 Supplement and Latin Extended-A
+			r >= 0x218 && r <= 0x21b, // Ș ș Ț ț
+			r == '€':
+		default:
+			return fmt.Errorf("character %q is not allowed", r)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package payload
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testCreditor = SwissAddress{
+	Name:           "Robert Schneider AG",
+	Street:         "Rue du Lac",
+	BuildingNumber: "1268",
+	PostalCode:     "2501",
+	Town:           "Biel",
+	Country:        "CH",
+}
+
+func TestSwissQRBillEncode(t *testing.T) {
+	creditor := "S\nRobert Schneider AG\nRue du Lac\n1268\n2501\nBiel\nCH\n"
+	none := strings.Repeat("\n", 7)
+	tests := []struct {
+		name string
+		bill SwissQRBill
+		want string
+	}{
+		{"qr reference", SwissQRBill{
+			IBAN:      "CH44 3199 9123 0008 8901 2",
+			Creditor:  testCreditor,
+			Amount:    1949.75,
+			Debtor:    &SwissAddress{Name: "Pia Rutschmann", Street: "Marktgasse", BuildingNumber: "28", PostalCode: "9400", Town: "Rorschach", Country: "CH"},
+			Reference: "21 00000 00003 13947 14300 09017",
+			Message:   "Order of 15 June 2020",
+			BillInfo:  "//S1/10/10201409/11/200701/20/140.000-53",
+		}, "SPC\n0200\n1\nCH4431999123000889012\n" + creditor + none + "1949.75\nCHF\n" +
+			"S\nPia Rutschmann\nMarktgasse\n28\n9400\nRorschach\nCH\n" +
+			"QRR\n210000000003139471430009017\nOrder of 15 June 2020\nEPD\n//S1/10/10201409/11/200701/20/140.000-53"},
+		{"creditor reference", SwissQRBill{
+			IBAN:      "CH58 0079 1123 0008 8901 2",
+			Creditor:  testCreditor,
+			Currency:  "EUR",
+			Reference: "RF18 5390 0754 7034",
+		}, "SPC\n0200\n1\nCH5800791123000889012\n" + creditor + none + "\nEUR\n" + none +
+			"SCOR\nRF18539007547034\n\nEPD"},
+		{"alternative scheme", SwissQRBill{
+			IBAN:       "CH5800791123000889012",
+			Creditor:   testCreditor,
+			Amount:     100,
+			AltSchemes: []string{"eBill/B/41010560425610173"},
+		}, "SPC\n0200\n1\nCH5800791123000889012\n" + creditor + none + "100.00\nCHF\n" + none +
+			"NON\n\n\nEPD\n\neBill/B/41010560425610173"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.bill.Encode()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSwissQRBillValidate(t *testing.T) {
+	valid := SwissQRBill{IBAN: "CH5800791123000889012", Creditor: testCreditor}
+	modify := func(f func(b *SwissQRBill)) SwissQRBill {
+		b := valid
+		f(&b)
+		return b
+	}
+
+	tests := []struct {
+		name  string
+		bill  SwissQRBill
+		field string
+	}{
+		{"bad iban check", modify(func(b *SwissQRBill) { b.IBAN = "CH5800791123000889013" }), "IBAN"},
+		{"foreign iban", modify(func(b *SwissQRBill) { b.IBAN = "DE89370400440532013000" }), "IBAN"},
+		{"qr-iban without reference", modify(func(b *SwissQRBill) { b.IBAN = "CH4431999123000889012" }), "Reference"},
+		{"qr-iban with rf reference", modify(func(b *SwissQRBill) {
+			b.IBAN, b.Reference = "CH4431999123000889012", "RF18539007547034"
+		}), "Reference"},
+		{"bad qr check digit", modify(func(b *SwissQRBill) {
+			b.IBAN, b.Reference = "CH4431999123000889012", "210000000003139471430009016"
+		}), "Reference"},
+		{"qr reference without qr-iban", modify(func(b *SwissQRBill) { b.Reference = "210000000003139471430009017" }), "Reference"},
+		{"bad rf reference", modify(func(b *SwissQRBill) { b.Reference = "RF19539007547034" }), "Reference"},
+		{"three decimals", modify(func(b *SwissQRBill) { b.Amount = 1.234 }), "Amount"},
+		{"bad currency", modify(func(b *SwissQRBill) { b.Currency = "USD" }), "Currency"},
+		{"no creditor town", modify(func(b *SwissQRBill) { b.Creditor.Town = "" }), "Creditor"},
+		{"bad creditor country", modify(func(b *SwissQRBill) { b.Creditor.Country = "Schweiz" }), "Creditor"},
+		{"long debtor name", modify(func(b *SwissQRBill) {
+			b.Debtor = &SwissAddress{Name: strings.Repeat("x", 71), PostalCode: "1", Town: "x", Country: "CH"}
+		}), "Debtor"},
+		{"long message", modify(func(b *SwissQRBill) { b.Message, b.BillInfo = strings.Repeat("x", 100), strings.Repeat("x", 41) }), "Message"},
+		{"line break", modify(func(b *SwissQRBill) { b.Message = "a\nb" }), "Message"},
+		{"outside character set", modify(func(b *SwissQRBill) { b.Message = "Zahlung ✓" }), "Message"},
+		{"three schemes", modify(func(b *SwissQRBill) { b.AltSchemes = []string{"a", "b", "c"} }), "AltSchemes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var perr *Error
+			if err := tt.bill.Validate(); !errors.As(err, &perr) || perr.Field != tt.field {
+				t.Errorf("expected error in %s, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestQRReferenceCheckDigit(t *testing.T) {
+	if got := qrReferenceCheckDigit("21000000000313947143000901"); got != '7' {
+		t.Errorf("expected 7, got %c", got)
+	}
+	if !IsQRIBAN("CH44 3199 9123 0008 8901 2") || IsQRIBAN("CH5800791123000889012") {
+		t.Error("expected only the first IBAN to be a QR-IBAN")
+	}
+}
@@ -0,0 +1,310 @@
+package payload
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// VCard is a contact in the vCard format of RFC 2426 (version 3.0) or
+// RFC 6350 (version 4.0), read by phone cameras and address books.
+type VCard struct {
+	Version string // "3.0" or "4.0", empty for 3.0
+
+	FamilyName      string
+	GivenName       string
+	AdditionalNames string
+	Prefix          string // Honorific prefix, like "Dr."
+	Suffix          string // Honorific suffix, like "Jr."
+	FormattedName   string // Display name, joined from the name parts if empty
+
+	Org       string
+	Titles    []string
+	Phones    []Phone
+	Emails    []Email
+	Addresses []Address
+	URL       string
+	Note      string
+	PhotoURL  string // Link to a photo, which is too large to embed
+}
+
+// Phone is a telephone number of a contact.
+type Phone struct {
+	Number string
+	Types  []string // Like "cell", "work" or "voice"
+}
+
+// Email is an email address of a contact.
+type Email struct {
+	Address string
+	Types   []string // Like "work" or "home"
+}
+
+// Address is a postal address of a contact.
+type Address struct {
+	Types      []string // Like "work" or "home"
+	POBox      string
+	Extended   string // Apartment or suite
+	Street     string
+	Locality   string // City
+	Region     string // State or province
+	PostalCode string
+	Country    string
+}
+
+// vcardSpecial holds the characters escaped in vCard text values, besides
+// line breaks.
+const vcardSpecial = `\;,`
+
+func (v VCard) version() string {
+	if v.Version == "" {
+		return "3.0"
+	}
+	return v.Version
+}
+
+// formattedName returns the display name, joined from the name parts if
+// not set.
+func (v VCard) formattedName() string {
+	if v.FormattedName != "" {
+		return v.FormattedName
+	}
+	return joinNonEmpty(" ", v.Prefix, v.GivenName, v.AdditionalNames, v.FamilyName, v.Suffix)
+}
+
+// Validate checks the version, that the contact has a name, and the
+// phone numbers, email addresses, links and TYPE parameters.
+func (v VCard) Validate() error {
+	fail := func(field, format string, args ...any) error {
+		return &Error{Payload: "VCard", Field: field, Message: fmt.Sprintf(format, args...)}
+	}
+
+	if v.Version != "" && v.Version != "3.0" && v.Version != "4.0" {
+		return fail("Version", "must be 3.0 or 4.0, got %q", v.Version)
+	}
+	if strings.TrimSpace(v.formattedName()) == "" {
+		return fail("FormattedName", "cannot be empty without name parts")
+	}
+
+	for i, p := range v.Phones {
+		field := fmt.Sprintf("Phones[%d]", i)
+		if err := checkPhone(p.Number); err != nil {
+			return fail(field, "%v", err)
+		}
+		if err := checkTypes(p.Types); err != nil {
+			return fail(field, "%v", err)
+		}
+	}
+	for i, e := range v.Emails {
+		field := fmt.Sprintf("Emails[%d]", i)
+		if err := checkEmail(e.Address); err != nil {
+			return fail(field, "%v", err)
+		}
+		if err := checkTypes(e.Types); err != nil {
+			return fail(field, "%v", err)
+		}
+	}
+	for i, a := range v.Addresses {
+		if err := checkTypes(a.Types); err != nil {
+			return fail(fmt.Sprintf("Addresses[%d]", i), "%v", err)
+		}
+	}
+	if err := checkURI(v.URL); err != nil {
+		return fail("URL", "%v", err)
+	}
+	if err := checkURI(v.PhotoURL); err != nil {
+		return fail("PhotoURL", "%v", err)
+	}
+	return nil
+}
+
+// Encode validates the contact and returns it as a vCard. Text values
+// are escaped and lines longer than 75 bytes folded, as the RFCs ask.
+func (v VCard) Encode() (string, error) {
+	if err := v.Validate(); err != nil {
+		return "", err
+	}
+
+	v4 := v.version() == "4.0"
+	var b strings.Builder
+	line := func(name, value string) {
+		writeFolded(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCARD")
+	line("VERSION", v.version())
+	line("N", structuredText(v.FamilyName, v.GivenName, v.AdditionalNames, v.Prefix, v.Suffix))
+	line("FN", escapeText(v.formattedName()))
+	if v.Org != "" {
+		line("ORG", escapeText(v.Org))
+	}
+	for _, title := range v.Titles {
+		line("TITLE", escapeText(title))
+	}
+	for _, p := range v.Phones {
+		if v4 {
+			// The value is a tel URI, which has no spaces
+			number := strings.ReplaceAll(strings.TrimSpace(p.Number), " ", "-")
+			line("TEL;VALUE=uri"+typeParam(p.Types, v4), "tel:"+number)
+		} else {
+			line("TEL"+typeParam(p.Types, v4), p.Number)
+		}
+	}
+	for _, e := range v.Emails {
+		line("EMAIL"+typeParam(e.Types, v4), e.Address)
+	}
+	for _, a := range v.Addresses {
+		line("ADR"+typeParam(a.Types, v4),
+			structuredText(a.POBox, a.Extended, a.Street, a.Locality, a.Region, a.PostalCode, a.Country))
+	}
+	if v.URL != "" {
+		line("URL", v.URL)
+	}
+	if v.Note != "" {
+		line("NOTE", escapeText(v.Note))
+	}
+	if v.PhotoURL != "" {
+		if v4 {
+			line("PHOTO", v.PhotoURL)
+		} else {
+			line("PHOTO;VALUE=uri", v.PhotoURL)
+		}
+	}
+	line("END", "VCARD")
+	return b.String(), nil
+}
+
+// Trim returns the contact without its least important optional field:
+// the photo, note, addresses, URL, titles, then all but the first email
+// address and phone number, and the organization.
+func (v VCard) Trim() (Payload, bool) {
+	switch {
+	case v.PhotoURL != "":
+		v.PhotoURL = ""
+	case v.Note != "":
+		v.Note = ""
+	case len(v.Addresses) > 0:
+		v.Addresses = v.Addresses[:len(v.Addresses)-1]
+	case v.URL != "":
+		v.URL = ""
+	case len(v.Titles) > 0:
+		v.Titles = v.Titles[:len(v.Titles)-1]
+	case len(v.Emails) > 1:
+		v.Emails = v.Emails[:len(v.Emails)-1]
+	case len(v.Phones) > 1:
+		v.Phones = v.Phones[:len(v.Phones)-1]
+	case v.Org != "":
+		v.Org = ""
+	default:
+		return v, false
+	}
+	return v, true
+}
+
+// typeParam returns the TYPE parameter for types: comma separated in
+// version 3.0, and quoted when there are several in version 4.0.
+func typeParam(types []string, v4 bool) string {
+	switch {
+	case len(types) == 0:
+		return ""
+	case v4 && len(types) > 1:
+		return `;TYPE="` + strings.Join(types, ",") + `"`
+	}
+	return ";TYPE=" + strings.Join(types, ",")
+}
+
+// escapeText escapes a vCard text value, with line breaks as \n.
+func escapeText(s string) string {
+	return strings.NewReplacer("\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(escape(s, vcardSpecial))
+}
+
+// structuredText returns the components of a structured value like N
+// or ADR, escaped and separated by semicolons.
+func structuredText(components ...string) string {
+	for i, c := range components {
+		components[i] = escapeText(c)
+	}
+	return strings.Join(components, ";")
+}
+
+// writeFolded writes a content line ending in CRLF, folded so that no
+// line is longer than 75 bytes. Continuation lines start with a space,
+// and UTF-8 sequences are never split.
+func writeFolded(b *strings.Builder, s string) {
+	limit := 75
+	for len(s) > limit {
+		n := limit
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		b.WriteString(s[:n])
+		b.WriteString("\r\n ")
+		s = s[n:]
+		limit = 74 // After the leading space
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
+
+// checkTypes checks that TYPE parameter values are plain tokens.
+func checkTypes(types []string) error {
+	for _, t := range types {
+		if t == "" {
+			return errors.New("empty type")
+		}
+		for _, r := range t {
+			if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '-') {
+				return fmt.Errorf("type %q may only hold letters, digits and hyphens", t)
+			}
+		}
+	}
+	return nil
+}
+
+// checkPhone checks that a phone number holds digits and only the
+// usual separators.
+func checkPhone(number string) error {
+	if !strings.ContainsAny(number, "0123456789") {
+		return fmt.Errorf("number %q has no digits", number)
+	}
+	for _, r := range number {
+		if !('0' <= r && r <= '9' || strings.ContainsRune("+ -().#*", r)) {
+			return fmt.Errorf("number %q holds %q", number, r)
+		}
+	}
+	return nil
+}
+
+// checkEmail checks the rough shape of an email address.
+func checkEmail(address string) error {
+	at := strings.LastIndexByte(address, '@')
+	if at <= 0 || at == len(address)-1 {
+		return fmt.Errorf("%q is not an email address", address)
+	}
+	if strings.ContainsAny(address, " \t\r\n;,:\"") {
+		return fmt.Errorf("address %q holds spaces or separators", address)
+	}
+	return nil
+}
+
+// checkURI checks that a link can be written without escaping.
+func checkURI(uri string) error {
+	for _, r := range uri {
+		if r <= ' ' || r == 0x7f {
+			return fmt.Errorf("%q holds spaces or control characters", uri)
+		}
+	}
+	return nil
+}
+
+// joinNonEmpty joins the non-empty parts with sep.
+func joinNonEmpty(sep string, parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, sep)
+}
@@ -0,0 +1,136 @@
+package payload
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVCardEncode(t *testing.T) {
+	card := VCard{
+		GivenName:  "Jane",
+		FamilyName: "Doe",
+		Prefix:     "Dr.",
+		Org:        "Example; Sons, Ltd",
+		Titles:     []string{"CEO"},
+		Phones: []Phone{
+			{Number: "+1 555 0100", Types: []string{"cell"}},
+			{Number: "+1 555 0199", Types: []string{"work", "voice"}},
+		},
+		Emails:    []Email{{Address: "jane@example.com", Types: []string{"work"}}},
+		Addresses: []Address{{Types: []string{"work"}, Street: "1 Main St", Locality: "Springfield", Country: "USA"}},
+		URL:       "https://example.com",
+		Note:      "First line\nSecond line",
+		PhotoURL:  "https://example.com/jane.jpg",
+	}
+
+	got, err := card.Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "BEGIN:VCARD\r\n" +
+		"VERSION:3.0\r\n" +
+		"N:Doe;Jane;;Dr.;\r\n" +
+		"FN:Dr. Jane Doe\r\n" +
+		"ORG:Example\\; Sons\\, Ltd\r\n" +
+		"TITLE:CEO\r\n" +
+		"TEL;TYPE=cell:+1 555 0100\r\n" +
+		"TEL;TYPE=work,voice:+1 555 0199\r\n" +
+		"EMAIL;TYPE=work:jane@example.com\r\n" +
+		"ADR;TYPE=work:;;1 Main St;Springfield;;;USA\r\n" +
+		"URL:https://example.com\r\n" +
+		"NOTE:First line\\nSecond line\r\n" +
+		"PHOTO;VALUE=uri:https://example.com/jane.jpg\r\n" +
+		"END:VCARD\r\n"
+	if got != want {
+		t.Errorf("expected\n%q\ngot\n%q", want, got)
+	}
+
+	card.Version = "4.0"
+	got, err = card.Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range []string{
+		"VERSION:4.0\r\n",
+		"TEL;VALUE=uri;TYPE=cell:tel:+1-555-0100\r\n",
+		"TEL;VALUE=uri;TYPE=\"work,voice\":tel:+1-555-0199\r\n",
+		"PHOTO:https://example.com/jane.jpg\r\n",
+	} {
+		if !strings.Contains(got, line) {
+			t.Errorf("expected %q in output", line)
+		}
+	}
+}
+
+func TestVCardFolding(t *testing.T) {
+	note := strings.Repeat("ü", 100) // Two bytes each
+	got, err := VCard{FormattedName: "Jane", Note: note}.Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var unfolded strings.Builder
+	for i, line := range strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line %d is %d bytes long", i, len(line))
+		}
+		if !strings.HasPrefix(line, " ") {
+			unfolded.WriteString("\r\n")
+		}
+		unfolded.WriteString(strings.TrimPrefix(line, " "))
+	}
+	if !strings.Contains(unfolded.String(), "\r\nNOTE:"+note+"\r\n") {
+		t.Error("expected the note back after unfolding")
+	}
+}
+
+func TestVCardValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		card  VCard
+		field string
+	}{
+		{"no name", VCard{}, "FormattedName"},
+		{"bad version", VCard{FormattedName: "x", Version: "2.1"}, "Version"},
+		{"bad phone", VCard{FormattedName: "x", Phones: []Phone{{Number: "call me"}}}, "Phones[0]"},
+		{"bad email", VCard{FormattedName: "x", Emails: []Email{{Address: "jane"}}}, "Emails[0]"},
+		{"bad type", VCard{FormattedName: "x", Emails: []Email{{Address: "a@b.c", Types: []string{"wo;rk"}}}}, "Emails[0]"},
+		{"bad url", VCard{FormattedName: "x", URL: "https://example.com/a b"}, "URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var perr *Error
+			if err := tt.card.Validate(); !errors.As(err, &perr) || perr.Field != tt.field {
+				t.Errorf("expected error in %s, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestVCardTrim(t *testing.T) {
+	var p Payload = VCard{
+		FormattedName: "Jane",
+		Org:           "Example",
+		Phones:        []Phone{{Number: "1"}, {Number: "2"}},
+		Note:          "note",
+	}
+	var steps int
+	for {
+		next, ok := p.(Trimmer).Trim()
+		if !ok {
+			break
+		}
+		p = next
+		steps++
+	}
+	// The note, the second phone and the organization
+	if steps != 3 {
+		t.Errorf("expected 3 fields dropped, got %d", steps)
+	}
+	card := p.(VCard)
+	if card.FormattedName != "Jane" || len(card.Phones) != 1 || card.Phones[0].Number != "1" {
+		t.Errorf("expected the name and first phone kept, got %+v", card)
+	}
+}
@@ -0,0 +1,172 @@
+package payload
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Auth is the authentication type of a WiFi network.
+type Auth string
+
+const (
+	AuthWEP     Auth = "WEP"      // WEP key
+	AuthWPA     Auth = "WPA"      // WPA, WPA2 or WPA3 personal, with a passphrase
+	AuthWPA2EAP Auth = "WPA2-EAP" // WPA2 enterprise, with an EAP method
+	AuthNone    Auth = "nopass"   // Open network
+)
+
+// wifiSpecial holds the characters escaped in WiFi fields.
+const wifiSpecial = `\;,:"`
+
+// WiFi is a network join code in the WIFI: format read by the Android
+// and iOS cameras:
+//
+//	WIFI:T:WPA;S:Office;P:correct horse;H:true;;
+type WiFi struct {
+	SSID     string // Network name, 1-32 bytes
+	Password string // Passphrase or key, empty for open networks
+	Auth     Auth   // Empty for WPA with a password, open without
+	Hidden   bool   // The network does not broadcast its SSID
+
+	// WPA2-EAP only
+	EAPMethod         string // PEAP, TLS, TTLS, PWD, SIM, AKA or AKA'
+	Phase2            string // Inner method for PEAP and TTLS: PAP, MSCHAP, MSCHAPV2 or GTC
+	Identity          string // User name
+	AnonymousIdentity string // Outer identity sent before the tunnel is up
+}
+
+var (
+	eapMethods    = []string{"PEAP", "TLS", "TTLS", "PWD", "SIM", "AKA", "AKA'"}
+	phase2Methods = []string{"PAP", "MSCHAP", "MSCHAPV2", "GTC"}
+)
+
+// auth returns the authentication type, inferred from the password if
+// not set.
+func (w WiFi) auth() Auth {
+	switch {
+	case w.Auth != "":
+		return w.Auth
+	case w.Password != "":
+		return AuthWPA
+	}
+	return AuthNone
+}
+
+// Validate checks the SSID length and that the password and EAP fields
+// suit the authentication type.
+func (w WiFi) Validate() error {
+	fail := func(field, format string, args ...any) error {
+		return &Error{Payload: "WiFi", Field: field, Message: fmt.Sprintf(format, args...)}
+	}
+
+	if w.SSID == "" {
+		return fail("SSID", "cannot be empty")
+	}
+	if len(w.SSID) > 32 {
+		return fail("SSID", "must be at most 32 bytes, got %d", len(w.SSID))
+	}
+
+	auth := w.auth()
+	switch auth {
+	case AuthNone:
+		if w.Password != "" {
+			return fail("Password", "must be empty for an open network")
+		}
+	case AuthWEP:
+		// 40 or 104 bit keys, as ASCII text or hex digits
+		switch {
+		case w.Password == "":
+			return fail("Password", "cannot be empty for WEP")
+		case (len(w.Password) == 10 || len(w.Password) == 26) && isHex(w.Password):
+		case (len(w.Password) == 5 || len(w.Password) == 13) && isPrintableASCII(w.Password):
+		default:
+			return fail("Password", "must be a WEP key of 5 or 13 characters, or 10 or 26 hex digits")
+		}
+	case AuthWPA:
+		switch {
+		case w.Password == "":
+			return fail("Password", "cannot be empty for WPA")
+		case len(w.Password) == 64 && isHex(w.Password):
+			// Pre-shared key
+		case len(w.Password) < 8 || len(w.Password) > 63:
+			return fail("Password", "must be 8-63 characters, got %d", len(w.Password))
+		case !isPrintableASCII(w.Password):
+			return fail("Password", "must be printable ASCII")
+		}
+	case AuthWPA2EAP:
+		switch {
+		case w.EAPMethod == "":
+			return fail("EAPMethod", "cannot be empty for WPA2-EAP")
+		case !slices.Contains(eapMethods, w.EAPMethod):
+			return fail("EAPMethod", "unknown method %q (expected %s)", w.EAPMethod, strings.Join(eapMethods, ", "))
+		case w.Phase2 != "" && w.EAPMethod != "PEAP" && w.EAPMethod != "TTLS":
+			return fail("Phase2", "only used with PEAP and TTLS")
+		case w.Phase2 != "" && !slices.Contains(phase2Methods, w.Phase2):
+			return fail("Phase2", "unknown method %q (expected %s)", w.Phase2, strings.Join(phase2Methods, ", "))
+		}
+	default:
+		return fail("Auth", "unknown type %q (expected WEP, WPA, WPA2-EAP or nopass)", w.Auth)
+	}
+
+	if auth != AuthWPA2EAP {
+		eap := []struct{ field, value string }{
+			{"EAPMethod", w.EAPMethod}, {"Phase2", w.Phase2},
+			{"Identity", w.Identity}, {"AnonymousIdentity", w.AnonymousIdentity},
+		}
+		for _, f := range eap {
+			if f.value != "" {
+				return fail(f.field, "only used with WPA2-EAP")
+			}
+		}
+	}
+	return nil
+}
+
+// Encode validates the network and returns its join code, with
+// backslashes before \ ; , : and " in the fields.
+func (w WiFi) Encode() (string, error) {
+	if err := w.Validate(); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	field := func(name, value string) {
+		if value != "" {
+			b.WriteString(name + ":" + escape(value, wifiSpecial) + ";")
+		}
+	}
+
+	b.WriteString("WIFI:")
+	field("T", string(w.auth()))
+	field("S", w.SSID)
+	field("E", w.EAPMethod)
+	field("PH2", w.Phase2)
+	field("A", w.AnonymousIdentity)
+	field("I", w.Identity)
+	field("P", w.Password)
+	if w.Hidden {
+		field("H", "true")
+	}
+	b.WriteString(";")
+	return b.String(), nil
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func isPrintableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
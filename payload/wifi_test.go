@@ -0,0 +1,83 @@
+package payload
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWiFiEncode(t *testing.T) {
+	tests := []struct {
+		name string
+		wifi WiFi
+		want string
+	}{
+		{"wpa", WiFi{SSID: "Office", Password: "correct horse", Hidden: true},
+			"WIFI:T:WPA;S:Office;P:correct horse;H:true;;"},
+		{"open", WiFi{SSID: "Lobby"}, "WIFI:T:nopass;S:Lobby;;"},
+		{"wep", WiFi{SSID: "Old", Password: "0123456789", Auth: AuthWEP}, "WIFI:T:WEP;S:Old;P:0123456789;;"},
+		{"escaped", WiFi{SSID: `a;b,c:d"e\f`, Password: `p;a:s,s"\word`},
+			`WIFI:T:WPA;S:a\;b\,c\:d\"e\\f;P:p\;a\:s\,s\"\\word;;`},
+		{"eap", WiFi{SSID: "Corp", Auth: AuthWPA2EAP, EAPMethod: "PEAP", Phase2: "MSCHAPV2",
+			Identity: "alice", AnonymousIdentity: "anon", Password: "secret"},
+			"WIFI:T:WPA2-EAP;S:Corp;E:PEAP;PH2:MSCHAPV2;A:anon;I:alice;P:secret;;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.wifi.Encode()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWiFiValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		wifi  WiFi
+		field string
+	}{
+		{"empty ssid", WiFi{Password: "password1"}, "SSID"},
+		{"long ssid", WiFi{SSID: strings.Repeat("x", 33)}, "SSID"},
+		{"open with password", WiFi{SSID: "x", Auth: AuthNone, Password: "password1"}, "Password"},
+		{"wpa without password", WiFi{SSID: "x", Auth: AuthWPA}, "Password"},
+		{"short wpa", WiFi{SSID: "x", Password: "short"}, "Password"},
+		{"long wpa", WiFi{SSID: "x", Password: strings.Repeat("x", 64)}, "Password"},
+		{"non-ascii wpa", WiFi{SSID: "x", Password: "pässwörd"}, "Password"},
+		{"bad wep", WiFi{SSID: "x", Auth: AuthWEP, Password: "123456"}, "Password"},
+		{"eap without method", WiFi{SSID: "x", Auth: AuthWPA2EAP}, "EAPMethod"},
+		{"unknown eap method", WiFi{SSID: "x", Auth: AuthWPA2EAP, EAPMethod: "LEAP"}, "EAPMethod"},
+		{"phase2 with tls", WiFi{SSID: "x", Auth: AuthWPA2EAP, EAPMethod: "TLS", Phase2: "PAP"}, "Phase2"},
+		{"identity without eap", WiFi{SSID: "x", Password: "password1", Identity: "alice"}, "Identity"},
+		{"unknown auth", WiFi{SSID: "x", Auth: "WPA3"}, "Auth"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.wifi.Validate()
+			var perr *Error
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected *Error, got %v", err)
+			}
+			if perr.Field != tt.field {
+				t.Errorf("expected error in %s, got %v", tt.field, err)
+			}
+		})
+	}
+
+	// Valid edge cases
+	for _, w := range []WiFi{
+		{SSID: strings.Repeat("x", 32), Password: strings.Repeat("x", 63)},
+		{SSID: "x", Password: strings.Repeat("ab", 32)},
+		{SSID: "x", Auth: AuthWEP, Password: "abcde"},
+	} {
+		if err := w.Validate(); err != nil {
+			t.Errorf("unexpected error for %+v: %v", w, err)
+		}
+	}
+}
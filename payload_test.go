@@ -0,0 +1,215 @@
+package qrgode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ahmedtahas/qr-gode/payload"
+)
+
+func TestNewWiFi(t *testing.T) {
+	qr := NewWiFi(payload.WiFi{SSID: "Café; 2nd floor", Password: "correct horse", Hidden: true})
+	if qr.data != `WIFI:T:WPA;S:Café\; 2nd floor;P:correct horse;H:true;;` {
+		t.Errorf("unexpected data %q", qr.data)
+	}
+	if err := qr.Verify(); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	_, err := NewWiFi(payload.WiFi{SSID: "Office", Password: "short"}).SVG()
+	var perr *payload.Error
+	if !errors.As(err, &perr) || perr.Field != "Password" {
+		t.Errorf("expected payload error for the password, got %v", err)
+	}
+}
+
+func TestNewVCard(t *testing.T) {
+	qr := NewVCard(payload.VCard{
+		GivenName:  "Jane",
+		FamilyName: "Doe",
+		Phones:     []payload.Phone{{Number: "+1 555 0100", Types: []string{"cell"}}},
+	})
+	if !strings.HasPrefix(qr.data, "BEGIN:VCARD\r\nVERSION:3.0\r\n") {
+		t.Errorf("unexpected data %q", qr.data)
+	}
+	if err := qr.Verify(); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	qr = NewMeCard(payload.MeCard{FamilyName: "Doe", GivenName: "Jane"})
+	if qr.data != "MECARD:N:Doe,Jane;;" {
+		t.Errorf("unexpected data %q", qr.data)
+	}
+}
+
+func TestFitVersion(t *testing.T) {
+	card := payload.VCard{
+		GivenName:  "Jane",
+		FamilyName: "Doe",
+		Org:        "Example Corp",
+		Titles:     []string{"Head of Research"},
+		Phones:     []payload.Phone{{Number: "+1 555 0100", Types: []string{"cell"}}},
+		Emails:     []payload.Email{{Address: "jane@example.com"}},
+		Addresses: []payload.Address{{Types: []string{"work"}, Street: "1 Main Street",
+			Locality: "Springfield", PostalCode: "12345", Country: "USA"}},
+		URL:      "https://example.com/jane",
+		Note:     strings.Repeat("A long note about the contact. ", 20),
+		PhotoURL: "https://example.com/jane.jpg",
+	}
+
+	full, err := NewVCard(card).ErrorCorrection(LevelQ).Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	symbol, err := NewVCard(card).FitVersion(12).ErrorCorrection(LevelQ).Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if symbol.Version > 12 || full.Version <= 12 {
+		t.Errorf("expected version %d cut to at most 12, got %d", full.Version, symbol.Version)
+	}
+
+	qr := NewVCard(card).FitVersion(12).ErrorCorrection(LevelQ)
+	data, _ := qr.fittedData()
+	if strings.Contains(data, "NOTE:") || strings.Contains(data, "PHOTO") {
+		t.Error("expected the note and photo dropped")
+	}
+	if !strings.Contains(data, "TEL;TYPE=cell:+1 555 0100") || !strings.Contains(data, "EMAIL:jane@example.com") {
+		t.Error("expected the phone number and email address kept")
+	}
+	if err := qr.Verify(); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	// The name alone needs more than version 1 at level H
+	card.FamilyName = strings.Repeat("Doe", 10)
+	if _, err := NewVCard(card).FitVersion(1).ErrorCorrection(LevelH).SVG(); err == nil {
+		t.Error("expected error when required fields do not fit")
+	}
+	if _, err := New("plain text").FitVersion(5).SVG(); err == nil {
+		t.Error("expected error for FitVersion without a payload")
+	}
+}
+
+func TestNewEPC(t *testing.T) {
+	transfer := payload.EPC{
+		Name:   "Red Cross of Belgium",
+		IBAN:   "BE72 0000 0000 1616",
+		Amount: 12.34,
+		Text:   "Urgency fund",
+	}
+
+	qr := NewEPC(transfer)
+	symbol, err := qr.Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if symbol.ErrorCorrection != LevelM {
+		t.Errorf("expected level M, got %s", symbol.ErrorCorrection)
+	}
+	if err := qr.Verify(); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	// Latin-1 text is announced with an ECI header
+	transfer.Charset = payload.EPCLatin1
+	transfer.Text = "Überweisung"
+	symbol, err = NewEPC(transfer).Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if symbol.Segments[0].Mode != ModeECI {
+		t.Errorf("expected an ECI header, got mode %v", symbol.Segments[0].Mode)
+	}
+
+	// A payload of the largest size, 331 bytes, fits version 13
+	transfer = payload.EPC{
+		Version:     "001",
+		BIC:         "BPOTBEB1XXX",
+		Name:        strings.Repeat("n", 70),
+		IBAN:        "BE72000000001616",
+		Amount:      999999999.99,
+		Purpose:     "CHAR",
+		Text:        strings.Repeat("t", 124),
+		Information: strings.Repeat("i", 70),
+	}
+	if symbol, err = NewEPC(transfer).Encode(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if symbol.Version > 13 {
+		t.Errorf("expected at most version 13, got %d", symbol.Version)
+	}
+
+	tests := []struct {
+		name string
+		qr   *QRCode
+	}{
+		{"level changed", NewEPC(transfer).ErrorCorrection(LevelH)},
+		{"boosted", NewEPC(transfer).BoostECL()},
+		{"version too large", NewEPC(transfer).Version(20)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.qr.SVG(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestNewSwissQRBill(t *testing.T) {
+	bill := payload.SwissQRBill{
+		IBAN:      "CH44 3199 9123 0008 8901 2",
+		Creditor:  payload.SwissAddress{Name: "Robert Schneider AG", Street: "Rue du Lac", BuildingNumber: "1268", PostalCode: "2501", Town: "Biel", Country: "CH"},
+		Amount:    1949.75,
+		Debtor:    &payload.SwissAddress{Name: "Pia-Maria Rutschmann-Schnyder", Street: "Grosse Marktgasse", BuildingNumber: "28", PostalCode: "9400", Town: "Rorschach", Country: "CH"},
+		Reference: "21 00000 00003 13947 14300 09017",
+		Message:   "Order of 15 June 2020",
+	}
+
+	qr := NewSwissQRBill(bill)
+	symbol, err := qr.Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if symbol.ErrorCorrection != LevelM {
+		t.Errorf("expected level M, got %s", symbol.ErrorCorrection)
+	}
+	if err := qr.Verify(); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	// The code is 46 mm and the cross 7 mm, drawn over the modules
+	pdf, err := qr.PDF()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(pdf), "/MediaBox [0 0 130.394 130.394]") {
+		t.Error("expected a 46 mm MediaBox")
+	}
+	svg, err := qr.Size(460).SVG()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(svg), `<image x="195.00" y="195.00" width="70.00" height="70.00"`) {
+		t.Error("expected a 7 mm cross in the center")
+	}
+
+	tests := []struct {
+		name string
+		qr   *QRCode
+	}{
+		{"level changed", NewSwissQRBill(bill).ErrorCorrection(LevelQ)},
+		{"boosted", NewSwissQRBill(bill).BoostECL()},
+		{"invalid reference", NewSwissQRBill(payload.SwissQRBill{IBAN: bill.IBAN, Creditor: bill.Creditor})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.qr.SVG(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
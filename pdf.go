@@ -0,0 +1,497 @@
+package qrgode
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ahmedtahas/qr-gode/internal/colors"
+	"github.com/ahmedtahas/qr-gode/internal/raster"
+)
+
+// pointsPerMM converts millimetres to PDF points.
+const pointsPerMM = 72 / 25.4
+
+// pageScale returns the size of one output pixel in points: one without
+// a PhysicalSize, so the page is Size points wide. EPS output uses it too.
+func (r *renderer) pageScale() float64 {
+	if r.config.PhysicalSize <= 0 {
+		return 1
+	}
+	width, _ := r.pixelSize()
+	return r.config.PhysicalSize * pointsPerMM / float64(width)
+}
+
+// renderPDF generates the QR code as a single page PDF document.
+func (r *renderer) renderPDF() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.writePDF(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writePDF streams a single page PDF document of the QR code to w. The
+// shapes are drawn as the same paths as in SVG, with curves kept exact.
+func (r *renderer) writePDF(w io.Writer) error {
+	doc := &pdfDocument{}
+	page := &pdfPage{doc: doc, shadings: make(map[colors.Color]string)}
+
+	width, height := r.pixelSize()
+	scale := r.pageScale()
+	// Flip the y axis so the content uses SVG pixel coordinates
+	fmt.Fprintf(&page.content, "%s 0 0 %s 0 %s cm\n",
+		vectorNum(scale), vectorNum(-scale), vectorNum(float64(height)*scale))
+
+	bg := r.config.Background
+	if bg == nil {
+		bg = colors.NewSolid("#FFFFFF")
+	}
+	if err := page.fill(fmt.Sprintf("M0 0H%dV%dH0Z", width, height), bg, false); err != nil {
+		return fmt.Errorf("invalid background color: %w", err)
+	}
+
+	var err error
+	if r.hasCustomImages() {
+		err = r.pdfWithImages(page)
+	} else {
+		err = r.pdfWithShapes(page)
+	}
+	if err != nil {
+		return err
+	}
+
+	if r.hasLogo() {
+		if err := r.pdfLogo(page); err != nil {
+			return err
+		}
+	}
+
+	mediaBox := fmt.Sprintf("[0 0 %s %s]", vectorNum(float64(width)*scale), vectorNum(float64(height)*scale))
+	page.finish(mediaBox)
+	return doc.write(w)
+}
+
+// pdfWithShapes fills every vector layer on the page.
+func (r *renderer) pdfWithShapes(page *pdfPage) error {
+	layers, err := r.shapeLayers()
+	if err != nil {
+		return err
+	}
+	for _, layer := range layers {
+		if err := page.fill(layer.path, layer.color, layer.evenOdd); err != nil {
+			return fmt.Errorf("invalid module color: %w", err)
+		}
+	}
+	return nil
+}
+
+// pdfWithImages draws custom module, finder and alignment images,
+// mirroring the layout of writeWithImages.
+func (r *renderer) pdfWithImages(page *pdfPage) error {
+	quietZone := r.config.QuietZone
+	moduleSize := r.moduleSize()
+	images := r.config.Images
+
+	logoMinX, logoMinY, logoMaxX, logoMaxY, hasLogoZone, err := r.calculateExclusionZone()
+	if err != nil {
+		return err
+	}
+
+	var moduleImg, finderImg, alignImg string
+	if images.Module != "" {
+		if moduleImg, err = page.imageFile(images.Module); err != nil {
+			return fmt.Errorf("failed to load module image: %w", err)
+		}
+	}
+	if images.Finder != "" {
+		if finderImg, err = page.imageFile(images.Finder); err != nil {
+			return fmt.Errorf("failed to load finder image: %w", err)
+		}
+	}
+	if images.Alignment != "" {
+		if alignImg, err = page.imageFile(images.Alignment); err != nil {
+			return fmt.Errorf("failed to load alignment image: %w", err)
+		}
+	}
+
+	// Finder patterns, mirrored so each corner faces outward
+	if finderImg != "" {
+		finderSize := 7 * moduleSize
+		for _, origin := range r.matrix.Finders() {
+			px := float64(quietZone+origin[0]) * moduleSize
+			py := float64(quietZone+origin[1]) * moduleSize
+			page.drawImage(finderImg, px, py, finderSize, finderSize, origin[0] > 0, origin[1] > 0)
+		}
+	}
+
+	// Alignment patterns
+	if alignImg != "" {
+		alignSize := 5 * moduleSize
+		for _, pos := range r.matrix.Alignments() {
+			px := float64(quietZone+pos[0]-2) * moduleSize
+			py := float64(quietZone+pos[1]-2) * moduleSize
+			page.drawImage(alignImg, px, py, alignSize, alignSize, false, false)
+		}
+	}
+
+	// Data modules
+	if moduleImg == "" {
+		return nil
+	}
+	for y := 0; y < r.matrix.Height(); y++ {
+		for x := 0; x < r.matrix.Width(); x++ {
+			if r.shouldSkipModule(x, y, images.Module, images.Finder, images.Alignment, hasLogoZone, logoMinX, logoMinY, logoMaxX, logoMaxY) {
+				continue
+			}
+			px := float64(quietZone+x) * moduleSize
+			py := float64(quietZone+y) * moduleSize
+			page.drawImage(moduleImg, px, py, moduleSize, moduleSize, false, false)
+		}
+	}
+	return nil
+}
+
+// pdfLogo draws the logo and its background in the center of the page.
+func (r *renderer) pdfLogo(page *pdfPage) error {
+	logo := r.config.Logo
+
+	var name string
+	var err error
+	if logo.Image != nil {
+		name = page.image(logo.Image)
+	} else if name, err = page.imageFile(logo.Path); err != nil {
+		return fmt.Errorf("failed to load logo: %w", err)
+	}
+
+	logoWidth, logoHeight, padding, err := r.calculateLogoDimensions()
+	if err != nil {
+		return err
+	}
+
+	width, height := r.pixelSize()
+	logoX := (float64(width) - logoWidth) / 2
+	logoY := (float64(height) - logoHeight) / 2
+
+	bgColor := logo.Background
+	if bgColor == "" {
+		bgColor = "#FFFFFF"
+	}
+	if bgColor != "transparent" {
+		d := roundedRectPath(logoX-padding, logoY-padding, logoWidth+2*padding, logoHeight+2*padding, padding/2)
+		if err := page.fill(d, colors.NewSolid(bgColor), false); err != nil {
+			return fmt.Errorf("invalid logo background: %w", err)
+		}
+	}
+
+	page.drawImage(name, logoX, logoY, logoWidth, logoHeight, false, false)
+	return nil
+}
+
+// pdfPage collects the content stream of the page and the resources it
+// uses.
+type pdfPage struct {
+	doc     *pdfDocument
+	content bytes.Buffer
+
+	shadings  map[colors.Color]string // Resource name of each gradient
+	resources struct {
+		shading, xObject, extGState strings.Builder
+	}
+	alphas  map[uint8]string // Graphics state names by fill opacity
+	files   map[string]string
+	nImages int
+}
+
+// fill fills SVG path data in pixel coordinates with c. Gradients span
+// the bounding box of the path, matching the objectBoundingBox units of
+// the SVG output.
+func (p *pdfPage) fill(d string, c colors.Color, evenOdd bool) error {
+	outline, err := raster.ParseOutline(d)
+	if err != nil {
+		return fmt.Errorf("invalid shape path: %w", err)
+	}
+	if len(outline) == 0 {
+		return nil
+	}
+
+	switch g := vectorColor(c).(type) {
+	case *colors.Solid:
+		rgba, err := colors.ToRGBA(g.Hex)
+		if err != nil {
+			return err
+		}
+		if rgba.A == 0 {
+			return nil
+		}
+		p.content.WriteString("q\n")
+		if rgba.A < 255 {
+			fmt.Fprintf(&p.content, "/%s gs\n", p.alpha(rgba.A))
+		}
+		fmt.Fprintf(&p.content, "%s rg\n", rgbColor.components(rgba))
+		writePathOps(&p.content, outline)
+		if evenOdd {
+			p.content.WriteString("f*\nQ\n")
+		} else {
+			p.content.WriteString("f\nQ\n")
+		}
+		return nil
+	case *colors.LinearGradient, *colors.RadialGradient:
+		name, err := p.shading(g)
+		if err != nil {
+			return err
+		}
+		box, err := gradientBox(d)
+		if err != nil {
+			return err
+		}
+		p.content.WriteString("q\n")
+		writePathOps(&p.content, outline)
+		if evenOdd {
+			p.content.WriteString("W* n\n")
+		} else {
+			p.content.WriteString("W n\n")
+		}
+		fmt.Fprintf(&p.content, "%s cm\n/%s sh\nQ\n", box, name)
+	}
+	return nil
+}
+
+// alpha returns the graphics state setting the fill opacity to a/255.
+func (p *pdfPage) alpha(a uint8) string {
+	if p.alphas == nil {
+		p.alphas = make(map[uint8]string)
+	}
+	if name, ok := p.alphas[a]; ok {
+		return name
+	}
+	name := fmt.Sprintf("GS%d", len(p.alphas))
+	ref := p.doc.add(fmt.Sprintf("<< /Type /ExtGState /ca %s >>", vectorNum(float64(a)/255)))
+	fmt.Fprintf(&p.resources.extGState, "/%s %d 0 R ", name, ref)
+	p.alphas[a] = name
+	return name
+}
+
+// shading returns the resource name of the shading of a gradient.
+func (p *pdfPage) shading(c colors.Color) (string, error) {
+	if name, ok := p.shadings[c]; ok {
+		return name, nil
+	}
+
+	dict, err := shadingDict(c, rgbColor)
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("Sh%d", len(p.shadings))
+	ref := p.doc.add(dict)
+	fmt.Fprintf(&p.resources.shading, "/%s %d 0 R ", name, ref)
+	p.shadings[c] = name
+	return name, nil
+}
+
+// imageFile embeds a PNG or JPEG file once and returns its resource
+// name. JPEG data is embedded as is.
+func (p *pdfPage) imageFile(path string) (string, error) {
+	if name, ok := p.files[path]; ok {
+		return name, nil
+	}
+	if strings.ToLower(filepath.Ext(path)) == ".svg" {
+		return "", fmt.Errorf("SVG images cannot be embedded in PDF: %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+
+	var colorSpace string
+	switch cfg.ColorModel {
+	case color.GrayModel:
+		colorSpace = "/DeviceGray"
+	case color.YCbCrModel:
+		colorSpace = "/DeviceRGB"
+	}
+
+	var name string
+	if format == "jpeg" && colorSpace != "" {
+		name = p.addImage(fmt.Sprintf("/Width %d /Height %d /ColorSpace %s /BitsPerComponent 8 /Filter /DCTDecode",
+			cfg.Width, cfg.Height, colorSpace), data)
+	} else {
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return "", err
+		}
+		name = p.image(img)
+	}
+
+	if p.files == nil {
+		p.files = make(map[string]string)
+	}
+	p.files[path] = name
+	return name, nil
+}
+
+// image embeds img as a compressed RGB image, with a soft mask if it has
+// transparent pixels, and returns its resource name.
+func (p *pdfPage) image(img image.Image) string {
+	b := img.Bounds()
+	rgb := make([]byte, 0, 3*b.Dx()*b.Dy())
+	alpha := make([]byte, 0, b.Dx()*b.Dy())
+	opaque := true
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			rgb = append(rgb, c.R, c.G, c.B)
+			alpha = append(alpha, c.A)
+			opaque = opaque && c.A == 255
+		}
+	}
+
+	dict := fmt.Sprintf("/Width %d /Height %d /BitsPerComponent 8", b.Dx(), b.Dy())
+	if !opaque {
+		mask := p.doc.addStream(fmt.Sprintf("/Type /XObject /Subtype /Image %s /ColorSpace /DeviceGray /Filter /FlateDecode", dict),
+			deflate(alpha))
+		dict += fmt.Sprintf(" /SMask %d 0 R", mask)
+	}
+	return p.addImage(dict+" /ColorSpace /DeviceRGB /Filter /FlateDecode", deflate(rgb))
+}
+
+// addImage adds an image XObject to the resources.
+func (p *pdfPage) addImage(dict string, data []byte) string {
+	name := fmt.Sprintf("Im%d", p.nImages)
+	p.nImages++
+	ref := p.doc.addStream("/Type /XObject /Subtype /Image "+dict, data)
+	fmt.Fprintf(&p.resources.xObject, "/%s %d 0 R ", name, ref)
+	return name
+}
+
+// drawImage paints an image XObject into the box (x, y, w, h), mirrored
+// horizontally or vertically if asked.
+func (p *pdfPage) drawImage(name string, x, y, w, h float64, flipX, flipY bool) {
+	// Image space has its first row at the top of the unit square, which
+	// the flipped page puts at the bottom
+	a, d, e, f := w, -h, x, y+h
+	if flipX {
+		a, e = -w, x+w
+	}
+	if flipY {
+		d, f = h, y
+	}
+	fmt.Fprintf(&p.content, "q\n%s 0 0 %s %s %s cm\n/%s Do\nQ\n", vectorNum(a), vectorNum(d), vectorNum(e), vectorNum(f), name)
+}
+
+// finish adds the content stream and the page, page tree and catalog
+// objects.
+func (p *pdfPage) finish(mediaBox string) {
+	content := p.doc.addStream("/Filter /FlateDecode", deflate(p.content.Bytes()))
+
+	var res strings.Builder
+	res.WriteString("<< ")
+	for _, r := range []struct {
+		key  string
+		refs *strings.Builder
+	}{
+		{"ExtGState", &p.resources.extGState},
+		{"Shading", &p.resources.shading},
+		{"XObject", &p.resources.xObject},
+	} {
+		if r.refs.Len() > 0 {
+			fmt.Fprintf(&res, "/%s << %s>> ", r.key, r.refs.String())
+		}
+	}
+	res.WriteString(">>")
+
+	pages := len(p.doc.objects) + 2
+	page := p.doc.add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox %s /Resources %s /Contents %d 0 R >>",
+		pages, mediaBox, res.String(), content))
+	p.doc.add(fmt.Sprintf("<< /Type /Pages /Kids [%d 0 R] /Count 1 >>", page))
+	p.doc.root = p.doc.add(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+	p.doc.info = p.doc.add("<< /Producer (qr-gode) >>")
+}
+
+// pdfDocument holds the objects of a PDF file, numbered from 1.
+type pdfDocument struct {
+	objects    [][]byte
+	root, info int
+}
+
+// add adds an object and returns its number.
+func (d *pdfDocument) add(body string) int {
+	d.objects = append(d.objects, []byte(body))
+	return len(d.objects)
+}
+
+// addStream adds a stream object with the given dictionary entries.
+func (d *pdfDocument) addStream(dict string, data []byte) int {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<< %s /Length %d >>\nstream\n", dict, len(data))
+	buf.Write(data)
+	buf.WriteString("\nendstream")
+	d.objects = append(d.objects, buf.Bytes())
+	return len(d.objects)
+}
+
+// write streams the document to out with its cross-reference table and
+// returns the first error writing it.
+func (d *pdfDocument) write(out io.Writer) error {
+	w := &countingWriter{w: bufio.NewWriter(out)}
+
+	// The binary comment marks the file as binary for transfer tools
+	w.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int64, len(d.objects))
+	for i, body := range d.objects {
+		offsets[i] = w.n
+		fmt.Fprintf(w, "%d 0 obj\n", i+1)
+		w.Write(body)
+		w.WriteString("\nendobj\n")
+	}
+
+	xref := w.n
+	fmt.Fprintf(w, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(w, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(w, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(d.objects)+1, d.root, d.info, xref)
+	return w.w.Flush()
+}
+
+// countingWriter counts the bytes written for the cross-reference
+// offsets. Write errors are kept by the bufio.Writer and returned by
+// Flush.
+type countingWriter struct {
+	w *bufio.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingWriter) WriteString(s string) {
+	n, _ := c.w.WriteString(s)
+	c.n += int64(n)
+}
+
+// deflate compresses data for the FlateDecode filter.
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
@@ -0,0 +1,221 @@
+package qrgode
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPDFGeneration(t *testing.T) {
+	data, err := New("https://example.com").Size(290).PDF()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) {
+		t.Errorf("expected PDF header, got %q", data[:min(len(data), 9)])
+	}
+	checkPDFXref(t, data)
+	if !bytes.Contains(data, []byte("/MediaBox [0 0 290 290]")) {
+		t.Error("expected a 290 point MediaBox")
+	}
+
+	content := pdfContent(t, data)
+	if !strings.HasPrefix(content, "1 0 0 -1 0 290 cm\n") {
+		t.Errorf("expected flipped page coordinates, got %q", content[:min(len(content), 40)])
+	}
+	if !strings.Contains(content, "0 0 0 rg\n") || !strings.Contains(content, "\nf\n") {
+		t.Error("expected black filled paths")
+	}
+}
+
+func TestPDFPhysicalSize(t *testing.T) {
+	data, err := New("https://example.com").PhysicalSize(25.4).PDF()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 25.4 mm is one inch, 72 points
+	if !bytes.Contains(data, []byte("/MediaBox [0 0 72 72]")) {
+		t.Error("expected a 72 point MediaBox")
+	}
+
+	if _, err := New("test").PhysicalSize(-1).PDF(); err == nil {
+		t.Error("expected error for negative physical size")
+	}
+}
+
+func TestPDFRectangular(t *testing.T) {
+	data, err := New("https://example.com").Symbol(SymbolRMQR).QuietZone(2).Size(400).PDF()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	box := regexp.MustCompile(`/MediaBox \[0 0 (\S+) (\S+)\]`).FindSubmatch(data)
+	if box == nil {
+		t.Fatal("expected a MediaBox")
+	}
+	w, _ := strconv.ParseFloat(string(box[1]), 64)
+	h, _ := strconv.ParseFloat(string(box[2]), 64)
+	if w != 400 || h >= w {
+		t.Errorf("expected a page 400 points wide and less high, got %vx%v", w, h)
+	}
+}
+
+func TestPDFCurves(t *testing.T) {
+	data, err := New("test").Shape(ShapeCircle).PDF()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content := pdfContent(t, data); !strings.Contains(content, " c\n") {
+		t.Error("expected circles drawn as curves")
+	}
+}
+
+func TestPDFGradients(t *testing.T) {
+	tests := []struct {
+		name    string
+		qr      *QRCode
+		shading string
+	}{
+		{"linear", New("test").LinearGradient(45, "#ff0000", "#00ff00", "#0000ff"), "/ShadingType 2"},
+		{"radial", New("test").RadialGradient(0.5, 0.5, "#ff0000", "#0000ff"), "/ShadingType 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.qr.PDF()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Contains(data, []byte(tt.shading)) {
+				t.Errorf("expected %s", tt.shading)
+			}
+			if content := pdfContent(t, data); !strings.Contains(content, "W n\n") || !strings.Contains(content, "/Sh0 sh\n") {
+				t.Error("expected shading clipped to the modules")
+			}
+		})
+	}
+
+	// Three stops stitch two functions
+	data, _ := tests[0].qr.PDF()
+	if !bytes.Contains(data, []byte("/Bounds [0.5]")) {
+		t.Error("expected the middle stop at 0.5")
+	}
+}
+
+func TestPDFWithLogo(t *testing.T) {
+	dir := t.TempDir()
+
+	jpegPath := filepath.Join(dir, "logo.jpg")
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	for i := range img.Pix {
+		img.Pix[i] = 200
+	}
+	f, err := os.Create(jpegPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(f, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	data, err := New("https://example.com").ErrorCorrection(LevelH).Logo(jpegPath).PDF()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(data, []byte("/Filter /DCTDecode")) {
+		t.Error("expected JPEG logo embedded as is")
+	}
+	if content := pdfContent(t, data); !strings.Contains(content, "/Im0 Do\n") {
+		t.Error("expected logo drawn")
+	}
+
+	// Transparent pixels need a soft mask
+	transparent := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	transparent.SetNRGBA(10, 10, color.NRGBA{255, 0, 0, 255})
+	data, err = New("https://example.com").ErrorCorrection(LevelH).LogoImage(transparent).PDF()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(data, []byte("/SMask")) {
+		t.Error("expected soft mask for transparent logo")
+	}
+	checkPDFXref(t, data)
+}
+
+func TestPDFRejectsSVGLogo(t *testing.T) {
+	dir := t.TempDir()
+	logoPath := filepath.Join(dir, "logo.svg")
+	if err := os.WriteFile(logoPath, []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New("test").Logo(logoPath).PDF(); err == nil {
+		t.Error("expected error for SVG logo in PDF output")
+	}
+}
+
+func TestGenerateToFilePDF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "qr.pdf")
+	if err := GenerateToFile("https://example.com", nil, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPDFXref(t, data)
+}
+
+// checkPDFXref checks that every cross-reference entry points at the
+// start of its object.
+func checkPDFXref(t *testing.T, data []byte) {
+	t.Helper()
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(data)
+	if m == nil {
+		t.Fatal("expected startxref at the end")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	lines := strings.Split(string(data[xref:]), "\n")
+	if lines[0] != "xref" {
+		t.Fatalf("expected xref table at offset %d", xref)
+	}
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+	for i := 1; i < count; i++ {
+		off, _ := strconv.Atoi(lines[2+i][:10])
+		if !bytes.HasPrefix(data[off:], []byte(strconv.Itoa(i)+" 0 obj\n")) {
+			t.Errorf("xref entry %d points at offset %d, not its object", i, off)
+		}
+	}
+}
+
+// pdfContent returns the decompressed content stream of the page.
+func pdfContent(t *testing.T, data []byte) string {
+	t.Helper()
+	m := regexp.MustCompile(`/Contents (\d+) 0 R`).FindSubmatch(data)
+	if m == nil {
+		t.Fatal("expected page contents")
+	}
+	obj := bytes.Index(data, []byte("\n"+string(m[1])+" 0 obj\n"))
+	start := obj + bytes.Index(data[obj:], []byte("stream\n")) + len("stream\n")
+	end := obj + bytes.Index(data[obj:], []byte("\nendstream"))
+	zr, err := zlib.NewReader(bytes.NewReader(data[start:end]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
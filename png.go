@@ -0,0 +1,305 @@
+package qrgode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ahmedtahas/qr-gode/internal/colors"
+	"github.com/ahmedtahas/qr-gode/internal/raster"
+)
+
+// renderPNG rasterizes the QR code and encodes it as PNG.
+func (r *renderer) renderPNG() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.writePNG(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writePNG rasterizes the QR code and streams it to w as PNG.
+func (r *renderer) writePNG(w io.Writer) error {
+	img, err := r.renderImage()
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return nil
+}
+
+// renderImage rasterizes the QR code into an RGBA image.
+// It draws the same geometry as renderSVG, with anti-aliased edges.
+func (r *renderer) renderImage() (*image.RGBA, error) {
+	width, height := r.pixelSize()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	if err := r.rasterBackground(img); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if r.hasCustomImages() {
+		err = r.rasterWithImages(img)
+	} else {
+		err = r.rasterWithShapes(img)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if r.hasLogo() {
+		if err := r.rasterLogo(img); err != nil {
+			return nil, err
+		}
+	}
+
+	return img, nil
+}
+
+func (r *renderer) rasterBackground(img *image.RGBA) error {
+	bg := r.config.Background
+	if bg == nil {
+		bg = colors.NewSolid("#FFFFFF")
+	}
+
+	width, height := r.pixelSize()
+	paint, err := paintFor(bg, 0, 0, float64(width), float64(height))
+	if err != nil {
+		return fmt.Errorf("invalid background color: %w", err)
+	}
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			img.SetRGBA(x, y, premultiply(paint(x, y)))
+		}
+	}
+	return nil
+}
+
+// rasterWithShapes fills every vector layer onto img.
+func (r *renderer) rasterWithShapes(img *image.RGBA) error {
+	layers, err := r.shapeLayers()
+	if err != nil {
+		return err
+	}
+
+	for _, layer := range layers {
+		path, err := raster.ParseSVG(layer.path)
+		if err != nil {
+			return fmt.Errorf("invalid shape path: %w", err)
+		}
+		if path.Empty() {
+			continue
+		}
+
+		// Gradients span the bounding box of each path, as in SVG
+		minX, minY, maxX, maxY := path.Bounds()
+		paint, err := paintFor(layer.color, minX, minY, maxX-minX, maxY-minY)
+		if err != nil {
+			return fmt.Errorf("invalid module color: %w", err)
+		}
+
+		rule := raster.NonZero
+		if layer.evenOdd {
+			rule = raster.EvenOdd
+		}
+		raster.Fill(img, path, rule, paint)
+	}
+	return nil
+}
+
+// rasterWithImages draws custom module, finder and alignment images,
+// mirroring the layout of renderWithImages.
+func (r *renderer) rasterWithImages(img *image.RGBA) error {
+	quietZone := r.config.QuietZone
+	moduleSize := r.moduleSize()
+	images := r.config.Images
+
+	logoMinX, logoMinY, logoMaxX, logoMaxY, hasLogoZone, err := r.calculateExclusionZone()
+	if err != nil {
+		return err
+	}
+
+	var moduleImg, finderImg, alignImg image.Image
+	if images.Module != "" {
+		if moduleImg, err = loadRasterImage(images.Module); err != nil {
+			return fmt.Errorf("failed to load module image: %w", err)
+		}
+	}
+	if images.Finder != "" {
+		if finderImg, err = loadRasterImage(images.Finder); err != nil {
+			return fmt.Errorf("failed to load finder image: %w", err)
+		}
+	}
+	if images.Alignment != "" {
+		if alignImg, err = loadRasterImage(images.Alignment); err != nil {
+			return fmt.Errorf("failed to load alignment image: %w", err)
+		}
+	}
+
+	// Finder patterns, mirrored so each corner faces outward
+	if finderImg != nil {
+		finderSize := 7 * moduleSize
+		for _, origin := range r.matrix.Finders() {
+			px := float64(quietZone+origin[0]) * moduleSize
+			py := float64(quietZone+origin[1]) * moduleSize
+			raster.DrawImage(img, finderImg, px, py, finderSize, finderSize, origin[0] > 0, origin[1] > 0)
+		}
+	}
+
+	// Alignment patterns
+	if alignImg != nil {
+		alignSize := 5 * moduleSize
+		for _, pos := range r.matrix.Alignments() {
+			px := float64(quietZone+pos[0]-2) * moduleSize
+			py := float64(quietZone+pos[1]-2) * moduleSize
+			raster.DrawImage(img, alignImg, px, py, alignSize, alignSize, false, false)
+		}
+	}
+
+	// Data modules
+	if moduleImg == nil {
+		return nil
+	}
+	for y := 0; y < r.matrix.Height(); y++ {
+		for x := 0; x < r.matrix.Width(); x++ {
+			if r.shouldSkipModule(x, y, images.Module, images.Finder, images.Alignment, hasLogoZone, logoMinX, logoMinY, logoMaxX, logoMaxY) {
+				continue
+			}
+			px := float64(quietZone+x) * moduleSize
+			py := float64(quietZone+y) * moduleSize
+			raster.DrawImage(img, moduleImg, px, py, moduleSize, moduleSize, false, false)
+		}
+	}
+	return nil
+}
+
+// rasterLogo draws the logo and its background in the center of img.
+func (r *renderer) rasterLogo(img *image.RGBA) error {
+	logo := r.config.Logo
+
+	logoImg := logo.Image
+	if logoImg == nil {
+		var err error
+		logoImg, err = loadRasterImage(logo.Path)
+		if err != nil {
+			return fmt.Errorf("failed to load logo: %w", err)
+		}
+	}
+
+	logoWidth, logoHeight, padding, err := r.calculateLogoDimensions()
+	if err != nil {
+		return err
+	}
+
+	width, height := r.pixelSize()
+	logoX := (float64(width) - logoWidth) / 2
+	logoY := (float64(height) - logoHeight) / 2
+
+	bgColor := logo.Background
+	if bgColor == "" {
+		bgColor = "#FFFFFF"
+	}
+	if bgColor != "transparent" {
+		bg, err := colors.ToRGBA(bgColor)
+		if err != nil {
+			return fmt.Errorf("invalid logo background: %w", err)
+		}
+		d := roundedRectPath(logoX-padding, logoY-padding, logoWidth+2*padding, logoHeight+2*padding, padding/2)
+		path, err := raster.ParseSVG(d)
+		if err != nil {
+			return err
+		}
+		raster.Fill(img, path, raster.NonZero, raster.SolidPaint(bg))
+	}
+
+	raster.DrawImage(img, logoImg, logoX, logoY, logoWidth, logoHeight, false, false)
+	return nil
+}
+
+// paintFor returns a raster paint for a color source. Position-dependent
+// colors are evaluated relative to the box (x, y, w, h), matching the
+// objectBoundingBox units used by the SVG gradients.
+func paintFor(c colors.Color, x, y, w, h float64) (raster.Paint, error) {
+	if colors.IsPerModule(c) {
+		// Already split into solid layers; elsewhere use the SVG fill
+		c = colors.NewSolid(c.SVGFill(""))
+	}
+	if solid, ok := c.(*colors.Solid); ok {
+		rgba, err := colors.ToRGBA(solid.Hex)
+		if err != nil {
+			return nil, err
+		}
+		return raster.SolidPaint(rgba), nil
+	}
+
+	// Validate once up front so per-pixel lookups can't fail
+	if _, err := colors.ToRGBA(c.ColorAt(0.5, 0.5)); err != nil {
+		return nil, err
+	}
+
+	if w <= 0 {
+		w = 1
+	}
+	if h <= 0 {
+		h = 1
+	}
+	cache := make(map[string]color.RGBA)
+	return func(px, py int) color.RGBA {
+		hex := c.ColorAt((float64(px)+0.5-x)/w, (float64(py)+0.5-y)/h)
+		rgba, ok := cache[hex]
+		if !ok {
+			rgba, _ = colors.ToRGBA(hex)
+			cache[hex] = rgba
+		}
+		return rgba
+	}, nil
+}
+
+// premultiply converts a non-premultiplied color for storage in image.RGBA.
+func premultiply(c color.RGBA) color.RGBA {
+	a := uint32(c.A)
+	return color.RGBA{
+		R: uint8(uint32(c.R) * a / 255),
+		G: uint8(uint32(c.G) * a / 255),
+		B: uint8(uint32(c.B) * a / 255),
+		A: c.A,
+	}
+}
+
+// roundedRectPath returns SVG path data for a rectangle with rounded corners.
+func roundedRectPath(x, y, w, h, rx float64) string {
+	rx = min(rx, w/2, h/2)
+	return fmt.Sprintf("M%.2f %.2fh%.2fa%.2f %.2f 0 0 1 %.2f %.2fv%.2fa%.2f %.2f 0 0 1 %.2f %.2fh%.2fa%.2f %.2f 0 0 1 %.2f %.2fv%.2fa%.2f %.2f 0 0 1 %.2f %.2fz",
+		x+rx, y, w-2*rx, rx, rx, rx, rx, h-2*rx, rx, rx, -rx, rx, -(w - 2*rx), rx, rx, -rx, -rx, -(h - 2*rx), rx, rx, rx, -rx)
+}
+
+// loadRasterImage decodes a PNG or JPEG file. SVG images cannot be
+// rasterized without an SVG engine and are rejected.
+func loadRasterImage(path string) (image.Image, error) {
+	if strings.ToLower(filepath.Ext(path)) == ".svg" {
+		return nil, fmt.Errorf("SVG images cannot be rasterized: %s", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
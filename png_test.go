@@ -0,0 +1,233 @@
+package qrgode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPNGGeneration(t *testing.T) {
+	data, err := New("https://example.com").Size(290).QuietZone(4).PNG()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("output is not a valid PNG: %v", err)
+	}
+	if img.Bounds().Dx() != 290 || img.Bounds().Dy() != 290 {
+		t.Errorf("expected 290x290 image, got %v", img.Bounds())
+	}
+
+	// Version 2 is 25 modules + 8 quiet zone = 33 modules, ~8.8px each
+	moduleSize := 290.0 / 33.0
+	quiet := pixelAt(img, 4, 4)
+	if quiet != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("expected white quiet zone, got %v", quiet)
+	}
+	// Center of the top-left finder pattern is dark
+	center := int((4 + 3.5) * moduleSize)
+	if dark := pixelAt(img, center, center); dark != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("expected black finder center, got %v", dark)
+	}
+}
+
+func TestImageMatchesPNG(t *testing.T) {
+	qr := New("test").Size(128).Shape(ShapeCircle)
+
+	img, err := qr.Image()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := qr.PNG()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for y := 0; y < 128; y += 7 {
+		for x := 0; x < 128; x += 7 {
+			if pixelAt(img, x, y) != pixelAt(decoded, x, y) {
+				t.Fatalf("pixel (%d,%d) differs between Image() and PNG()", x, y)
+			}
+		}
+	}
+}
+
+func TestPNGAntiAliasing(t *testing.T) {
+	img, err := New("test").Size(200).Shape(ShapeCircle).Image()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Circle edges must produce intermediate gray levels
+	gray := 0
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := pixelAt(img, x, y)
+			if c.R > 0 && c.R < 255 {
+				gray++
+			}
+		}
+	}
+	if gray == 0 {
+		t.Error("expected anti-aliased edge pixels")
+	}
+}
+
+func TestPNGGradient(t *testing.T) {
+	img, err := New("HELLO").Size(210).LinearGradient(0, "#ff0000", "#0000ff").Image()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Top-left finder is near the red end, top-right near the blue end
+	moduleSize := 210.0 / 29.0
+	left := pixelAt(img, int(4.5*moduleSize), int(4.5*moduleSize))
+	right := pixelAt(img, int(23.5*moduleSize), int(4.5*moduleSize))
+	if left.R <= left.B {
+		t.Errorf("expected reddish left finder, got %v", left)
+	}
+	if right.B <= right.R {
+		t.Errorf("expected bluish right finder, got %v", right)
+	}
+}
+
+func TestPNGTransparentBackground(t *testing.T) {
+	img, err := New("test").Size(100).Background("transparent").Image()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c := pixelAt(img, 1, 1); c.A != 0 {
+		t.Errorf("expected transparent corner, got %v", c)
+	}
+}
+
+func TestPNGWithLogo(t *testing.T) {
+	dir := t.TempDir()
+	logoPath := filepath.Join(dir, "logo.png")
+	writeTestPNG(t, logoPath, 40, 40, color.RGBA{255, 0, 0, 255})
+
+	img, err := New("https://example.com").
+		Size(400).
+		ErrorCorrection(LevelH).
+		Logo(logoPath).
+		Image()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if c := pixelAt(img, 200, 200); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("expected red logo in center, got %v", c)
+	}
+}
+
+func TestPNGWithModuleImage(t *testing.T) {
+	dir := t.TempDir()
+	modulePath := filepath.Join(dir, "module.png")
+	writeTestPNG(t, modulePath, 8, 8, color.RGBA{0, 128, 0, 255})
+
+	img, err := New("test").Size(210).ModuleImage(modulePath).Image()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Top-left finder corner module is drawn with the module image
+	moduleSize := 210.0 / 29.0
+	c := pixelAt(img, int(4.5*moduleSize), int(4.5*moduleSize))
+	if c != (color.RGBA{0, 128, 0, 255}) {
+		t.Errorf("expected green module image, got %v", c)
+	}
+}
+
+func TestPNGRejectsSVGLogo(t *testing.T) {
+	dir := t.TempDir()
+	logoPath := filepath.Join(dir, "logo.svg")
+	if err := os.WriteFile(logoPath, []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New("test").Logo(logoPath).PNG(); err == nil {
+		t.Error("expected error for SVG logo in PNG output")
+	}
+}
+
+func TestGenerateToFilePNG(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "qr.png")
+	if err := GenerateToFile("https://example.com", nil, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := png.Decode(f); err != nil {
+		t.Errorf("expected valid PNG file: %v", err)
+	}
+}
+
+func TestGenerateToFileFailedRender(t *testing.T) {
+	dir := t.TempDir()
+	logoPath := filepath.Join(dir, "logo.svg")
+	if err := os.WriteFile(logoPath, []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.Logo = &LogoConfig{Path: logoPath}
+
+	// SVG logos cannot be rasterized, which fails after encoding
+	path := filepath.Join(dir, "qr.png")
+	if err := GenerateToFile("https://example.com", cfg, path); err == nil {
+		t.Fatal("expected error for SVG logo in PNG output")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no file after a failed render, got %v", err)
+	}
+
+	// An existing file is kept as it was
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := New("https://example.com").Logo(logoPath).SaveAs(path); err == nil {
+		t.Fatal("expected error for SVG logo in PNG output")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Errorf("expected the existing file kept, got %q", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("expected no temporary files left, got %d entries", len(entries))
+	}
+}
+
+func pixelAt(img image.Image, x, y int) color.RGBA {
+	return color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+}
+
+func writeTestPNG(t *testing.T, path string, w, h int, c color.RGBA) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
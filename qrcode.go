@@ -1,6 +1,8 @@
 package qrgode
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,49 +14,188 @@ import (
 // Returns SVG as a byte slice.
 // If cfg is nil, DefaultConfig() is used.
 func Generate(data string, cfg *Config) ([]byte, error) {
-	if data == "" {
-		return nil, &ValidationError{Field: "Data", Message: "cannot be empty"}
+	renderer, err := prepareRenderer(data, cfg)
+	if err != nil {
+		return nil, err
 	}
+	return renderer.renderSVG()
+}
 
-	if cfg == nil {
-		cfg = DefaultConfig()
+// GeneratePNG creates a QR code from the given data and config.
+// Returns PNG as a byte slice, Size pixels wide and high.
+// If cfg is nil, DefaultConfig() is used.
+func GeneratePNG(data string, cfg *Config) ([]byte, error) {
+	renderer, err := prepareRenderer(data, cfg)
+	if err != nil {
+		return nil, err
 	}
+	return renderer.renderPNG()
+}
 
-	// Validate configuration
-	if errs := ValidateConfig(cfg); len(errs) > 0 {
-		return nil, errs[0]
+// GeneratePDF creates a QR code from the given data and config.
+// Returns a single page PDF document with the shapes as vector paths.
+// If cfg is nil, DefaultConfig() is used.
+func GeneratePDF(data string, cfg *Config) ([]byte, error) {
+	renderer, err := prepareRenderer(data, cfg)
+	if err != nil {
+		return nil, err
 	}
+	return renderer.renderPDF()
+}
 
-	// Convert public ECL to internal ECL
-	ecl := encoder.ErrorCorrectionLevel(cfg.ErrorCorrection)
-
-	// Encode data using internal/encoder
-	enc := encoder.New(data, ecl)
-	matrix, err := enc.Encode()
+// GenerateEPS creates a QR code from the given data and config.
+// Returns an Encapsulated PostScript file for print workflows.
+// If cfg is nil, DefaultConfig() is used.
+func GenerateEPS(data string, cfg *Config) ([]byte, error) {
+	renderer, err := prepareRenderer(data, cfg)
 	if err != nil {
 		return nil, err
 	}
+	return renderer.renderEPS()
+}
 
-	// Render to SVG
-	renderer := newRenderer(matrix, cfg)
-	return renderer.renderSVG()
+// Format selects the output format of the Write functions.
+type Format int
+
+const (
+	FormatSVG      Format = iota // SVG document
+	FormatPNG                    // PNG image, Size pixels wide
+	FormatPDF                    // Single page PDF document, PhysicalSize wide
+	FormatEPS                    // Encapsulated PostScript, PhysicalSize wide
+	FormatTerminal               // Half blocks in 24-bit ANSI colors, two rows per line
+	FormatUTF8                   // Half blocks without colors, dark modules as ink
+	FormatASCII                  // "##" per dark module, one row per line
+)
+
+// formatForPath returns the format for a file extension: PNG for .png,
+// PDF for .pdf, EPS for .eps, UTF-8 text for .txt and SVG otherwise.
+func formatForPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		return FormatPNG
+	case ".pdf":
+		return FormatPDF
+	case ".eps":
+		return FormatEPS
+	case ".txt":
+		return FormatUTF8
+	}
+	return FormatSVG
+}
+
+// GenerateTo creates a QR code from the given data and config and
+// streams it to w in the given format. Errors from w are returned.
+// If cfg is nil, DefaultConfig() is used.
+func GenerateTo(w io.Writer, data string, cfg *Config, format Format) error {
+	renderer, err := prepareRenderer(data, cfg)
+	if err != nil {
+		return err
+	}
+	return renderer.write(w, format)
 }
 
 // GenerateToFile creates a QR code and writes it to the specified path.
-// Supports .svg and .png extensions.
+// Supports .svg, .png, .pdf, .eps and .txt extensions. Nothing is written
+// if generating fails, and an existing file at path is kept.
 func GenerateToFile(data string, cfg *Config, path string) error {
-	svg, err := Generate(data, cfg)
+	renderer, err := prepareRenderer(data, cfg)
 	if err != nil {
 		return err
 	}
+	return renderer.writeFile(path, formatForPath(path))
+}
 
-	ext := strings.ToLower(filepath.Ext(path))
-	if ext == ".png" {
-		// PNG not yet implemented
-		renderer := newRenderer(nil, cfg)
-		_, err := renderer.RenderPNG()
+// GenerateToFileFormat is GenerateToFile with the format given rather
+// than chosen by extension, like ASCII art in a .txt file.
+func GenerateToFileFormat(data string, cfg *Config, path string, format Format) error {
+	renderer, err := prepareRenderer(data, cfg)
+	if err != nil {
 		return err
 	}
+	return renderer.writeFile(path, format)
+}
+
+// writeFile streams the QR code to a file in the given format. It is
+// written to a temporary file in the same directory
+// and renamed to path once complete, so a failed render leaves no
+// partial file behind.
+func (r *renderer) writeFile(path string, format Format) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // Fails harmlessly once renamed
+
+	if err := r.write(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
 
-	return os.WriteFile(path, svg, 0644)
+// write streams the QR code to w in the given format.
+func (r *renderer) write(w io.Writer, format Format) error {
+	switch format {
+	case FormatSVG:
+		return r.writeSVG(w)
+	case FormatPNG:
+		return r.writePNG(w)
+	case FormatPDF:
+		return r.writePDF(w)
+	case FormatEPS:
+		return r.writeEPS(w)
+	case FormatTerminal, FormatUTF8, FormatASCII:
+		return r.writeText(w, format)
+	}
+	return &UnsupportedFormatError{Format: fmt.Sprintf("Format(%d)", int(format))}
+}
+
+// prepareRenderer validates the config, encodes data and returns a
+// renderer ready to produce output.
+func prepareRenderer(data string, cfg *Config) (*renderer, error) {
+	if data == "" {
+		return nil, &ValidationError{Field: "Data", Message: "cannot be empty"}
+	}
+
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	// Validate configuration
+	if errs := ValidateConfig(cfg); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	return encodeRenderer(data, cfg, nil)
+}
+
+// newEncoder returns an encoder for data with the data settings of a
+// validated config.
+func newEncoder(data string, cfg *Config) *encoder.Encoder {
+	// Convert public ECL to internal ECL
+	enc := encoder.New(data, encoder.ErrorCorrectionLevel(cfg.ErrorCorrection))
+	enc.SetCharset(charset(cfg))
+	enc.SetSymbol(encoder.Symbol(cfg.Symbol))
+	enc.SetMaxHeight(cfg.MaxHeight)
+	enc.SetVersion(cfg.Version)
+	enc.SetMinVersion(cfg.MinVersion)
+	enc.SetMask(cfg.Mask)
+	enc.SetBoost(cfg.BoostECL)
+	enc.SetGS1(cfg.GS1)
+	return enc
+}
+
+// charset returns the encoder charset of a validated config.
+func charset(cfg *Config) *encoder.Charset {
+	if cfg.Charset == "" {
+		return nil
+	}
+	cs, _ := encoder.LookupCharset(cfg.Charset)
+	return cs
 }
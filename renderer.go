@@ -1,19 +1,22 @@
 package qrgode
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/base64"
 	"fmt"
 	"image"
 	_ "image/jpeg"
 	"image/png"
+	"io"
+	"math"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/ahmedtahas/qr-gode/internal/colors"
 	"github.com/ahmedtahas/qr-gode/internal/encoder"
-	"github.com/ahmedtahas/qr-gode/internal/shapes"
 )
 
 // Logo size constraints (as fraction of QR size)
@@ -45,7 +48,8 @@ func (r *renderer) calculateLogoDimensions() (float64, float64, float64, error)
 	}
 
 	logo := r.config.Logo
-	qrSize := float64(r.config.Size)
+	_, height := r.pixelSize()
+	qrSize := float64(height) // The shorter side of rMQR symbols
 	var logoWidth, logoHeight float64
 
 	if logo.Width > 0 && logo.Height > 0 {
@@ -72,6 +76,9 @@ func (r *renderer) calculateLogoDimensions() (float64, float64, float64, error)
 		}
 
 		targetFraction := (logoMinSize + logoMaxSize) / 2
+		if logo.Size > 0 {
+			targetFraction = logo.Size
+		}
 		maxDimension := qrSize * targetFraction
 		aspectRatio := float64(imgW) / float64(imgH)
 
@@ -89,194 +96,267 @@ func (r *renderer) calculateLogoDimensions() (float64, float64, float64, error)
 		padding = logoHeight
 	}
 	padding *= 0.1
+	if logo.Padding > 0 {
+		padding = qrSize * logo.Padding
+	}
 
-	return logoWidth, logoHeight, padding, nil
+	// Shrunk by LogoShrink
+	scale := r.logoScale
+	return logoWidth * scale, logoHeight * scale, padding * scale, nil
 }
 
 // renderer converts a QR matrix to SVG output.
 type renderer struct {
-	config *Config
-	matrix *encoder.Matrix
+	config    *Config
+	matrix    *encoder.Matrix
+	layout    encoder.Layout // Block layout of the matrix data
+	symbol    *Symbol        // How the matrix was encoded
+	logoScale float64        // Logo size relative to its configured size
+	idPrefix  string         // Prefix for SVG ids, unique per symbol in one document
 }
 
 // newRenderer creates a renderer for the given matrix and config.
 func newRenderer(matrix *encoder.Matrix, config *Config) *renderer {
 	return &renderer{
-		config: config,
-		matrix: matrix,
+		config:    config,
+		matrix:    matrix,
+		logoScale: 1,
 	}
 }
 
 // renderSVG generates the SVG representation of the QR code.
 func (r *renderer) renderSVG() ([]byte, error) {
-	// Check if using custom images
-	if r.config.Images != nil && (r.config.Images.Module != "" || r.config.Images.Finder != "" || r.config.Images.Alignment != "") {
-		return r.renderWithImages()
+	var buf bytes.Buffer
+	if err := r.writeSVG(&buf); err != nil {
+		return nil, err
 	}
-	return r.renderWithShapes()
+	return buf.Bytes(), nil
 }
 
-// renderWithShapes renders QR code using vector shapes
-func (r *renderer) renderWithShapes() ([]byte, error) {
-	// Calculate logo exclusion zone
-	logoMinX, logoMinY, logoMaxX, logoMaxY, hasLogoZone, err := r.calculateExclusionZone()
+// logoSVG returns the SVG elements of the logo, or "" without one. It is
+// rendered before any output so a bad logo leaves nothing written.
+func (r *renderer) logoSVG() (string, error) {
+	if !r.hasLogo() {
+		return "", nil
+	}
+	return r.renderLogo()
+}
+
+// writeSVG streams the SVG representation of the QR code to out and
+// returns the first error writing it.
+func (r *renderer) writeSVG(out io.Writer) error {
+	w := bufio.NewWriter(out)
+
+	// Check if using custom images
+	var err error
+	if r.hasCustomImages() {
+		err = r.writeWithImages(w)
+	} else {
+		err = r.writeWithShapes(w)
+	}
 	if err != nil {
-		return nil, err
+		return err
 	}
+	return w.Flush()
+}
 
-	var buf bytes.Buffer
+// hasCustomImages returns true if any custom element image is configured
+func (r *renderer) hasCustomImages() bool {
+	images := r.config.Images
+	return images != nil && (images.Module != "" || images.Finder != "" || images.Alignment != "")
+}
+
+// fillLayer is a single filled path of the vector output.
+// The path data is in output pixel coordinates.
+type fillLayer struct {
+	id      string       // Identifier for SVG gradient defs
+	color   colors.Color // Fill source
+	path    string       // SVG path data
+	evenOdd bool         // Use the even-odd fill rule
+}
+
+// writeWithShapes renders QR code using vector shapes
+func (r *renderer) writeWithShapes(w *bufio.Writer) error {
+	layers, err := r.shapeLayers()
+	if err != nil {
+		return err
+	}
+	logoSVG, err := r.logoSVG()
+	if err != nil {
+		return err
+	}
 
 	// SVG header
-	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d">`,
-		r.config.Size, r.config.Size, r.config.Size, r.config.Size)
-	buf.WriteString("\n")
+	width, height := r.pixelSize()
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d">`,
+		width, height, width, height)
+	w.WriteString("\n")
 
 	// Defs section for gradients
-	if r.config.Modules.Color != nil {
-		defs := r.config.Modules.Color.SVGDefs("module-fill")
-		if defs != "" {
-			fmt.Fprintf(&buf, "<defs>%s</defs>\n", defs)
-		}
-	}
+	r.writeDefs(w, layers)
 
 	// Background
-	r.writeBackground(&buf)
+	r.writeBackground(w)
 
-	// Get module color/fill
-	moduleFill := "#000000"
-	if r.config.Modules.Color != nil {
-		moduleFill = r.config.Modules.Color.SVGFill("module-fill")
+	// Draw all layers
+	for _, layer := range layers {
+		r.writeLayer(w, layer)
 	}
 
-	// Get shape - using "square" as safe default if nil or unknown
-	shapeName := r.config.Modules.Shape
-	if shapeName == "" {
-		shapeName = "square"
-	}
-	shape := shapes.Get(shapeName)
-	if shape == nil {
-		shape = shapes.Get("square")
-	}
+	// Logo, if configured
+	w.WriteString(logoSVG)
 
-	// Draw all valid modules
-	r.drawModulesShapes(&buf, shape, moduleFill, hasLogoZone, logoMinX, logoMinY, logoMaxX, logoMaxY)
+	// Close SVG
+	w.WriteString("</svg>")
+	return nil
+}
 
-	// Render logo if configured
-	if r.hasLogo() {
-		logoSVG, err := r.renderLogo()
-		if err != nil {
-			return nil, err
-		}
-		buf.WriteString(logoSVG)
+// shapeLayers builds the filled paths that make up the vector rendering.
+// They are shared by every output format that draws shapes.
+func (r *renderer) shapeLayers() ([]fillLayer, error) {
+	// Calculate logo exclusion zone
+	logoMinX, logoMinY, logoMaxX, logoMaxY, hasLogoZone, err := r.calculateExclusionZone()
+	if err != nil {
+		return nil, err
+	}
+	inLogoZone := func(x, y int) bool {
+		return hasLogoZone && x >= logoMinX && x <= logoMaxX && y >= logoMinY && y <= logoMaxY
 	}
 
-	// Close SVG
-	buf.WriteString("</svg>")
-
-	return buf.Bytes(), nil
-}
+	// Get module color, black if unset
+	moduleColor := r.config.Modules.Color
+	if moduleColor == nil {
+		moduleColor = colors.NewSolid("#000000")
+	}
 
-func (r *renderer) drawModulesShapes(buf *bytes.Buffer, shape shapes.Shape, moduleFill string, hasLogoZone bool, logoMinX, logoMinY, logoMaxX, logoMaxY int) {
-	matrixSize := r.matrix.Size()
-	quietZone := r.config.QuietZone
-	totalModules := matrixSize + 2*quietZone
-	moduleSize := float64(r.config.Size) / float64(totalModules)
+	// Get shape - using "square" as safe default if nil or unknown
+	shape := resolveShape(r.config.Modules.Shape, 0)
 
-	// Render all dark modules as a single path for efficiency
-	fmt.Fprintf(buf, `<path fill="%s" d="`, moduleFill)
+	// Modules drawn one by one, grouped by style
+	var layers []fillLayer
+	for _, g := range r.moduleGroups(shape, moduleColor, inLogoZone) {
+		layers = append(layers, g.layers()...)
+	}
 
-	shapePath := shape.SVGPath()
-	for y := 0; y < matrixSize; y++ {
-		for x := 0; x < matrixSize; x++ {
-			// Skip modules in the logo zone
-			if hasLogoZone && x >= logoMinX && x <= logoMaxX && y >= logoMinY && y <= logoMaxY {
-				continue
-			}
+	// Whole-pattern shapes
+	if r.config.Finders.layered() {
+		layers = append(layers, r.finderLayers(moduleColor)...)
+	}
+	if r.config.Alignment.layered() {
+		layers = append(layers, r.alignmentLayers(moduleColor, inLogoZone)...)
+	}
 
-			if r.matrix.Get(x, y).Dark {
-				// Calculate position with quiet zone offset
-				px := float64(quietZone+x) * moduleSize
-				py := float64(quietZone+y) * moduleSize
+	return layers, nil
+}
 
-				// Transform and add shape path
-				transformed := transformPath(shapePath, px, py, moduleSize)
-				buf.WriteString(transformed)
-				buf.WriteString(" ")
-			}
+// writeDefs writes gradient definitions needed by the layers.
+func (r *renderer) writeDefs(w *bufio.Writer, layers []fillLayer) {
+	var defs strings.Builder
+	seen := make(map[string]bool)
+	for _, layer := range layers {
+		if seen[layer.id] {
+			continue
 		}
+		seen[layer.id] = true
+		defs.WriteString(layer.color.SVGDefs(r.idPrefix + layer.id))
+	}
+	if defs.Len() > 0 {
+		fmt.Fprintf(w, "<defs>%s</defs>\n", defs.String())
 	}
+}
 
-	buf.WriteString(`"/>`)
-	buf.WriteString("\n")
+// writeLayer writes a single layer as an SVG path element.
+func (r *renderer) writeLayer(w *bufio.Writer, layer fillLayer) {
+	fmt.Fprintf(w, `<path fill="%s"`, layer.color.SVGFill(r.idPrefix+layer.id))
+	if layer.evenOdd {
+		w.WriteString(` fill-rule="evenodd"`)
+	}
+	fmt.Fprintf(w, ` d="%s"/>`, layer.path)
+	w.WriteString("\n")
+}
+
+// moduleSize returns the size of a single module in output pixels.
+func (r *renderer) moduleSize() float64 {
+	totalModules := r.matrix.Width() + 2*r.config.QuietZone
+	return float64(r.config.Size) / float64(totalModules)
 }
 
-// renderWithImages renders QR code using custom PNG images
-func (r *renderer) renderWithImages() ([]byte, error) {
-	matrixSize := r.matrix.Size()
+// pixelSize returns the output width and height in pixels. Config.Size
+// is the width; rMQR symbols are less high than wide.
+func (r *renderer) pixelSize() (width, height int) {
+	quietZone := 2 * r.config.QuietZone
+	if r.matrix.Width() == r.matrix.Height() {
+		return r.config.Size, r.config.Size
+	}
+	height = int(math.Round(r.moduleSize() * float64(r.matrix.Height()+quietZone)))
+	return r.config.Size, height
+}
 
+// writeWithImages renders QR code using custom PNG images
+func (r *renderer) writeWithImages(w *bufio.Writer) error {
 	// Calculate logo exclusion zone
 	logoMinX, logoMinY, logoMaxX, logoMaxY, hasLogoZone, err := r.calculateExclusionZone()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var buf bytes.Buffer
-	r.writeSVGHeader(&buf)
-
-	// Background
-	r.writeBackground(&buf)
-
 	// Load images
 	moduleImg, finderImg, alignImg, err := r.loadCustomImages()
 	if err != nil {
-		return nil, err
+		return err
 	}
+	logoSVG, err := r.logoSVG()
+	if err != nil {
+		return err
+	}
+
+	r.writeSVGHeader(w)
+
+	// Background
+	r.writeBackground(w)
 
 	// Render finder patterns
 	if finderImg != "" {
-		r.renderFinderImages(&buf, finderImg, matrixSize)
+		r.renderFinderImages(w, finderImg)
 	}
 
 	// Render alignment patterns
 	if alignImg != "" {
-		r.renderAlignmentImages(&buf, alignImg, finderImg != "", matrixSize)
+		r.renderAlignmentImages(w, alignImg)
 	}
 
 	// Render custom image modules
 	// Skip modules in the logo zone or those covered by custom finders/alignments
-	r.renderImageModules(&buf, moduleImg, finderImg, alignImg, matrixSize, hasLogoZone, logoMinX, logoMinY, logoMaxX, logoMaxY)
+	r.renderImageModules(w, moduleImg, finderImg, alignImg, hasLogoZone, logoMinX, logoMinY, logoMaxX, logoMaxY)
 
-	// Render logo if configured
-	if r.hasLogo() {
-		logoSVG, err := r.renderLogo()
-		if err != nil {
-			return nil, err
-		}
-		buf.WriteString(logoSVG)
-	}
+	// Logo, if configured
+	w.WriteString(logoSVG)
 
-	buf.WriteString("</svg>")
-	return buf.Bytes(), nil
+	w.WriteString("</svg>")
+	return nil
 }
 
-func (r *renderer) writeSVGHeader(buf *bytes.Buffer) {
+func (r *renderer) writeSVGHeader(w *bufio.Writer) {
 	// SVG header with xlink namespace for images
-	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 %d %d" width="%d" height="%d">`,
-		r.config.Size, r.config.Size, r.config.Size, r.config.Size)
-	buf.WriteString("\n")
+	width, height := r.pixelSize()
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 %d %d" width="%d" height="%d">`,
+		width, height, width, height)
+	w.WriteString("\n")
 }
 
-func (r *renderer) writeBackground(buf *bytes.Buffer) {
+func (r *renderer) writeBackground(w *bufio.Writer) {
 	bgColor := "#FFFFFF"
 	if r.config.Background != nil {
 		bgColor = r.config.Background.SVGFill("")
 	}
-	fmt.Fprintf(buf, `<rect width="100%%" height="100%%" fill="%s"/>`, bgColor)
-	buf.WriteString("\n")
+	fmt.Fprintf(w, `<rect width="100%%" height="100%%" fill="%s"/>`, bgColor)
+	w.WriteString("\n")
 }
 
+// calculateExclusionZone returns the modules cleared for the logo, in
+// matrix coordinates. Overlay logos clear none.
 func (r *renderer) calculateExclusionZone() (minX, minY, maxX, maxY int, active bool, err error) {
-	if !r.hasLogo() {
+	if !r.hasLogo() || r.config.Logo.Overlay {
 		return 0, 0, 0, 0, false, nil
 	}
 
@@ -285,10 +365,8 @@ func (r *renderer) calculateExclusionZone() (minX, minY, maxX, maxY int, active
 		return 0, 0, 0, 0, false, fmt.Errorf("failed to calculate logo dimensions: %w", err)
 	}
 
-	matrixSize := r.matrix.Size()
 	quietZone := r.config.QuietZone
-	totalModules := matrixSize + 2*quietZone
-	moduleSize := float64(r.config.Size) / float64(totalModules)
+	moduleSize := r.moduleSize()
 
 	// Total logo area including padding
 	totalWidth := logoWidth + 2*padding
@@ -299,9 +377,8 @@ func (r *renderer) calculateExclusionZone() (minX, minY, maxX, maxY int, active
 	excludeHalfY := int(totalHeight/moduleSize/2) + 1
 
 	// Center of SVG in matrix coordinates
-	svgCenterModule := float64(totalModules) / 2
-	matrixCenterX := int(svgCenterModule) - quietZone
-	matrixCenterY := int(svgCenterModule) - quietZone
+	matrixCenterX := int(float64(r.matrix.Width()+2*quietZone)/2) - quietZone
+	matrixCenterY := int(float64(r.matrix.Height()+2*quietZone)/2) - quietZone
 
 	minX = matrixCenterX - excludeHalfX
 	minY = matrixCenterY - excludeHalfY
@@ -311,6 +388,33 @@ func (r *renderer) calculateExclusionZone() (minX, minY, maxX, maxY int, active
 	return minX, minY, maxX, maxY, true, nil
 }
 
+// overlayZone returns the modules an overlay logo covers, in matrix
+// coordinates: those the logo touches, and those under its padding
+// unless the background is transparent.
+func (r *renderer) overlayZone() (minX, minY, maxX, maxY int, err error) {
+	logoWidth, logoHeight, padding, err := r.calculateLogoDimensions()
+	if err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("failed to calculate logo dimensions: %w", err)
+	}
+	if r.config.Logo.Background == "transparent" {
+		padding = 0
+	}
+
+	width, height := r.pixelSize()
+	moduleSize := r.moduleSize()
+	quietZone := r.config.QuietZone
+
+	// Pixel edges of the logo to the modules they fall in
+	first := func(size, logo float64) int {
+		return int(math.Floor(((size-logo)/2-padding)/moduleSize)) - quietZone
+	}
+	last := func(size, logo float64) int {
+		return int(math.Ceil(((size+logo)/2+padding)/moduleSize)) - 1 - quietZone
+	}
+	return first(float64(width), logoWidth), first(float64(height), logoHeight),
+		last(float64(width), logoWidth), last(float64(height), logoHeight), nil
+}
+
 func (r *renderer) loadCustomImages() (modImg, findImg, alignImg string, err error) {
 	if r.config.Images.Module != "" {
 		modImg, err = loadImageAsDataURI(r.config.Images.Module)
@@ -333,69 +437,55 @@ func (r *renderer) loadCustomImages() (modImg, findImg, alignImg string, err err
 	return
 }
 
-func (r *renderer) renderFinderImages(buf *bytes.Buffer, finderImg string, matrixSize int) {
+func (r *renderer) renderFinderImages(w *bufio.Writer, finderImg string) {
 	quietZone := r.config.QuietZone
-	totalModules := matrixSize + 2*quietZone
-	moduleSize := float64(r.config.Size) / float64(totalModules)
+	moduleSize := r.moduleSize()
 	finderSize := 7 * moduleSize
 
-	// Top-left finder
-	px := float64(quietZone) * moduleSize
-	py := float64(quietZone) * moduleSize
-	fmt.Fprintf(buf, `<image x="%.2f" y="%.2f" width="%.2f" height="%.2f" href="%s"/>`,
-		px, py, finderSize, finderSize, finderImg)
-	buf.WriteString("\n")
-
-	// Top-right finder
-	px = float64(quietZone+matrixSize-7) * moduleSize
-	py = float64(quietZone) * moduleSize
-	fmt.Fprintf(buf, `<image x="%.2f" y="%.2f" width="%.2f" height="%.2f" href="%s" transform="scale(-1,1) translate(%.2f,0)"/>`,
-		px, py, finderSize, finderSize, finderImg, -(2*px + finderSize))
-	buf.WriteString("\n")
-
-	// Bottom-left finder
-	px = float64(quietZone) * moduleSize
-	py = float64(quietZone+matrixSize-7) * moduleSize
-	fmt.Fprintf(buf, `<image x="%.2f" y="%.2f" width="%.2f" height="%.2f" href="%s" transform="scale(1,-1) translate(0,%.2f)"/>`,
-		px, py, finderSize, finderSize, finderImg, -(2*py + finderSize))
-	buf.WriteString("\n")
+	// Finders away from the top-left corner are mirrored to face outward
+	for _, origin := range r.matrix.Finders() {
+		px := float64(quietZone+origin[0]) * moduleSize
+		py := float64(quietZone+origin[1]) * moduleSize
+		fmt.Fprintf(w, `<image x="%.2f" y="%.2f" width="%.2f" height="%.2f" href="%s"`,
+			px, py, finderSize, finderSize, finderImg)
+		switch {
+		case origin[0] > 0 && origin[1] > 0:
+			fmt.Fprintf(w, ` transform="scale(-1,-1) translate(%.2f,%.2f)"`, -(2*px + finderSize), -(2*py + finderSize))
+		case origin[0] > 0:
+			fmt.Fprintf(w, ` transform="scale(-1,1) translate(%.2f,0)"`, -(2*px + finderSize))
+		case origin[1] > 0:
+			fmt.Fprintf(w, ` transform="scale(1,-1) translate(0,%.2f)"`, -(2*py + finderSize))
+		}
+		w.WriteString("/>\n")
+	}
 }
 
-func (r *renderer) renderAlignmentImages(buf *bytes.Buffer, alignImg string, hasFinderImg bool, matrixSize int) {
+func (r *renderer) renderAlignmentImages(w *bufio.Writer, alignImg string) {
 	quietZone := r.config.QuietZone
-	totalModules := matrixSize + 2*quietZone
-	moduleSize := float64(r.config.Size) / float64(totalModules)
+	moduleSize := r.moduleSize()
 	alignSize := 5 * moduleSize
-	alignPositions := getAlignmentPositions(matrixSize)
 
-	for _, ay := range alignPositions {
-		for _, ax := range alignPositions {
-			// Skip if overlapping with finder patterns
-			if isFinderArea(ax, ay, matrixSize) {
-				continue
-			}
-			// Alignment pattern is centered, so offset by 2
-			px := float64(quietZone+ax-2) * moduleSize
-			py := float64(quietZone+ay-2) * moduleSize
-			fmt.Fprintf(buf, `<image x="%.2f" y="%.2f" width="%.2f" height="%.2f" href="%s"/>`,
-				px, py, alignSize, alignSize, alignImg)
-			buf.WriteString("\n")
-		}
+	for _, pos := range r.matrix.Alignments() {
+		// Alignment pattern is centered, so offset by 2
+		px := float64(quietZone+pos[0]-2) * moduleSize
+		py := float64(quietZone+pos[1]-2) * moduleSize
+		fmt.Fprintf(w, `<image x="%.2f" y="%.2f" width="%.2f" height="%.2f" href="%s"/>`,
+			px, py, alignSize, alignSize, alignImg)
+		w.WriteString("\n")
 	}
 }
 
-func (r *renderer) renderImageModules(buf *bytes.Buffer, moduleImg, finderImg, alignImg string, matrixSize int, hasLogoZone bool, logoMinX, logoMinY, logoMaxX, logoMaxY int) {
+func (r *renderer) renderImageModules(w *bufio.Writer, moduleImg, finderImg, alignImg string, hasLogoZone bool, logoMinX, logoMinY, logoMaxX, logoMaxY int) {
 	if moduleImg == "" {
 		return
 	}
 
 	quietZone := r.config.QuietZone
-	totalModules := matrixSize + 2*quietZone
-	moduleSize := float64(r.config.Size) / float64(totalModules)
+	moduleSize := r.moduleSize()
 
 	// Render regular modules (skip finder and alignment areas if custom images provided)
-	for y := 0; y < matrixSize; y++ {
-		for x := 0; x < matrixSize; x++ {
+	for y := 0; y < r.matrix.Height(); y++ {
+		for x := 0; x < r.matrix.Width(); x++ {
 			if r.shouldSkipModule(x, y, moduleImg, finderImg, alignImg, hasLogoZone, logoMinX, logoMinY, logoMaxX, logoMaxY) {
 				continue
 			}
@@ -403,9 +493,9 @@ func (r *renderer) renderImageModules(buf *bytes.Buffer, moduleImg, finderImg, a
 			px := float64(quietZone+x) * moduleSize
 			py := float64(quietZone+y) * moduleSize
 
-			fmt.Fprintf(buf, `<image x="%.2f" y="%.2f" width="%.2f" height="%.2f" href="%s"/>`,
+			fmt.Fprintf(w, `<image x="%.2f" y="%.2f" width="%.2f" height="%.2f" href="%s"/>`,
 				px, py, moduleSize, moduleSize, moduleImg)
-			buf.WriteString("\n")
+			w.WriteString("\n")
 		}
 	}
 }
@@ -422,12 +512,12 @@ func (r *renderer) shouldSkipModule(x, y int, moduleImg, finderImg, alignImg str
 	}
 
 	// Skip finder pattern modules if we rendered them as unified images
-	if finderImg != "" && mod.Type == encoder.ModuleFinder {
+	if finderImg != "" && mod.Type == encoder.ModuleFinder && r.matrix.InFinder(x, y) {
 		return true
 	}
 
 	// Skip alignment pattern modules if we rendered them as unified images
-	if alignImg != "" && mod.Type == encoder.ModuleAlignment {
+	if alignImg != "" && mod.Type == encoder.ModuleAlignment && r.matrix.InAlignment(x, y) {
 		return true
 	}
 
@@ -439,53 +529,6 @@ func (r *renderer) shouldSkipModule(x, y int, moduleImg, finderImg, alignImg str
 	return false
 }
 
-// getAlignmentPositions returns center positions of alignment patterns
-func getAlignmentPositions(matrixSize int) []int {
-	version := (matrixSize - 17) / 4
-	if version < 2 {
-		return nil
-	}
-
-	count := version/7 + 2
-	first := 6
-	last := matrixSize - 7
-
-	if count == 2 {
-		return []int{first, last}
-	}
-
-	step := (last - first) / (count - 1)
-	if step%2 != 0 {
-		step++
-	}
-
-	positions := make([]int, count)
-	positions[0] = first
-	positions[count-1] = last
-	for i := count - 2; i >= 1; i-- {
-		positions[i] = positions[i+1] - step
-	}
-
-	return positions
-}
-
-// isFinderArea checks if position overlaps with finder patterns
-func isFinderArea(x, y, size int) bool {
-	// Top-left finder (0-6, 0-6)
-	if x <= 8 && y <= 8 {
-		return true
-	}
-	// Top-right finder (size-7 to size-1, 0-6)
-	if x >= size-9 && y <= 8 {
-		return true
-	}
-	// Bottom-left finder (0-6, size-7 to size-1)
-	if x <= 8 && y >= size-9 {
-		return true
-	}
-	return false
-}
-
 // getLogoDimensions reads image dimensions from file
 // For SVG files, returns 1:1 aspect ratio
 func getLogoDimensions(path string) (int, int, error) {
@@ -527,11 +570,11 @@ func (r *renderer) renderLogo() (string, error) {
 		return "", err
 	}
 
-	qrSize := float64(r.config.Size)
+	width, height := r.pixelSize()
 
 	// Center position
-	logoX := (qrSize - logoWidth) / 2
-	logoY := (qrSize - logoHeight) / 2
+	logoX := (float64(width) - logoWidth) / 2
+	logoY := (float64(height) - logoHeight) / 2
 
 	var buf strings.Builder
 
@@ -769,8 +812,3 @@ func splitNumbers(s string) []float64 {
 	}
 	return nums
 }
-
-// RenderPNG generates a PNG by rasterizing the SVG.
-func (r *renderer) RenderPNG() ([]byte, error) {
-	return nil, fmt.Errorf("PNG rendering not supported - use SVG output")
-}
@@ -0,0 +1,117 @@
+package qrgode
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ahmedtahas/qr-gode/internal/colors"
+)
+
+// writeSplitPNG writes an image that is red on the left and blue on the right.
+func writeSplitPNG(t *testing.T, path string) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			c := color.RGBA{255, 0, 0, 255}
+			if x >= 20 {
+				c = color.RGBA{0, 0, 255, 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImageColorsSVG(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "split.png")
+	writeSplitPNG(t, path)
+
+	svg, err := New("test").ImageColors(path, 0).SVGString()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{`fill="#ff0000"`, `fill="#0000ff"`} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("expected SVG to contain %s", want)
+		}
+	}
+	if strings.Contains(svg, "<defs>") {
+		t.Error("image colors should not need SVG defs")
+	}
+}
+
+func TestImageColorsPNG(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "split.png")
+	writeSplitPNG(t, path)
+
+	// Version 1 with quiet zone 4 is 29 modules, 10px each
+	img, err := New("test").Size(290).ImageColors(path, 0.5).Image()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Top-left and top-right finder rings, darkened by half
+	if got := pixelAt(img, 45, 45); got != (color.RGBA{128, 0, 0, 255}) {
+		t.Errorf("expected dark red on the left, got %v", got)
+	}
+	if got := pixelAt(img, 245, 45); got != (color.RGBA{0, 0, 128, 255}) {
+		t.Errorf("expected dark blue on the right, got %v", got)
+	}
+}
+
+func TestImageColorsFinderLayers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "split.png")
+	writeSplitPNG(t, path)
+
+	svg, err := New("test").ImageColors(path, 0).FinderShape(ShapeCircle).SVGString()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// One outer ring fill per side of the image
+	if n := strings.Count(svg, `fill-rule="evenodd"`); n != 2 {
+		t.Errorf("expected 2 finder ring fills, got %d", n)
+	}
+}
+
+func TestImageColorsMissingFile(t *testing.T) {
+	_, err := New("test").ImageColors("missing.png", 0).SVG()
+	if err == nil {
+		t.Error("expected error for missing image")
+	}
+}
+
+func TestImageColorAt(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 30, 10))
+	for x := 0; x < 30; x++ {
+		for y := 0; y < 10; y++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(x * 8), 200, 100, 255})
+		}
+	}
+
+	// A wide image is center-cropped: 0.05 is the center of column 10
+	c := colors.NewImage(img, 0)
+	if got := c.ColorAt(0.05, 0.05); got != "#50c864" {
+		t.Errorf("expected cropped left column #50c864, got %s", got)
+	}
+	if got := colors.NewImage(img, 1).ColorAt(0.5, 0.5); got != "#000000" {
+		t.Errorf("expected full darkening to give black, got %s", got)
+	}
+	if !colors.IsPerModule(c) || colors.IsPerModule(colors.NewSolid("#000")) {
+		t.Error("expected only image colors to be per-module")
+	}
+}
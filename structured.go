@@ -0,0 +1,138 @@
+package qrgode
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/ahmedtahas/qr-gode/internal/encoder"
+)
+
+// GenerateStructuredAppend splits data that is too long for one QR code
+// across up to 16 linked symbols, which Structured Append aware scanners
+// join back together. Each symbol is an SVG with the same style.
+// Data that fits in one symbol gives a single ordinary QR code.
+// If cfg is nil, DefaultConfig() is used.
+func GenerateStructuredAppend(data string, cfg *Config) ([][]byte, error) {
+	renderers, err := prepareSequence(data, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return renderSequence(renderers)
+}
+
+// GenerateStructuredAppendGrid is GenerateStructuredAppend with the
+// symbols laid out in order in one SVG, columns symbols per row.
+// Columns of 0 or less makes the grid as square as possible.
+func GenerateStructuredAppendGrid(data string, cfg *Config, columns int) ([]byte, error) {
+	renderers, err := prepareSequence(data, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return renderGrid(renderers, columns)
+}
+
+// prepareSequence validates the config, splits and encodes data and
+// returns a renderer per symbol.
+func prepareSequence(data string, cfg *Config) ([]*renderer, error) {
+	if data == "" {
+		return nil, &ValidationError{Field: "Data", Message: "cannot be empty"}
+	}
+
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	if errs := ValidateConfig(cfg); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	return sequenceRenderers(data, cfg)
+}
+
+// sequenceRenderers encodes data over as few symbols as it needs with a
+// validated config.
+func sequenceRenderers(data string, cfg *Config) ([]*renderer, error) {
+	if cfg.Symbol != SymbolQR {
+		return nil, &ValidationError{Field: "Symbol", Message: "Structured Append needs QR Code symbols"}
+	}
+	if cfg.GS1 {
+		return nil, &ValidationError{Field: "GS1", Message: "GS1 data cannot be split with Structured Append"}
+	}
+	ecl := encoder.ErrorCorrectionLevel(cfg.ErrorCorrection)
+	maxVersion := encoder.Version(40)
+	if cfg.Version > 0 {
+		maxVersion = encoder.Version(cfg.Version)
+	}
+	parts, err := encoder.SplitStructuredAppend(data, ecl, charset(cfg), maxVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	parity := encoder.Parity(data, charset(cfg))
+	renderers := make([]*renderer, len(parts))
+	for i, part := range parts {
+		var sa *encoder.StructuredAppend
+		if len(parts) > 1 {
+			sa = &encoder.StructuredAppend{
+				Index:  i,
+				Total:  len(parts),
+				Parity: parity,
+			}
+		}
+		r, err := encodeRenderer(part, cfg, sa)
+		if err != nil {
+			return nil, err
+		}
+		renderers[i] = r
+	}
+	return renderers, nil
+}
+
+// renderSequence renders each symbol as its own SVG document.
+func renderSequence(renderers []*renderer) ([][]byte, error) {
+	out := make([][]byte, len(renderers))
+	for i, r := range renderers {
+		svg, err := r.renderSVG()
+		if err != nil {
+			return nil, err
+		}
+		out[i] = svg
+	}
+	return out, nil
+}
+
+// renderGrid renders the symbols as nested SVGs in rows of columns.
+func renderGrid(renderers []*renderer, columns int) ([]byte, error) {
+	if columns <= 0 {
+		columns = int(math.Ceil(math.Sqrt(float64(len(renderers)))))
+	}
+	columns = min(columns, len(renderers))
+	rows := (len(renderers) + columns - 1) / columns
+
+	size := renderers[0].config.Size
+	width, height := columns*size, rows*size
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d">`,
+		width, height, width, height)
+	buf.WriteString("\n")
+
+	for i, r := range renderers {
+		// Keep gradient ids apart between symbols
+		r.idPrefix = fmt.Sprintf("qr%d-", i+1)
+		svg, err := r.renderSVG()
+		if err != nil {
+			return nil, err
+		}
+
+		// Position the symbol's own <svg> element in its cell
+		x, y := (i%columns)*size, (i/columns)*size
+		fmt.Fprintf(&buf, `<svg x="%d" y="%d" `, x, y)
+		buf.Write(bytes.TrimPrefix(svg, []byte("<svg ")))
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString("</svg>")
+	return buf.Bytes(), nil
+}
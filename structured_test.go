@@ -0,0 +1,108 @@
+package qrgode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ahmedtahas/qr-gode/internal/encoder"
+)
+
+// longData needs two version 40-L symbols
+var longData = strings.Repeat("config=value;", 400)
+
+func TestStructuredAppendSingle(t *testing.T) {
+	symbols, err := GenerateStructuredAppend("https://example.com", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	single, _ := Generate("https://example.com", nil)
+	if len(symbols) != 1 || !bytes.Equal(symbols[0], single) {
+		t.Error("expected short data to give one ordinary symbol")
+	}
+}
+
+func TestStructuredAppendSequence(t *testing.T) {
+	if _, err := Generate(longData, &Config{ErrorCorrection: LevelL, Size: 256}); err == nil {
+		t.Fatal("expected data to be too long for one symbol")
+	}
+
+	symbols, err := New(longData).ErrorCorrection(LevelL).StructuredAppend()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(symbols) != 2 {
+		t.Fatalf("expected 2 symbols, got %d", len(symbols))
+	}
+	if bytes.Equal(symbols[0], symbols[1]) {
+		t.Error("expected symbols to differ")
+	}
+}
+
+func TestStructuredAppendGrid(t *testing.T) {
+	svg, err := New(longData).
+		ErrorCorrection(LevelL).
+		Size(300).
+		LinearGradient(45, "#ff0000", "#0000ff").
+		StructuredAppendGrid(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := string(svg)
+
+	if !strings.HasPrefix(s, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 600 300"`) {
+		t.Errorf("expected a 2x1 grid, got %.100s", s)
+	}
+	for _, want := range []string{`<svg x="0" y="0"`, `<svg x="300" y="0"`, `id="qr1-module-fill"`, `url(#qr2-module-fill)`} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected grid to contain %s", want)
+		}
+	}
+
+	// One column stacks the symbols
+	svg, _ = GenerateStructuredAppendGrid(longData, &Config{ErrorCorrection: LevelL, Size: 300}, 1)
+	if !strings.Contains(string(svg), `viewBox="0 0 300 600"`) {
+		t.Error("expected a 1x2 grid")
+	}
+}
+
+func TestStructuredAppendCharsetParity(t *testing.T) {
+	// 3505 bytes in Latin-1 need two version 40-L symbols
+	data := strings.Repeat("café;", 701)
+	cfg := &Config{ErrorCorrection: LevelL, Size: 256, Charset: CharsetLatin1}
+	renderers, err := prepareSequence(data, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(renderers) != 2 {
+		t.Fatalf("expected 2 symbols, got %d", len(renderers))
+	}
+
+	// The parity is over the Latin-1 bytes that were encoded
+	var parity byte
+	for _, c := range []byte(strings.ReplaceAll(data, "é", "\xe9")) {
+		parity ^= c
+	}
+	parts, _ := encoder.SplitStructuredAppend(data, encoder.LevelL, charset(cfg), 40)
+	for i, part := range parts {
+		want, err := encodeRenderer(part, cfg, &encoder.StructuredAppend{Index: i, Total: len(parts), Parity: parity})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := renderers[i].matrix
+		for y := 0; y < got.Height(); y++ {
+			for x := 0; x < got.Width(); x++ {
+				if got.Get(x, y) != want.matrix.Get(x, y) {
+					t.Fatalf("symbol %d does not carry parity %#x", i, parity)
+				}
+			}
+		}
+	}
+}
+
+func TestStructuredAppendTooLong(t *testing.T) {
+	_, err := New(strings.Repeat("x", 17*2953)).ErrorCorrection(LevelL).StructuredAppend()
+	if err == nil {
+		t.Error("expected error for data beyond 16 symbols")
+	}
+}
@@ -0,0 +1,156 @@
+package qrgode
+
+import (
+	"fmt"
+
+	"github.com/ahmedtahas/qr-gode/internal/encoder"
+)
+
+// Symbol describes how data was encoded: the version and mask chosen
+// for it, the error correction level used, how much of the capacity the
+// data fills and the modules of the symbol.
+type Symbol struct {
+	Type              SymbolType
+	Version           int                  // 1-40 for QR Code, 1-4 for Micro QR (M1-M4), 1-32 for rMQR
+	Mask              int                  // 0-7, or 0-3 for Micro QR; rMQR always uses 4
+	ErrorCorrection   ErrorCorrectionLevel // Raised above the configured level by BoostECL or for a logo
+	Mode              Mode                 // Mode of the data segments, ModeMixed if they differ
+	Segments          []Segment            // Segments in encoding order, headers included
+	Size              int                  // Modules per row, without quiet zone
+	Height            int                  // Modules per column; equal to Size except for rMQR
+	DataCapacityBytes int                  // Data codewords; the last is 4 bits long for M1 and M3
+	UsedBits          int                  // Bits taken by the segments, without terminator and padding
+
+	matrix *encoder.Matrix
+}
+
+// Mode is the encoding mode of a segment.
+type Mode int
+
+const (
+	ModeNumeric          Mode = iota // 0-9
+	ModeAlphanumeric                 // 0-9, A-Z, space and $%*+-./:
+	ModeByte                         // Bytes in the configured charset, UTF-8 by default
+	ModeKanji                        // Shift JIS double-byte characters
+	ModeECI                          // Character set header
+	ModeStructuredAppend             // Sequence header
+	ModeFNC1First                    // GS1 header
+	ModeFNC1Second                   // Industry application header
+	ModeMixed                        // Several data modes, for Symbol.Mode only
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeNumeric:
+		return "numeric"
+	case ModeAlphanumeric:
+		return "alphanumeric"
+	case ModeByte:
+		return "byte"
+	case ModeKanji:
+		return "kanji"
+	case ModeECI:
+		return "ECI"
+	case ModeStructuredAppend:
+		return "structured append"
+	case ModeFNC1First:
+		return "FNC1 first position"
+	case ModeFNC1Second:
+		return "FNC1 second position"
+	case ModeMixed:
+		return "mixed"
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// Segment is a run of data encoded in one mode. Alphanumeric segments
+// of GS1 data hold the GS separator as % and % as %%.
+type Segment struct {
+	Mode Mode
+	Data string // Encoded characters; bytes in the charset for ModeByte, empty for headers
+	ECI  int    // ECI assignment number, for ModeECI
+}
+
+// ModuleType identifies the function of a module.
+type ModuleType int
+
+const (
+	ModuleData            ModuleType = iota // Data and error correction
+	ModuleFinder                            // Finder pattern, and rMQR corner patterns
+	ModuleFinderSeparator                   // Light border around a finder pattern
+	ModuleAlignment                         // Alignment pattern
+	ModuleTiming                            // Timing pattern
+	ModuleFormatInfo                        // Format information
+	ModuleVersionInfo                       // Version information
+	ModuleDarkModule                        // The single always-dark module of QR Codes
+	ModuleQuietZone                         // Outside the symbol
+)
+
+// Encode encodes data at the given error correction level with the
+// default config and returns the symbol without rendering it.
+func Encode(data string, ecl ErrorCorrectionLevel) (*Symbol, error) {
+	cfg := DefaultConfig()
+	cfg.ErrorCorrection = ecl
+	r, err := prepareRenderer(data, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return r.symbol, nil
+}
+
+// newSymbol describes the symbol enc encoded as matrix.
+func newSymbol(enc *encoder.Encoder, matrix *encoder.Matrix, cfg *Config) *Symbol {
+	s := &Symbol{
+		Type:              cfg.Symbol,
+		Version:           enc.Version(),
+		Mask:              enc.Mask(),
+		ErrorCorrection:   ErrorCorrectionLevel(enc.ErrorCorrection()),
+		Size:              matrix.Width(),
+		Height:            matrix.Height(),
+		DataCapacityBytes: enc.Layout().ECC.DataCapacity(),
+		UsedBits:          enc.UsedBits(),
+		matrix:            matrix,
+	}
+
+	for _, seg := range enc.Segments() {
+		s.Segments = append(s.Segments, Segment{Mode: Mode(seg.Mode), Data: seg.Data, ECI: seg.ECI})
+	}
+	s.Mode = dataMode(s.Segments)
+	return s
+}
+
+// dataMode returns the mode shared by the data segments, or ModeMixed.
+func dataMode(segs []Segment) Mode {
+	mode := ModeMixed
+	for _, seg := range segs {
+		switch {
+		case seg.Mode >= ModeECI: // Headers
+		case mode == ModeMixed:
+			mode = seg.Mode
+		case mode != seg.Mode:
+			return ModeMixed
+		}
+	}
+	return mode
+}
+
+// inside reports whether (x, y) lies in the symbol.
+func (s *Symbol) inside(x, y int) bool {
+	return x >= 0 && x < s.Size && y >= 0 && y < s.Height
+}
+
+// IsDark reports whether the module at column x and row y, counted from
+// the top-left corner of the symbol, is dark. Modules outside the symbol
+// are light.
+func (s *Symbol) IsDark(x, y int) bool {
+	return s.inside(x, y) && s.matrix.Get(x, y).Dark
+}
+
+// TypeAt returns the type of the module at column x and row y, or
+// ModuleQuietZone outside the symbol.
+func (s *Symbol) TypeAt(x, y int) ModuleType {
+	if !s.inside(x, y) {
+		return ModuleQuietZone
+	}
+	return ModuleType(s.matrix.Get(x, y).Type)
+}
@@ -0,0 +1,103 @@
+package qrgode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	symbol, err := Encode("HELLO WORLD", LevelM)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if symbol.Type != SymbolQR || symbol.Version != 1 || symbol.ErrorCorrection != LevelM {
+		t.Errorf("expected QR version 1-M, got %+v", symbol)
+	}
+	if symbol.Size != 21 || symbol.Height != 21 {
+		t.Errorf("expected 21x21 modules, got %dx%d", symbol.Size, symbol.Height)
+	}
+	if symbol.Mode != ModeAlphanumeric || len(symbol.Segments) != 1 {
+		t.Errorf("expected one alphanumeric segment, got %v %+v", symbol.Mode, symbol.Segments)
+	}
+	if symbol.DataCapacityBytes != 16 {
+		t.Errorf("expected 16 data codewords, got %d", symbol.DataCapacityBytes)
+	}
+	// Mode indicator, count and 5 pairs and a single character
+	if symbol.UsedBits != 4+9+61 {
+		t.Errorf("expected %d used bits, got %d", 4+9+61, symbol.UsedBits)
+	}
+}
+
+func TestEncodeEmpty(t *testing.T) {
+	if _, err := Encode("", LevelM); err == nil {
+		t.Error("expected error for empty data")
+	}
+}
+
+func TestSymbolModules(t *testing.T) {
+	symbol, err := Encode("https://example.com", LevelQ)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		x, y int
+		dark bool
+		typ  ModuleType
+	}{
+		{0, 0, true, ModuleFinder},
+		{1, 1, false, ModuleFinder},
+		{7, 0, false, ModuleFinderSeparator},
+		{8, 6, true, ModuleTiming},
+		{8, symbol.Size - 8, true, ModuleDarkModule},
+		{-1, 0, false, ModuleQuietZone},
+		{0, symbol.Size, false, ModuleQuietZone},
+	}
+	for _, tt := range tests {
+		if got := symbol.IsDark(tt.x, tt.y); got != tt.dark {
+			t.Errorf("IsDark(%d, %d) = %v, expected %v", tt.x, tt.y, got, tt.dark)
+		}
+		if got := symbol.TypeAt(tt.x, tt.y); got != tt.typ {
+			t.Errorf("TypeAt(%d, %d) = %d, expected %d", tt.x, tt.y, got, tt.typ)
+		}
+	}
+}
+
+func TestSymbolMixedMode(t *testing.T) {
+	symbol, err := Encode(strings.Repeat("1", 40)+"hello", LevelL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(symbol.Segments) != 2 || symbol.Mode != ModeMixed {
+		t.Fatalf("expected mixed numeric and byte segments, got %v %+v", symbol.Mode, symbol.Segments)
+	}
+	if symbol.Segments[0].Mode != ModeNumeric || symbol.Segments[1].Data != "hello" {
+		t.Errorf("unexpected segments %+v", symbol.Segments)
+	}
+}
+
+func TestSymbolHeaders(t *testing.T) {
+	symbol, err := New("Grüße").Charset("ISO-8859-1").Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if symbol.Mode != ModeByte || symbol.Segments[0].Mode != ModeECI || symbol.Segments[0].ECI != 3 {
+		t.Errorf("expected ECI 3 header before byte data, got %v %+v", symbol.Mode, symbol.Segments)
+	}
+}
+
+func TestSymbolRMQRSize(t *testing.T) {
+	symbol, err := New("12345").Symbol(SymbolRMQR).ErrorCorrection(LevelM).Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if symbol.Height >= symbol.Size || !symbol.IsDark(0, 0) || symbol.TypeAt(symbol.Size, 0) != ModuleQuietZone {
+		t.Errorf("unexpected rMQR symbol %dx%d", symbol.Size, symbol.Height)
+	}
+}
+
+func TestModeString(t *testing.T) {
+	if ModeAlphanumeric.String() != "alphanumeric" || Mode(42).String() != "Mode(42)" {
+		t.Errorf("unexpected mode names %q, %q", ModeAlphanumeric, Mode(42))
+	}
+}
@@ -0,0 +1,195 @@
+package qrgode
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+
+	"github.com/ahmedtahas/qr-gode/internal/colors"
+)
+
+// Text output draws the bare module grid, one character cell per module
+// or per pair of modules. Shapes, pattern styles and the logo are left
+// out; the error correction raised for a logo still applies.
+
+// renderText generates the QR code as text in one of the text formats.
+func (r *renderer) renderText(format Format) (string, error) {
+	var buf bytes.Buffer
+	if err := r.writeText(&buf, format); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// writeText streams the QR code to w as text, quiet zone included.
+func (r *renderer) writeText(out io.Writer, format Format) error {
+	w := bufio.NewWriter(out)
+	switch format {
+	case FormatASCII:
+		r.writeASCII(w)
+	case FormatUTF8:
+		r.writeHalfBlocks(w)
+	case FormatTerminal:
+		if err := r.writeANSI(w); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("format %d is not a text format", format)
+	}
+	return w.Flush()
+}
+
+// darkAt reports whether the module at (x, y) is dark, with coordinates
+// that include the quiet zone.
+func (r *renderer) darkAt(x, y int) bool {
+	q := r.config.QuietZone
+	return r.symbol.IsDark(x-q, y-q)
+}
+
+// textSize returns the number of modules per row and column, quiet zone
+// included.
+func (r *renderer) textSize() (width, height int) {
+	q := 2 * r.config.QuietZone
+	return r.matrix.Width() + q, r.matrix.Height() + q
+}
+
+// writeASCII draws each module as two characters, "##" when dark, so
+// modules come out roughly square in a monospaced font.
+func (r *renderer) writeASCII(w *bufio.Writer) {
+	width, height := r.textSize()
+	for y := range height {
+		for x := range width {
+			if r.darkAt(x, y) {
+				w.WriteString("##")
+			} else {
+				w.WriteString("  ")
+			}
+		}
+		w.WriteByte('\n')
+	}
+}
+
+// halfBlocks indexes the block characters by top and bottom module,
+// each dark or not.
+var halfBlocks = [2][2]string{
+	{" ", "▄"},
+	{"▀", "█"},
+}
+
+// writeHalfBlocks draws two rows of modules per line with the Unicode
+// half block characters, dark modules as ink.
+func (r *renderer) writeHalfBlocks(w *bufio.Writer) {
+	width, height := r.textSize()
+	for y := 0; y < height; y += 2 {
+		for x := range width {
+			w.WriteString(halfBlocks[b2i(r.darkAt(x, y))][b2i(r.darkAt(x, y+1))])
+		}
+		w.WriteByte('\n')
+	}
+}
+
+// writeANSI draws half blocks with 24-bit ANSI colors for the modules
+// and background, so the code reads on dark and light terminals alike.
+// Gradients and image colors are sampled at each module; transparent
+// colors leave the terminal background.
+func (r *renderer) writeANSI(w *bufio.Writer) error {
+	width, height := r.textSize()
+	moduleColor := r.config.Modules.Color
+	if moduleColor == nil {
+		moduleColor = colors.NewSolid("#000000")
+	}
+	bg := r.config.Background
+	if bg == nil {
+		bg = colors.NewSolid("#FFFFFF")
+	}
+
+	// Colors of modules past the last row are the background's
+	colorAt := func(x, y int) (color.RGBA, error) {
+		c := bg
+		if r.darkAt(x, y) {
+			c = moduleColor
+		}
+		hex := c.ColorAt((float64(x)+0.5)/float64(width), (float64(y)+0.5)/float64(height))
+		rgba, err := colors.ToRGBA(hex)
+		if err != nil {
+			if c == bg {
+				return rgba, fmt.Errorf("invalid background color: %w", err)
+			}
+			return rgba, fmt.Errorf("invalid module color: %w", err)
+		}
+		return rgba, nil
+	}
+
+	for y := 0; y < height; y += 2 {
+		var t ansiState
+		for x := range width {
+			top, err := colorAt(x, y)
+			if err != nil {
+				return err
+			}
+			bottom, err := colorAt(x, y+1)
+			if err != nil {
+				return err
+			}
+			t.cell(w, top, bottom)
+		}
+		w.WriteString("\x1b[0m\n")
+	}
+	return nil
+}
+
+// ansiState tracks the colors last set on a line to skip repeated
+// escape sequences.
+type ansiState struct {
+	fg, bg   color.RGBA
+	set      bool
+	fgActive bool
+}
+
+// cell writes one character cell showing top over bottom. Transparent
+// colors are left to the terminal background.
+func (s *ansiState) cell(w *bufio.Writer, top, bottom color.RGBA) {
+	switch {
+	case top.A == 0 && bottom.A == 0:
+		s.colors(w, color.RGBA{}, color.RGBA{})
+		w.WriteString(" ")
+	case top == bottom:
+		s.colors(w, color.RGBA{}, top)
+		w.WriteString(" ")
+	case top.A == 0:
+		s.colors(w, bottom, color.RGBA{})
+		w.WriteString("▄")
+	default:
+		s.colors(w, top, bottom)
+		w.WriteString("▀")
+	}
+}
+
+// colors sets the foreground and background, where a transparent color
+// selects the terminal default. The foreground of a space is irrelevant
+// and kept.
+func (s *ansiState) colors(w *bufio.Writer, fg, bg color.RGBA) {
+	if !s.set || bg != s.bg {
+		if bg.A == 0 {
+			w.WriteString("\x1b[49m")
+		} else {
+			fmt.Fprintf(w, "\x1b[48;2;%d;%d;%dm", bg.R, bg.G, bg.B)
+		}
+		s.bg = bg
+	}
+	if fg.A != 0 && (!s.set || !s.fgActive || fg != s.fg) {
+		fmt.Fprintf(w, "\x1b[38;2;%d;%d;%dm", fg.R, fg.G, fg.B)
+		s.fg = fg
+		s.fgActive = true
+	}
+	s.set = true
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,119 @@
+package qrgode
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestASCIIOutput(t *testing.T) {
+	out, err := New("test").ErrorCorrection(LevelL).QuietZone(2).ASCII()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Version 1 is 21 modules, plus two modules of quiet zone on each side
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 25 {
+		t.Fatalf("expected 25 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if len(line) != 50 {
+			t.Errorf("line %d: expected 50 characters, got %d", i, len(line))
+		}
+	}
+	if strings.TrimSpace(lines[0]) != "" || strings.TrimSpace(lines[1]) != "" {
+		t.Error("expected blank quiet zone rows")
+	}
+	// Top row of the top-left finder pattern
+	if !strings.HasPrefix(lines[2], "    ##############  ") {
+		t.Errorf("expected finder pattern, got %q", lines[2])
+	}
+}
+
+func TestUTF8Output(t *testing.T) {
+	out, err := New("test").ErrorCorrection(LevelL).QuietZone(2).UTF8()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Two rows per line: 25 rows round up to 13 lines
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 13 {
+		t.Fatalf("expected 13 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if n := len([]rune(line)); n != 25 {
+			t.Errorf("line %d: expected 25 cells, got %d", i, n)
+		}
+	}
+	// Top two rows of the top-left finder pattern
+	if !strings.HasPrefix(lines[1], "  █▀▀▀▀▀█ ") {
+		t.Errorf("expected finder pattern, got %q", lines[1])
+	}
+}
+
+func TestTerminalOutput(t *testing.T) {
+	out, err := New("test").Foreground("#aa0000").Background("#ffffee").Terminal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"\x1b[38;2;170;0;0m", "\x1b[48;2;255;255;238m", "\x1b[0m\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output", want)
+		}
+	}
+
+	out, err = New("test").Background("transparent").Terminal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "\x1b[49m") {
+		t.Error("expected the terminal background for a transparent background")
+	}
+}
+
+func TestGenerateToFileText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "qr.txt")
+	if err := GenerateToFile("https://example.com", nil, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "█") {
+		t.Error("expected half block characters")
+	}
+}
+
+func TestGenerateToFileFormat(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "qr.txt")
+	if err := GenerateToFileFormat("https://example.com", nil, path, FormatASCII); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "##") || strings.Contains(string(data), "█") {
+		t.Error("expected ASCII art whatever the extension")
+	}
+
+	// A failed render keeps the existing file
+	logoPath := filepath.Join(dir, "logo.svg")
+	if err := os.WriteFile(logoPath, []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.Logo = &LogoConfig{Path: logoPath}
+	if err := GenerateToFileFormat("https://example.com", cfg, path, FormatPNG); err == nil {
+		t.Fatal("expected error for SVG logo in PNG output")
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(data) {
+		t.Error("expected the existing file kept")
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/ahmedtahas/qr-gode/internal/encoder"
 )
 
 // ValidationError represents an error during configuration validation.
@@ -162,6 +164,121 @@ func ValidateConfig(cfg *Config) []error {
 			Message: "must be positive",
 		})
 	}
+	if cfg.PhysicalSize < 0 {
+		errs = append(errs, &ValidationError{
+			Field:   "PhysicalSize",
+			Message: "cannot be negative",
+		})
+	}
+
+	// Validate spot color
+	if spot := cfg.Spot; spot != nil {
+		if spot.Name == "" {
+			errs = append(errs, &ValidationError{
+				Field:   "Spot.Name",
+				Message: "cannot be empty",
+			})
+		}
+		for _, c := range []struct {
+			field string
+			value float64
+		}{{"C", spot.C}, {"M", spot.M}, {"Y", spot.Y}, {"K", spot.K}} {
+			if c.value < 0 || c.value > 1 {
+				errs = append(errs, &ValidationError{
+					Field:   "Spot." + c.field,
+					Message: "must be between 0 and 1",
+				})
+			}
+		}
+	}
+
+	// Validate charset
+	if cfg.Charset != "" {
+		if _, ok := encoder.LookupCharset(cfg.Charset); !ok {
+			errs = append(errs, &ValidationError{
+				Field:   "Charset",
+				Message: fmt.Sprintf("unknown character set %q", cfg.Charset),
+			})
+		}
+	}
+
+	// Validate Micro QR limits
+	if cfg.Symbol == SymbolMicro {
+		if cfg.ErrorCorrection == LevelH {
+			errs = append(errs, &ValidationError{
+				Field:   "ErrorCorrection",
+				Message: "Micro QR does not support level H",
+			})
+		}
+		if cfg.Charset != "" {
+			errs = append(errs, &ValidationError{
+				Field:   "Charset",
+				Message: "Micro QR does not support ECI character sets",
+			})
+		}
+		if cfg.GS1 {
+			errs = append(errs, &ValidationError{
+				Field:   "GS1",
+				Message: "Micro QR does not support GS1 data",
+			})
+		}
+	}
+
+	// Validate rMQR limits
+	if cfg.Symbol == SymbolRMQR {
+		if cfg.ErrorCorrection == LevelL || cfg.ErrorCorrection == LevelQ {
+			errs = append(errs, &ValidationError{
+				Field:   "ErrorCorrection",
+				Message: "rMQR only supports levels M and H",
+			})
+		}
+		if cfg.Charset != "" {
+			errs = append(errs, &ValidationError{
+				Field:   "Charset",
+				Message: "rMQR does not support ECI character sets",
+			})
+		}
+	}
+	if cfg.MaxHeight != 0 && (cfg.MaxHeight < 7 || cfg.MaxHeight > 17) {
+		errs = append(errs, &ValidationError{
+			Field:   "MaxHeight",
+			Message: "must be 0 or between 7 and 17 modules",
+		})
+	}
+
+	// Validate version and mask
+	maxVersion := cfg.Symbol.maxVersion()
+	if cfg.Version < 0 || cfg.Version > maxVersion {
+		errs = append(errs, &ValidationError{
+			Field:   "Version",
+			Message: fmt.Sprintf("must be 0 or between 1 and %d", maxVersion),
+		})
+	}
+	if cfg.MinVersion < 0 || cfg.MinVersion > maxVersion {
+		errs = append(errs, &ValidationError{
+			Field:   "MinVersion",
+			Message: fmt.Sprintf("must be 0 or between 1 and %d", maxVersion),
+		})
+	} else if cfg.Version > 0 && cfg.MinVersion > cfg.Version {
+		errs = append(errs, &ValidationError{
+			Field:   "MinVersion",
+			Message: "cannot be above Version",
+		})
+	}
+	switch {
+	case cfg.Symbol == SymbolRMQR:
+		// A single mask, Mask is ignored
+	case cfg.Symbol == SymbolMicro && (cfg.Mask < -1 || cfg.Mask > 3):
+		errs = append(errs, &ValidationError{
+			Field:   "Mask",
+			Message: "must be -1 or between 0 and 3 for Micro QR",
+		})
+	case cfg.Mask < -1 || cfg.Mask > 7:
+		errs = append(errs, &ValidationError{
+			Field:   "Mask",
+			Message: "must be -1 or between 0 and 7",
+		})
+	}
 
 	// Validate quiet zone
 	if cfg.QuietZone < 0 {
@@ -171,6 +288,14 @@ func ValidateConfig(cfg *Config) []error {
 		})
 	}
 
+	// Validate module size
+	if cfg.Modules.Size < 0 || cfg.Modules.Size > 1 {
+		errs = append(errs, &ValidationError{
+			Field:   "Modules.Size",
+			Message: "must be between 0 and 1",
+		})
+	}
+
 	// Validate custom images if provided
 	if cfg.Images != nil {
 		if cfg.Images.Module != "" {
@@ -196,6 +321,26 @@ func ValidateConfig(cfg *Config) []error {
 			errs = append(errs, err)
 		}
 	}
+	if cfg.Logo != nil {
+		if cfg.Logo.Size < 0 || cfg.Logo.Size > 1 {
+			errs = append(errs, &ValidationError{
+				Field:   "Logo.Size",
+				Message: "must be between 0 and 1",
+			})
+		}
+		if cfg.Logo.Padding < 0 {
+			errs = append(errs, &ValidationError{
+				Field:   "Logo.Padding",
+				Message: "cannot be negative",
+			})
+		}
+		if cfg.Logo.Policy < LogoRaise || cfg.Logo.Policy > LogoIgnore {
+			errs = append(errs, &ValidationError{
+				Field:   "Logo.Policy",
+				Message: fmt.Sprintf("unknown policy %d", cfg.Logo.Policy),
+			})
+		}
+	}
 
 	return errs
 }
@@ -165,6 +165,59 @@ func TestValidateConfig_NegativeQuietZone(t *testing.T) {
 	}
 }
 
+func TestValidateConfig_UnknownCharset(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Charset = "ebcdic"
+
+	errs := ValidateConfig(cfg)
+	if len(errs) != 1 {
+		t.Errorf("expected 1 error for unknown charset, got %d", len(errs))
+	}
+}
+
+func TestValidateConfig_MicroLimits(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Symbol = SymbolMicro
+	cfg.ErrorCorrection = LevelH
+	cfg.Charset = CharsetUTF8
+	cfg.GS1 = true
+
+	errs := ValidateConfig(cfg)
+	if len(errs) != 3 {
+		t.Errorf("expected 3 errors for level H, charset and GS1, got %d", len(errs))
+	}
+}
+
+func TestValidateConfig_RMQRLimits(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Symbol = SymbolRMQR
+	cfg.ErrorCorrection = LevelQ
+	cfg.MaxHeight = 20
+
+	errs := ValidateConfig(cfg)
+	if len(errs) != 2 {
+		t.Errorf("expected 2 errors for level Q and max height, got %d", len(errs))
+	}
+}
+
+func TestValidateConfig_VersionAndMask(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Version = 41
+	cfg.Mask = 8
+	if errs := ValidateConfig(cfg); len(errs) != 2 {
+		t.Errorf("expected 2 errors for version 41 and mask 8, got %d", len(errs))
+	}
+
+	cfg = DefaultConfig()
+	cfg.Symbol = SymbolMicro
+	cfg.Version = 3
+	cfg.MinVersion = 4
+	cfg.Mask = 4
+	if errs := ValidateConfig(cfg); len(errs) != 2 {
+		t.Errorf("expected 2 errors for min version above version and mask 4, got %d", len(errs))
+	}
+}
+
 func TestValidateConfig_InvalidImages(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.Images = &CustomImages{
@@ -206,6 +259,15 @@ func TestBuilder_InvalidAlignmentImage(t *testing.T) {
 	}
 }
 
+func TestBuilder_UnencodableCharset(t *testing.T) {
+	qr := New("한국어").Charset(CharsetLatin1)
+
+	_, err := qr.SVG()
+	if err == nil {
+		t.Error("expected error for text outside the charset")
+	}
+}
+
 func TestBuilder_Validate(t *testing.T) {
 	qr := New("test").
 		ModuleImage("/nonexistent/module.png").
@@ -0,0 +1,153 @@
+package qrgode
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ahmedtahas/qr-gode/internal/colors"
+	"github.com/ahmedtahas/qr-gode/internal/raster"
+)
+
+// Helpers shared by the PDF and EPS output, which use the same path
+// operators, function and shading dictionaries.
+
+// deviceColor formats colors in a device color space of PDF and
+// PostScript.
+type deviceColor struct {
+	space      string                  // Color space name
+	components func(color.RGBA) string // Space separated components, 0-1
+}
+
+var (
+	rgbColor = deviceColor{"/DeviceRGB", func(c color.RGBA) string {
+		return fmt.Sprintf("%s %s %s", vectorNum(float64(c.R)/255), vectorNum(float64(c.G)/255), vectorNum(float64(c.B)/255))
+	}}
+	cmykColor = deviceColor{"/DeviceCMYK", func(c color.RGBA) string {
+		cc, m, y, k := color.RGBToCMYK(c.R, c.G, c.B)
+		return fmt.Sprintf("%s %s %s %s", vectorNum(float64(cc)/255), vectorNum(float64(m)/255),
+			vectorNum(float64(y)/255), vectorNum(float64(k)/255))
+	}}
+)
+
+// vectorColor returns c as a solid color or gradient. Per-module colors
+// are already split into solid layers; elsewhere their SVG fill is used.
+// Other colors are reduced to their center color.
+func vectorColor(c colors.Color) colors.Color {
+	switch c.(type) {
+	case *colors.Solid, *colors.LinearGradient, *colors.RadialGradient:
+		return c
+	}
+	if colors.IsPerModule(c) {
+		return colors.NewSolid(c.SVGFill(""))
+	}
+	return colors.NewSolid(c.ColorAt(0.5, 0.5))
+}
+
+// writePathOps writes the m, l, c and h operators that construct an
+// outline.
+func writePathOps(w io.Writer, o raster.Outline) {
+	for _, seg := range o {
+		for _, pt := range seg.Points {
+			fmt.Fprintf(w, "%s %s ", vectorNum(pt.X), vectorNum(pt.Y))
+		}
+		switch seg.Op {
+		case 'M':
+			io.WriteString(w, "m\n")
+		case 'L':
+			io.WriteString(w, "l\n")
+		case 'C':
+			io.WriteString(w, "c\n")
+		case 'Z':
+			io.WriteString(w, "h\n")
+		}
+	}
+}
+
+// gradientBox returns the matrix operands that map the unit square of a
+// shading onto the bounding box of path data. Gradients span the tight
+// bounds of each shape, like the objectBoundingBox units of SVG and the
+// raster output.
+func gradientBox(d string) (string, error) {
+	flat, err := raster.ParseSVG(d)
+	if err != nil {
+		return "", fmt.Errorf("invalid shape path: %w", err)
+	}
+	minX, minY, maxX, maxY := flat.Bounds()
+	if flat.Empty() {
+		minX, minY, maxX, maxY = 0, 0, 0, 0
+	}
+	return fmt.Sprintf("%s 0 0 %s %s %s", vectorNum(math.Max(maxX-minX, 1e-3)), vectorNum(math.Max(maxY-minY, 1e-3)),
+		vectorNum(minX), vectorNum(minY)), nil
+}
+
+// shadingDict returns the axial or radial shading of a gradient in
+// coordinates of the unit square. The opacity of the stops is ignored.
+func shadingDict(c colors.Color, dc deviceColor) (string, error) {
+	switch g := c.(type) {
+	case *colors.LinearGradient:
+		fn, err := stopsFunction(g.Stops, dc)
+		if err != nil {
+			return "", err
+		}
+		// The gradient vector of LinearGradient.SVGDefs
+		rad := g.Angle * math.Pi / 180
+		dx, dy := 0.5*math.Cos(rad), 0.5*math.Sin(rad)
+		return fmt.Sprintf("<< /ShadingType 2 /ColorSpace %s /Coords [%s %s %s %s] /Function %s /Extend [true true] >>",
+			dc.space, vectorNum(0.5-dx), vectorNum(0.5-dy), vectorNum(0.5+dx), vectorNum(0.5+dy), fn), nil
+	case *colors.RadialGradient:
+		fn, err := stopsFunction(g.Stops, dc)
+		if err != nil {
+			return "", err
+		}
+		// r="70%" as in RadialGradient.SVGDefs
+		cx, cy := vectorNum(g.CenterX), vectorNum(g.CenterY)
+		return fmt.Sprintf("<< /ShadingType 3 /ColorSpace %s /Coords [%s %s 0 %s %s 0.7] /Function %s /Extend [true true] >>",
+			dc.space, cx, cy, cx, cy, fn), nil
+	}
+	return "", fmt.Errorf("unsupported gradient type %s", c.Type())
+}
+
+// stopsFunction returns a function of t (0.0-1.0) blending linearly
+// between evenly spaced color stops: a stitching function of one
+// exponential function per pair of neighbours.
+func stopsFunction(stops []string, dc deviceColor) (string, error) {
+	if len(stops) == 0 {
+		stops = []string{"#000000"}
+	}
+	values := make([]string, len(stops))
+	for i, stop := range stops {
+		rgba, err := colors.ToRGBA(stop)
+		if err != nil {
+			return "", err
+		}
+		values[i] = "[" + dc.components(rgba) + "]"
+	}
+	if len(values) == 1 {
+		return fmt.Sprintf("<< /FunctionType 2 /Domain [0 1] /C0 %s /C1 %s /N 1 >>", values[0], values[0]), nil
+	}
+
+	var fns, bounds, encode strings.Builder
+	for i := range len(values) - 1 {
+		fmt.Fprintf(&fns, "<< /FunctionType 2 /Domain [0 1] /C0 %s /C1 %s /N 1 >> ", values[i], values[i+1])
+		if i > 0 {
+			fmt.Fprintf(&bounds, "%s ", vectorNum(float64(i)/float64(len(values)-1)))
+		}
+		encode.WriteString("0 1 ")
+	}
+	return fmt.Sprintf("<< /FunctionType 3 /Domain [0 1] /Functions [%s] /Bounds [%s] /Encode [%s] >>",
+		strings.TrimSpace(fns.String()), strings.TrimSpace(bounds.String()), strings.TrimSpace(encode.String())), nil
+}
+
+// vectorNum formats v with at most three decimals, the precision of
+// PDF and PostScript coordinates in practice.
+func vectorNum(v float64) string {
+	v = math.Round(v*1000) / 1000
+	if v == 0 {
+		return "0" // Not -0
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}